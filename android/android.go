@@ -0,0 +1,358 @@
+// Package android converts catalogs to and from the strings.xml resources of
+// Android applications, for teams sharing translations between their mobile
+// and backend code.
+//
+// Each string resource becomes a message whose msgctxt is the name of the
+// resource, and whose msgid is its text in the resources of the source
+// language. Plurals resources become plural messages, whose msgid and
+// msgid_plural are their "one" and "other" items, and whose msgstrs are the
+// items of the translations, indexed by the plural forms of their quantities
+// in the language of the translations, as given by its CLDR plural rule.
+//
+// The strings hold the inner XML of the resources, keeping their markup,
+// e.g. <b> or <xliff:g> placeholders, and entities other than the predefined
+// ones, with the Android escape sequences resolved, e.g. "Don't" for "Don\'t"
+// and "é" for "\u00e9", and the predefined entities, e.g. "&" for "&amp;".
+// Resources not translatable, string arrays and comments are not converted.
+package android
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/olebedev/gettext/po"
+)
+
+type resources struct {
+	XMLName   xml.Name   `xml:"resources"`
+	Resources []resource `xml:",any"`
+}
+
+type resource struct {
+	XMLName      xml.Name
+	Name         string `xml:"name,attr"`
+	Translatable string `xml:"translatable,attr,omitempty"`
+	Text         string `xml:",innerxml"`
+	Items        []item `xml:"item"`
+}
+
+type item struct {
+	Quantity string `xml:"quantity,attr"`
+	Text     string `xml:",innerxml"`
+}
+
+// Options controls how resources are written.
+type Options struct {
+	// Source writes the msgids, as the resources of the source language,
+	// instead of the translations.
+	Source bool
+}
+
+// categories lists the plural categories, in the order Android writes them.
+var categories = []po.PluralCategory{po.Zero, po.One, po.Two, po.Few, po.Many, po.Other}
+
+// Parse reads the resources of the source language, and their translations
+// to the language lang, if translations is not nil, returning a catalog of
+// the language.
+func Parse(source, translations io.Reader, lang string) (*po.File, error) {
+	var src, err = decode(source)
+	if err != nil {
+		return nil, err
+	}
+	var trans = make(map[string]resource)
+	if translations != nil {
+		var res, err = decode(translations)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res {
+			trans[r.Name] = r
+		}
+	}
+	var forms = pluralForms(lang)
+	var f = po.NewFile(lang)
+	for _, r := range src {
+		var msg = &po.Message{Ctxt: r.Name}
+		var t, translated = trans[r.Name]
+		switch r.XMLName.Local {
+		case "string":
+			msg.Id = unescape(r.Text)
+			msg.Str = []string{""}
+			if translated {
+				msg.Str[0] = unescape(t.Text)
+			}
+		case "plurals":
+			msg.Id, msg.IdPlural = unescape(quantity(r, po.One)), unescape(quantity(r, po.Other))
+			msg.Str = make([]string, nforms(forms))
+			for _, it := range items(t) {
+				if i, found := forms[po.PluralCategory(it.Quantity)]; found && msg.Str[i] == "" {
+					msg.Str[i] = unescape(it.Text)
+				}
+			}
+		}
+		f.Messages = append(f.Messages, msg)
+	}
+	return f, nil
+}
+
+// decode returns the translatable string and plurals resources read from r.
+func decode(r io.Reader) ([]resource, error) {
+	var doc resources
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("android: %v", err)
+	}
+	var res []resource
+	for _, r := range doc.Resources {
+		if (r.XMLName.Local == "string" || r.XMLName.Local == "plurals") && r.Translatable != "false" {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+// items returns the items of the resource, in the order of their categories,
+// so that the forms of integers take precedence over those of fractions
+// sharing their plural form, e.g. "few" over "many" in Czech.
+func items(r resource) []item {
+	var items []item
+	for _, c := range categories {
+		for _, it := range r.Items {
+			if po.PluralCategory(it.Quantity) == c {
+				items = append(items, it)
+			}
+		}
+	}
+	return items
+}
+
+// quantity returns the text of the item of the given quantity, or of the
+// first item if none.
+func quantity(r resource, q po.PluralCategory) string {
+	for _, it := range r.Items {
+		if po.PluralCategory(it.Quantity) == q {
+			return it.Text
+		}
+	}
+	if len(r.Items) > 0 {
+		return r.Items[0].Text
+	}
+	return ""
+}
+
+// pluralForms returns the plural forms of the categories of the language,
+// as its plural rule selects them for a sample of quantities: integers
+// first, then fractions, the categories of some languages only applying to
+// fractions. Languages without a rule have the forms of English.
+func pluralForms(lang string) map[po.PluralCategory]int {
+	var rule = po.PluralRuleForLanguage(lang)
+	if rule == nil {
+		return map[po.PluralCategory]int{po.One: 0, po.Other: 1}
+	}
+	var forms = make(map[po.PluralCategory]int)
+	var add = func(n float64) {
+		if _, found := forms[rule.Category(n)]; !found {
+			forms[rule.Category(n)] = rule.Index(n)
+		}
+	}
+	for n := 0; n <= 200; n++ {
+		add(float64(n))
+	}
+	add(1000000)
+	for n := 0; n <= 100; n++ {
+		add(float64(n) + 0.5)
+	}
+	return forms
+}
+
+// nforms returns the number of plural forms.
+func nforms(forms map[po.PluralCategory]int) int {
+	var n = 0
+	for _, i := range forms {
+		if i >= n {
+			n = i + 1
+		}
+	}
+	return n
+}
+
+// Write writes the messages of the file as Android resources, named by their
+// msgctxt: their translations, to the Language of the file, or their msgids
+// with opts.Source. Untranslated, fuzzy and obsolete messages are left out of
+// the translations.
+func Write(w io.Writer, f *po.File, opts Options) error {
+	var forms = pluralForms(f.Language())
+	var doc resources
+	for _, msg := range f.Messages {
+		if msg.Obsolete || msg.Id == "" && msg.Ctxt == "" {
+			continue
+		}
+		if msg.Ctxt == "" {
+			return fmt.Errorf("android: message %q has no msgctxt naming its resource", msg.Id)
+		}
+		if !opts.Source && (msg.HasFlag("fuzzy") || !translated(msg)) {
+			continue
+		}
+		var r = resource{Name: msg.Ctxt}
+		switch {
+		case msg.IdPlural == "":
+			r.XMLName.Local = "string"
+			r.Text = msg.Id
+			if !opts.Source {
+				r.Text = msg.Str[0]
+			}
+			r.Text = escape(r.Text)
+		case opts.Source:
+			r.XMLName.Local = "plurals"
+			r.Items = []item{{string(po.One), escape(msg.Id)}, {string(po.Other), escape(msg.IdPlural)}}
+		default:
+			r.XMLName.Local = "plurals"
+			for _, c := range categories {
+				if i, found := forms[c]; found && i < len(msg.Str) && msg.Str[i] != "" {
+					r.Items = append(r.Items, item{string(c), escape(msg.Str[i])})
+				}
+			}
+		}
+		doc.Resources = append(doc.Resources, r)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	var enc = xml.NewEncoder(w)
+	enc.Indent("", "    ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// translated returns true if the message has a translation.
+func translated(msg *po.Message) bool {
+	for _, str := range msg.Str {
+		if str != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapes maps the characters of the Android escape sequences to the
+// characters they stand for.
+var unescapes = map[byte]byte{'n': '\n', 't': '\t', '\'': '\'', '"': '"', '\\': '\\', '@': '@', '?': '?'}
+
+// unescape resolves the Android escape sequences and predefined entities of
+// the inner XML of a resource, outside its tags, and its quotes, collapsing the
+// XML whitespace outside quotes, and trimming it at the ends, as Android does.
+func unescape(s string) string {
+	var b strings.Builder
+	var quoted = false
+	var space = false // whitespace pending outside quotes
+	for i := 0; i < len(s); i++ {
+		var c = s[i]
+		switch {
+		case c == '"' || strings.HasPrefix(s[i:], "&quot;"):
+			quoted = !quoted
+			if c == '&' {
+				i += len("&quot;") - 1
+			}
+			continue
+		case !quoted && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		if t := tag.FindString(s[i:]); c == '<' && t != "" {
+			b.WriteString(t)
+			i += len(t) - 1
+			continue
+		}
+		if c == '&' {
+			if name, u := xmlEntity(s[i:]); name != "" {
+				b.WriteByte(u)
+				i += len(name) - 1
+				continue
+			}
+		}
+		if c == '\\' && i+5 < len(s) && s[i+1] == 'u' {
+			if u, err := strconv.ParseUint(s[i+2:i+6], 16, 16); err == nil {
+				b.WriteRune(rune(u))
+				i += 5
+				continue
+			}
+		}
+		if c == '\\' && i+1 < len(s) {
+			if u, found := unescapes[s[i+1]]; found {
+				b.WriteByte(u)
+				i++
+				continue
+			}
+		}
+		var _, size = utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		i += size - 1
+	}
+	return b.String()
+}
+
+// xmlEntities maps the predefined XML entities, but &quot;, to the
+// characters they stand for.
+var xmlEntities = map[string]byte{"&amp;": '&', "&lt;": '<', "&gt;": '>', "&apos;": '\''}
+
+// xmlEntity returns the predefined entity s starts with, and its character,
+// or "" if none, or if it is a &lt; starting what reads as a tag, left as is
+// so that it is not written as one.
+func xmlEntity(s string) (string, byte) {
+	for name, c := range xmlEntities {
+		if strings.HasPrefix(s, name) && !(c == '<' && tag.MatchString("<"+s[len(name):])) {
+			return name, c
+		}
+	}
+	return "", 0
+}
+
+// tag matches an XML start or end tag, or a comment.
+var tag = regexp.MustCompile(`^<(/?[A-Za-z][^<>]*|!--(?s:.*?)--)>`)
+
+// entity matches an XML entity or character reference.
+var entity = regexp.MustCompile(`^&(#[0-9]+|#x[0-9a-fA-F]+|[A-Za-z][A-Za-z0-9]*);`)
+
+// escape returns s as the inner XML of a resource, escaping the characters
+// Android interprets outside its tags, and ampersands and angle brackets not
+// starting an entity or a tag, and quoting it if it has whitespace that would be collapsed.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		var c = s[i]
+		switch {
+		case c == '<' && tag.MatchString(s[i:]):
+			var t = tag.FindString(s[i:])
+			b.WriteString(t)
+			i += len(t) - 1
+		case c == '<':
+			b.WriteString("&lt;")
+		case c == '&' && !entity.MatchString(s[i:]):
+			b.WriteString("&amp;")
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c == '\'' || c == '"' || c == '\\' || (c == '@' || c == '?') && i == 0:
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if strings.TrimSpace(s) != s || strings.Contains(s, "  ") {
+		return `"` + b.String() + `"`
+	}
+	return b.String()
+}
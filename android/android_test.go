@@ -0,0 +1,152 @@
+package android
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/olebedev/gettext/po"
+)
+
+var source = `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="app_name" translatable="false">Notes</string>
+    <!-- Greeting on the start screen. -->
+    <string name="hello">Hello, <xliff:g id="name">%s</xliff:g>!</string>
+    <string name="dont">Don\'t
+        save</string>
+    <string name="spaces">"  two  spaces"</string>
+    <string name="tom">Tom &amp; Jerry\né</string>
+    <plurals name="files">
+        <item quantity="one">%d file</item>
+        <item quantity="other">%d files</item>
+    </plurals>
+    <string-array name="planets">
+        <item>Mercury</item>
+    </string-array>
+</resources>
+`
+
+var translations = `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="hello">Ahoj, <xliff:g id="name">%s</xliff:g>!</string>
+    <string name="dont">Neukladať</string>
+    <plurals name="files">
+        <item quantity="one">%d súbor</item>
+        <item quantity="few">%d súbory</item>
+        <item quantity="many">%d súboru</item>
+        <item quantity="other">%d súborov</item>
+    </plurals>
+</resources>
+`
+
+func TestParse(t *testing.T) {
+	var f, err = Parse(strings.NewReader(source), strings.NewReader(translations), "sk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []po.Message{
+		{Ctxt: "hello", Id: `Hello, <xliff:g id="name">%s</xliff:g>!`, Str: []string{`Ahoj, <xliff:g id="name">%s</xliff:g>!`}},
+		{Ctxt: "dont", Id: "Don't save", Str: []string{"Neukladať"}},
+		{Ctxt: "spaces", Id: "  two  spaces", Str: []string{""}},
+		{Ctxt: "tom", Id: "Tom & Jerry\né", Str: []string{""}},
+		{Ctxt: "files", Id: "%d file", IdPlural: "%d files", Str: []string{"%d súbor", "%d súbory", "%d súborov"}},
+	}
+	if len(f.Messages) != len(expected) {
+		t.Fatalf("unexpected messages %v", f.Messages)
+	}
+	for i, msg := range f.Messages {
+		if msg.Ctxt != expected[i].Ctxt || msg.Id != expected[i].Id || msg.IdPlural != expected[i].IdPlural ||
+			!reflect.DeepEqual(msg.Str, expected[i].Str) {
+			t.Errorf("expected %v, got %v", expected[i], *msg)
+		}
+	}
+	if actual := f.NPGetText("files", "%d file", "%d files", 3); actual != "%d súbory" {
+		t.Errorf("unexpected plural %q", actual)
+	}
+	if _, err := Parse(strings.NewReader("<resources>"), nil, "sk"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	var f, err = Parse(strings.NewReader(source), strings.NewReader(translations), "sk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		opts     Options
+		expected []string
+	}{
+		{Options{Source: true}, []string{
+			`<string name="dont">Don\'t save</string>`,
+			`<string name="spaces">"  two  spaces"</string>`,
+			`<string name="tom">Tom &amp; Jerry\né</string>`,
+			`<item quantity="one">%d file</item>`,
+			`<item quantity="other">%d files</item>`,
+		}},
+		{Options{}, []string{
+			`<string name="hello">Ahoj, <xliff:g id="name">%s</xliff:g>!</string>`,
+			`<item quantity="one">%d súbor</item>`,
+			`<item quantity="few">%d súbory</item>`,
+			`<item quantity="many">%d súbory</item>`,
+			`<item quantity="other">%d súborov</item>`,
+		}},
+	} {
+		var buf bytes.Buffer
+		if err := Write(&buf, f, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		for _, expected := range test.expected {
+			if !strings.Contains(buf.String(), expected) {
+				t.Errorf("expected %s in:\n%s", expected, buf.String())
+			}
+		}
+		if !test.opts.Source && strings.Contains(buf.String(), "spaces") {
+			t.Errorf("unexpected untranslated string in:\n%s", buf.String())
+		}
+		decoded, err := Parse(bytes.NewReader(buf.Bytes()), nil, "sk")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.opts.Source && len(decoded.Messages) != len(f.Messages) {
+			t.Errorf("unexpected messages %v", decoded.Messages)
+		}
+		for i, msg := range decoded.Messages {
+			if test.opts.Source && (msg.Id != f.Messages[i].Id || msg.IdPlural != f.Messages[i].IdPlural) {
+				t.Errorf("expected %q, got %q", f.Messages[i].Id, msg.Id)
+			}
+		}
+	}
+	f.Messages = append(f.Messages, &po.Message{Id: "Quit", Str: []string{"Koniec"}})
+	if err := Write(&bytes.Buffer{}, f, Options{}); err == nil {
+		t.Error("expected an error for a message without msgctxt")
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var f = po.NewFile("en")
+	for _, id := range []string{"Voilà déjà …", "1 < 2 & 3 fr", "a <b>b</b> &#233; <!-- c > d -->", "a &lt;b> <", `"@x"`} {
+		f.Messages = append(f.Messages, &po.Message{Ctxt: "s", Id: id, Str: []string{id}})
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, f, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<string name="s">1 &lt; 2 &amp; 3 fr</string>`) {
+		t.Errorf("unexpected escaping in:\n%s", buf.String())
+	}
+	var decoded, err = Parse(&buf, nil, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Messages) != len(f.Messages) {
+		t.Fatalf("unexpected messages %v", decoded.Messages)
+	}
+	for i, msg := range decoded.Messages {
+		if msg.Id != f.Messages[i].Id {
+			t.Errorf("expected %q, got %q", f.Messages[i].Id, msg.Id)
+		}
+	}
+}
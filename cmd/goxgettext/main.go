@@ -0,0 +1,96 @@
+// Command goxgettext extracts the translatable messages of Go packages into a
+// PO template.
+//
+// Usage:
+//
+//	goxgettext [-k keyword]... [-c tag] [-t exts] [-o file] path...
+//
+// The paths are Go source files, or directories searched recursively for
+// them. With -t, the files with the given comma separated extensions, e.g.
+// ".html,.tmpl", are extracted as templates, from the calls of the functions
+// of package tmpl. Keywords are given in the syntax of xgettext, e.g. "T" or "NT:1,2",
+// and default to the lookup methods of po.File. With -c, only the comments
+// starting with tag are extracted.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olebedev/gettext/extract"
+)
+
+// keywords collects the repeated -k flags.
+type keywords []extract.Keyword
+
+func (k *keywords) String() string {
+	return fmt.Sprint(*k)
+}
+
+func (k *keywords) Set(s string) error {
+	var kw, err = extract.ParseKeyword(s)
+	if err != nil {
+		return err
+	}
+	*k = append(*k, kw)
+	return nil
+}
+
+func main() {
+	var kws keywords
+	flag.Var(&kws, "k", "extract the calls of `keyword`, e.g. T or NT:1,2")
+	var tag = flag.String("c", "", "only extract the comments starting with `tag`")
+	var exts = flag.String("t", "", "extract the files with the comma separated `extensions` as templates")
+	var out = flag.String("o", "", "write to `file` instead of the standard output")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: goxgettext [-k keyword]... [-c tag] [-t exts] [-o file] path...")
+		os.Exit(2)
+	}
+	var x = extract.NewExtractor(kws...)
+	x.CommentTag = *tag
+	var templates []string
+	if *exts != "" {
+		templates = strings.Split(*exts, ",")
+	}
+	if err := run(x, flag.Args(), templates, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "goxgettext:", err)
+		os.Exit(1)
+	}
+}
+
+func run(x *extract.Extractor, paths, templates []string, out string) error {
+	for _, path := range paths {
+		var err = filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			if strings.HasSuffix(name, ".go") {
+				return x.ParseFile(name, nil)
+			}
+			for _, ext := range templates {
+				if ext != "" && strings.HasSuffix(name, ext) {
+					return x.ParseTemplate(name, nil)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	var buf bytes.Buffer
+	if _, err := x.Template().WriteTo(&buf); err != nil {
+		return err
+	}
+	if out == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0666)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// defaultPluralForms are the plural forms of the catalogs of unknown
+// languages, as in C.
+const defaultPluralForms = "nplurals=2; plural=(n != 1);"
+
+// catalog is the compiled catalog of a locale.
+type catalog struct {
+	Locale   string
+	Var      string   // name of the variable of the catalog
+	Messages []string // Go source of the lite.Message of each message
+	Plural   string   // name of the plural function
+}
+
+// plural is a generated plural function.
+type plural struct {
+	Name   string
+	Forms  string // Plural-Forms compiled
+	Source string // Go source of the function literal
+}
+
+// compile compiles the translated messages of f, with its plural function
+// among those generated so far, adding it to them if new.
+func compile(locale string, f *po.File, plurals *[]plural) (catalog, error) {
+	var c = catalog{Locale: locale}
+	var pluralForms = f.PluralForms()
+	if pluralForms == "" {
+		pluralForms = po.NewFile(f.Language()).PluralForms()
+	}
+	if pluralForms == "" {
+		pluralForms = defaultPluralForms
+	}
+	for _, p := range *plurals {
+		if p.Forms == pluralForms {
+			c.Plural = p.Name
+		}
+	}
+	if c.Plural == "" {
+		var src, err = po.PluralFormsSource(pluralForms)
+		if err != nil {
+			return c, err
+		}
+		c.Plural = "plural" + strconv.Itoa(len(*plurals))
+		*plurals = append(*plurals, plural{c.Plural, pluralForms, src})
+	}
+
+	var seen = make(map[[2]string]bool)
+	for _, m := range f.Messages {
+		if m.Obsolete || m.Id == "" || m.HasFlag("fuzzy") || !translated(m) {
+			continue
+		}
+		if seen[[2]string{m.Ctxt, m.Id}] {
+			return c, fmt.Errorf("duplicate message %q in context %q", m.Id, m.Ctxt)
+		}
+		seen[[2]string{m.Ctxt, m.Id}] = true
+		c.Messages = append(c.Messages, message(m))
+	}
+	return c, nil
+}
+
+// message returns the Go source of the lite.Message of m.
+func message(m *po.Message) string {
+	var b strings.Builder
+	b.WriteString("{")
+	if m.Ctxt != "" {
+		b.WriteString("Ctxt: " + strconv.Quote(m.Ctxt) + ", ")
+	}
+	b.WriteString("Id: " + strconv.Quote(m.Id) + ", Str: []string{")
+	for i, str := range m.Str {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(str))
+	}
+	b.WriteString("}}")
+	return b.String()
+}
+
+// translated returns true if the message has a translation.
+func translated(m *po.Message) bool {
+	for _, str := range m.Str {
+		if str != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// generate writes the package of the compiled catalogs, by locale.
+func generate(w io.Writer, pkg string, files map[string]*po.File) error {
+	var locales []string
+	for locale := range files {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	var catalogs []catalog
+	var plurals []plural
+	for i, locale := range locales {
+		var c, err = compile(locale, files[locale], &plurals)
+		if err != nil {
+			return fmt.Errorf("%s: %v", locale, err)
+		}
+		c.Var = "catalog" + strconv.Itoa(i)
+		catalogs = append(catalogs, c)
+	}
+	var buf bytes.Buffer
+	if err := pkgTemplate.Execute(&buf, struct {
+		Package  string
+		Catalogs []catalog
+		Plurals  []plural
+	}{pkg, catalogs, plurals}); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+var pkgTemplate = template.Must(template.New("pkg").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(`// Code generated by po2go. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/olebedev/gettext/lite"
+
+// Lookup returns the catalog of the locale, or nil, which returns the source
+// strings.
+func Lookup(locale string) *lite.Catalog {
+	switch locale {
+{{- range .Catalogs}}
+	case {{quote .Locale}}:
+		return {{.Var}}
+{{- end}}
+	}
+	return nil
+}
+
+// Locales returns the locales of the catalogs, sorted.
+func Locales() []string {
+	return []string{ {{- range $i, $c := .Catalogs}}{{if $i}}, {{end}}{{quote $c.Locale}}{{end -}} }
+}
+{{range .Catalogs}}
+// {{.Var}} is the catalog of {{quote .Locale}}.
+var {{.Var}} = lite.New({{.Plural}}, []lite.Message{
+{{- range .Messages}}
+	{{.}},
+{{- end}}
+})
+{{end}}
+{{- range .Plurals}}
+// {{.Name}} selects the plural form of n, for {{quote .Forms}}.
+var {{.Name}} = {{.Source}}
+{{end}}`))
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/olebedev/gettext/po"
+)
+
+func TestGenerate(t *testing.T) {
+	f, err := po.Parse(strings.NewReader(`msgid ""
+msgstr ""
+"Language: sk\n"
+
+msgid "Open"
+msgstr "Otvoriť"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Otvor"
+
+msgid "Close"
+msgstr ""
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := generate(&buf, "translations", map[string]*po.File{"sk": f}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"package translations\n",
+		"import \"github.com/olebedev/gettext/lite\"\n",
+		"\tcase \"sk\":\n\t\treturn catalog0\n",
+		"var catalog0 = lite.New(plural0, []lite.Message{\n\t{Id: \"Open\", Str: []string{\"Otvoriť\"}},\n\t{Ctxt: \"menu\", Id: \"Open\", Str: []string{\"Otvor\"}},\n})\n",
+		"for \"nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\".\nvar plural0 = func(n int) int {\n",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "Close") {
+		t.Errorf("unexpected untranslated message in:\n%s", buf.String())
+	}
+	var fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "translations.go", buf.Bytes(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf = types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("translations", fset, []*ast.File{file}, nil); err != nil {
+		t.Errorf("%v in:\n%s", err, buf.String())
+	}
+}
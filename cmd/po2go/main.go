@@ -0,0 +1,72 @@
+// Command po2go compiles catalogs into a Go package, so that programs embed
+// their translations without parsing them at run time, e.g. CLIs that must
+// start fast.
+//
+// Usage:
+//
+//	po2go [-pkg name] [-o file] [locale=]file...
+//
+// The files are PO or MO files, of the locale given, or else of their
+// Language header, or named after it, e.g. "sk.po". The generated package
+// has a lite.Catalog per locale, returned by Lookup(locale), built from
+// tables of its messages. The plural forms are compiled into Go functions by
+// po.PluralFormsSource. Fuzzy and untranslated messages are left out.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olebedev/gettext/po"
+)
+
+func main() {
+	var pkg = flag.String("pkg", "translations", "name of the generated `package`")
+	var out = flag.String("o", "", "write to `file` instead of the standard output")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: po2go [-pkg name] [-o file] [locale=]file...")
+		os.Exit(2)
+	}
+	if err := run(flag.Args(), *pkg, *out); err != nil {
+		fmt.Fprintln(os.Stderr, "po2go:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, pkg, out string) error {
+	var files = make(map[string]*po.File)
+	for _, arg := range args {
+		var locale, name, found = strings.Cut(arg, "=")
+		if !found {
+			locale, name = "", arg
+		}
+		f, err := po.LoadFile(name)
+		if err != nil {
+			return err
+		}
+		if locale == "" {
+			locale = f.Language()
+		}
+		if locale == "" {
+			locale = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		}
+		if files[locale] != nil {
+			return fmt.Errorf("%s: several catalogs of %s", name, locale)
+		}
+		files[locale] = f
+	}
+	var buf bytes.Buffer
+	if err := generate(&buf, pkg, files); err != nil {
+		return err
+	}
+	if out == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0666)
+}
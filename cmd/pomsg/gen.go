@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// Extracted comments naming the generated function and its parameters.
+const (
+	namePrefix   = "name:"
+	paramsPrefix = "params:"
+)
+
+// function describes the generated function of a message.
+type function struct {
+	Name     string
+	Ctxt     string
+	Id       string
+	IdPlural string
+	Params   []param
+	Braces   bool // placeholders are replaced by name rather than formatted
+}
+
+type param struct {
+	Name, Type string
+}
+
+// verbRe matches printf verbs, with their explicit argument index if any.
+var verbRe = regexp.MustCompile(`%(?:\[(\d+)\])?[-+# 0]*(?:\d+|\*)?(?:\.(?:\d+|\*)?)?(?:\[(\d+)\])?([a-zA-Z%])`)
+
+// braceRe matches named placeholders, e.g. "{amount}".
+var braceRe = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// functions returns the functions of the messages of the template.
+func functions(f *po.File) ([]function, error) {
+	var r []function
+	var names = make(map[string]string)
+	for _, msg := range f.Messages {
+		if msg.Obsolete {
+			continue
+		}
+		var fn = function{Name: comment(msg, namePrefix), Ctxt: msg.Ctxt, Id: msg.Id, IdPlural: msg.IdPlural}
+		if fn.Name == "" {
+			fn.Name = identifier(msg.Ctxt + " " + po.StripPlaceholders(msg.Id))
+		}
+		if !token.IsIdentifier(fn.Name) || !token.IsExported(fn.Name) {
+			return nil, fmt.Errorf("message %q: invalid function name %q", msg.Id, fn.Name)
+		}
+		if other, dup := names[fn.Name]; dup {
+			return nil, fmt.Errorf("messages %q and %q: both named %s, add a %q comment", other, msg.Id, fn.Name, namePrefix)
+		}
+		names[fn.Name] = msg.Id
+		var err error
+		if fn.Params, fn.Braces, err = params(msg); err != nil {
+			return nil, fmt.Errorf("message %q: %v", msg.Id, err)
+		}
+		r = append(r, fn)
+	}
+	return r, nil
+}
+
+// params returns the parameters corresponding to the placeholders of the
+// message, and whether they are brace placeholders.
+func params(msg *po.Message) ([]param, bool, error) {
+	var r []param
+	var next = 0
+	for _, m := range verbRe.FindAllStringSubmatch(msg.Id, -1) {
+		var index, verb = m[1] + m[2], m[3]
+		if verb == "%" {
+			continue
+		}
+		var i = next
+		if index != "" {
+			i, _ = strconv.Atoi(index)
+			i--
+		}
+		next = i + 1
+		for len(r) <= i {
+			r = append(r, param{"arg" + strconv.Itoa(len(r)+1), "interface{}"})
+		}
+		r[i].Type = verbType(verb)
+	}
+	var braces = false
+	if len(r) == 0 {
+		for _, m := range braceRe.FindAllStringSubmatch(msg.Id, -1) {
+			if !containsParam(r, m[1]) {
+				r = append(r, param{m[1], "string"})
+				braces = true
+			}
+		}
+	}
+	if names := comment(msg, paramsPrefix); names != "" {
+		if braces {
+			return nil, false, fmt.Errorf("%q comments do not apply to brace placeholders", paramsPrefix)
+		}
+		var fields = strings.FieldsFunc(names, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+		if len(fields) != len(r) {
+			return nil, false, fmt.Errorf("%d parameters named, the message has %d", len(fields), len(r))
+		}
+		for i, name := range fields {
+			r[i].Name = name
+		}
+	}
+	for _, p := range r {
+		if !token.IsIdentifier(p.Name) || reserved[p.Name] {
+			return nil, false, fmt.Errorf("invalid parameter name %q", p.Name)
+		}
+	}
+	return r, braces, nil
+}
+
+// reserved lists the names the generated functions use, which parameters
+// would shadow.
+var reserved = map[string]bool{"locale": true, "n": true, "text": true, "fmt": true, "strings": true, "po": true, "Bundle": true}
+
+// verbType returns the type of the arguments of the given printf verb.
+func verbType(verb string) string {
+	switch verb {
+	case "d", "b", "o", "O", "c", "U":
+		return "int"
+	case "s", "q":
+		return "string"
+	case "e", "E", "f", "F", "g", "G":
+		return "float64"
+	case "t":
+		return "bool"
+	}
+	return "interface{}"
+}
+
+func containsParam(params []param, name string) bool {
+	for _, p := range params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// comment returns the value of the extracted comment of the message with the
+// given prefix.
+func comment(msg *po.Message, prefix string) string {
+	for _, line := range msg.ExtractedComments {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(line[len(prefix):])
+		}
+	}
+	return ""
+}
+
+// identifier returns the words of s as an exported Go identifier, e.g.
+// "CheckoutTotal" for "checkout total:".
+func identifier(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		var runes = []rune(word)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	var r = b.String()
+	if r != "" && !unicode.IsLetter([]rune(r)[0]) {
+		r = "Msg" + r
+	}
+	return r
+}
+
+// generate writes the package of the functions of the messages of f, or of
+// their keys.
+func generate(w io.Writer, pkg string, f *po.File, keys bool) error {
+	fns, err := functions(f)
+	if err != nil {
+		return err
+	}
+	var braces = false
+	for _, fn := range fns {
+		braces = braces || fn.Braces
+	}
+	var tmpl = pkgTemplate
+	if keys {
+		tmpl = keysTemplate
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, struct {
+		Package   string
+		Functions []function
+		Braces    bool
+	}{pkg, fns, braces}); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+var pkgTemplate = template.Must(template.New("pkg").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(`// Code generated by pomsg. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+{{- if .Braces}}
+	"strings"
+{{- end}}
+
+	"github.com/olebedev/gettext/po"
+)
+
+// Bundle holds the catalogs the messages are translated with.
+var Bundle = po.NewBundle()
+
+// text returns the translation of the message in the catalog of the locale.
+func text(locale, ctxt, id, idPlural string, n int, args ...interface{}) string {
+	var f = Bundle.File(locale)
+	switch {
+	case f != nil && idPlural != "":
+		return f.NPGetText(ctxt, id, idPlural, n, args...)
+	case f != nil:
+		return f.PGetText(ctxt, id, args...)
+	case idPlural != "" && n != 1:
+		id = idPlural
+	}
+	if len(args) == 0 {
+		return id
+	}
+	return fmt.Sprintf(id, args...)
+}
+{{range .Functions}}
+// {{.Name}} translates {{quote .Id}}.
+func {{.Name}}(locale string{{if .IdPlural}}, n int{{end}}{{range .Params}}, {{.Name}} {{.Type}}{{end}}) string {
+{{- if .Braces}}
+	return strings.NewReplacer({{range $i, $p := .Params}}{{if $i}}, {{end}}"{{"{"}}{{$p.Name}}{{"}"}}", {{$p.Name}}{{end}}).Replace(text(locale, {{quote .Ctxt}}, {{quote .Id}}, {{quote .IdPlural}}, {{if .IdPlural}}n{{else}}0{{end}}))
+{{- else}}
+	return text(locale, {{quote .Ctxt}}, {{quote .Id}}, {{quote .IdPlural}}, {{if .IdPlural}}n{{else}}0{{end}}{{range .Params}}, {{.Name}}{{end}})
+{{- end}}
+}
+{{end}}`))
+
+var keysTemplate = template.Must(template.New("keys").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(`// Code generated by pomsg. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/olebedev/gettext/po"
+{{range .Functions}}
+// {{.Name}} is the key of {{quote .Id}}.
+var {{.Name}} = po.Key{ {{- if .Ctxt}}Ctxt: {{quote .Ctxt}}, {{end}}Id: {{quote .Id}}{{if .IdPlural}}, IdPlural: {{quote .IdPlural}}{{end -}} }
+{{end}}`))
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/olebedev/gettext/po"
+)
+
+var pot = `
+#. name: CheckoutTotal
+#. params: amount
+msgid "Total: %s"
+msgstr ""
+
+msgid "Hello, {name}! You have {count} {count} messages."
+msgstr ""
+
+msgctxt "cart"
+msgid "%[2]d items for %.2[1]f"
+msgstr ""
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+`[1:]
+
+func TestFunctions(t *testing.T) {
+	var f, err = po.Parse(strings.NewReader(pot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fns, err := functions(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, fn := range fns {
+		var sig = fn.Name + "("
+		for i, p := range fn.Params {
+			if i > 0 {
+				sig += ", "
+			}
+			sig += p.Name + " " + p.Type
+		}
+		actual = append(actual, sig+")")
+	}
+	var expected = []string{
+		"CheckoutTotal(amount string)",
+		"HelloYouHaveMessages(name string, count string)",
+		"CartItemsFor(arg1 float64, arg2 int)",
+		"File(arg1 int)",
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestFunctionErrors(t *testing.T) {
+	var tests = []string{
+		"msgid \"Open\"\nmsgstr \"\"\n\nmsgid \"Open!\"\nmsgstr \"\"\n",
+		"#. params: a, b\nmsgid \"%s\"\nmsgstr \"\"\n",
+		"#. name: lower\nmsgid \"Open\"\nmsgstr \"\"\n",
+		"msgid \"Search for {text}\"\nmsgstr \"\"\n",
+		"#. params: strings\nmsgid \"Search for %s\"\nmsgstr \"\"\n",
+	}
+	for _, test := range tests {
+		var f, err = po.Parse(strings.NewReader(test))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := functions(f); err == nil {
+			t.Errorf("%q: expected an error", test)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	var f, _ = po.Parse(strings.NewReader(pot))
+	var buf bytes.Buffer
+	if err := generate(&buf, "msg", f, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"func CheckoutTotal(locale string, amount string) string {\n\treturn text(locale, \"\", \"Total: %s\", \"\", 0, amount)\n}",
+		"func File(locale string, n int, arg1 int) string {",
+		"strings.NewReplacer(\"{name}\", name, \"{count}\", count)",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestGenerateKeys(t *testing.T) {
+	var f, _ = po.Parse(strings.NewReader(pot))
+	var buf bytes.Buffer
+	if err := generate(&buf, "msg", f, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"var CheckoutTotal = po.Key{Id: \"Total: %s\"}",
+		"var CartItemsFor = po.Key{Ctxt: \"cart\", Id: \"%[2]d items for %.2[1]f\"}",
+		"var File = po.Key{Id: \"%d file\", IdPlural: \"%d files\"}",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "func ") {
+		t.Errorf("expected no functions in:\n%s", buf.String())
+	}
+}
+
+func TestGenerateTypeChecks(t *testing.T) {
+	var f, _ = po.Parse(strings.NewReader(pot))
+	var buf bytes.Buffer
+	if err := generate(&buf, "msg", f, false); err != nil {
+		t.Fatal(err)
+	}
+	var fset = token.NewFileSet()
+	file, err := parser.ParseFile(fset, "msg.go", buf.Bytes(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf = types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("msg", fset, []*ast.File{file}, nil); err != nil {
+		t.Errorf("%v in:\n%s", err, buf.String())
+	}
+}
@@ -0,0 +1,74 @@
+// Command pomsg generates a Go package with a typed function per message of
+// a PO template, whose parameters are those of the message's placeholders.
+//
+// Usage:
+//
+//	pomsg [-pkg name] [-o file] [-keys] template.pot
+//
+// For instance, the message
+//
+//	#. name: CheckoutTotal
+//	#. params: amount
+//	msgid "Total: %s"
+//
+// generates
+//
+//	func CheckoutTotal(locale string, amount string) string
+//
+// which translates it with the catalog of the locale in the generated
+// Bundle variable. Functions are named by a "name:" extracted comment, or
+// after the context and msgid. Parameters are named by a "params:" extracted
+// comment, after brace placeholders such as "{amount}", or numbered. Plural
+// messages take the quantity n first.
+//
+// With -keys, a po.Key variable is generated per message instead, e.g.
+//
+//	var CheckoutTotal = po.Key{Id: "Total: %s"}
+//
+// to be translated with File.GetTextKey or File.NGetTextKey.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/olebedev/gettext/po"
+)
+
+func main() {
+	var pkg = flag.String("pkg", "msg", "name of the generated `package`")
+	var out = flag.String("o", "", "write to `file` instead of the standard output")
+	var keys = flag.Bool("keys", false, "generate a po.Key per message instead of a function")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: pomsg [-pkg name] [-o file] [-keys] template.pot")
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *pkg, *out, *keys); err != nil {
+		fmt.Fprintln(os.Stderr, "pomsg:", err)
+		os.Exit(1)
+	}
+}
+
+func run(name, pkg, out string, keys bool) error {
+	r, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f, err := po.Parse(r)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := generate(&buf, pkg, f, keys); err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(out, buf.Bytes(), 0666)
+}
@@ -0,0 +1,95 @@
+// Command potool performs maintenance operations on PO files.
+//
+// Usage:
+//
+//	potool purge-obsolete [-older-than duration] [-w] file.po...
+//
+// purge-obsolete removes the obsolete messages ("#~") that have been obsolete
+// for longer than the given duration, e.g. 2160h for 90 days, or all of them
+// by default. The result is written to the standard output, or back to the
+// files with -w.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// commands holds the subcommands by name.
+var commands = map[string]func(args []string) error{
+	"purge-obsolete": purgeObsolete,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		usage()
+		os.Exit(2)
+	}
+	if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "potool:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "usage: potool <command> [arguments]\n\ncommands: %s\n", strings.Join(names, ", "))
+}
+
+func purgeObsolete(args []string) error {
+	var fs = flag.NewFlagSet("purge-obsolete", flag.ExitOnError)
+	var olderThan = fs.Duration("older-than", 0, "only purge messages obsolete for longer than `duration`")
+	var write = fs.Bool("w", false, "write the result back to the files")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("purge-obsolete: no files")
+	}
+	for _, name := range fs.Args() {
+		f, err := parseFile(name)
+		if err != nil {
+			return err
+		}
+		var n = f.PurgeObsolete(*olderThan)
+		if err := output(name, f, *write); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%s: purged %d obsolete messages\n", name, n)
+	}
+	return nil
+}
+
+func parseFile(name string) (*po.File, error) {
+	r, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	f, err := po.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return f, nil
+}
+
+// output writes the file to the standard output, or back to name.
+func output(name string, f *po.File, write bool) error {
+	if !write {
+		_, err := f.WriteTo(os.Stdout)
+		return err
+	}
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), 0666)
+}
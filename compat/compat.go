@@ -0,0 +1,235 @@
+// Package compat verifies that catalogs survive the po package unchanged, and
+// that its results agree with those of the GNU gettext tools.
+//
+// The fixtures in testdata pair each source file x.po with
+//
+//	x.msgcat.po    output of msgcat x.po
+//	x.msgunfmt.po  output of msgfmt -o - x.po | msgunfmt
+//
+// and are regenerated with the commands above when the sources change.
+// Catalogs are compared by their contents rather than byte by byte, as the
+// po package does not wrap lines the way the GNU tools do.
+package compat
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// Difference describes a discrepancy between two catalogs.
+type Difference struct {
+	Ctxt, Id  string // message the difference was found in, empty for the header
+	Field     string // e.g. "msgstr[1]", "flags" or "header Language"
+	Got, Want string
+}
+
+func (d Difference) String() string {
+	var where = "header"
+	if d.Id != "" {
+		where = strconv.Quote(d.Id)
+		if d.Ctxt != "" {
+			where = strconv.Quote(d.Ctxt) + " " + where
+		}
+	}
+	return fmt.Sprintf("%s: %s: got %q, want %q", where, d.Field, d.Got, d.Want)
+}
+
+// Error is returned by the verifications that found differences.
+type Error struct {
+	Differences []Difference
+}
+
+func (e *Error) Error() string {
+	var lines = make([]string, len(e.Differences))
+	for i, d := range e.Differences {
+		lines[i] = d.String()
+	}
+	return fmt.Sprintf("compat: %d differences:\n%s", len(lines), strings.Join(lines, "\n"))
+}
+
+// VerifyRoundTrip writes the file and parses the result, returning an *Error
+// if that lost or altered any of its contents.
+func VerifyRoundTrip(f *po.File) error {
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		return err
+	}
+	written, err := po.Parse(&buf)
+	if err != nil {
+		return fmt.Errorf("compat: parsing written file: %v", err)
+	}
+	return result(Compare(written, f))
+}
+
+// VerifyMsgcat checks that writing the file parsed from src gives the
+// contents of golden, the output of msgcat for src.
+func VerifyMsgcat(src, golden io.Reader) error {
+	f, err := po.Parse(src)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		return err
+	}
+	got, err := po.Parse(&buf)
+	if err != nil {
+		return fmt.Errorf("compat: parsing written file: %v", err)
+	}
+	want, err := po.Parse(golden)
+	if err != nil {
+		return fmt.Errorf("compat: parsing golden file: %v", err)
+	}
+	return result(Compare(got, want))
+}
+
+// VerifyMsgfmt checks that the messages msgfmt would compile from the file
+// parsed from src are those of golden, the output of msgfmt and msgunfmt for
+// src.
+func VerifyMsgfmt(src, golden io.Reader) error {
+	f, err := po.Parse(src)
+	if err != nil {
+		return err
+	}
+	want, err := po.Parse(golden)
+	if err != nil {
+		return fmt.Errorf("compat: parsing golden file: %v", err)
+	}
+	return result(compare(Compiled(f), want, false))
+}
+
+// Compiled returns a copy of the file with only what msgfmt compiles: the
+// header and the translated messages that are neither fuzzy nor obsolete,
+// without comments.
+func Compiled(f *po.File) *po.File {
+	var r = &po.File{Header: f.Header, Pluralize: f.Pluralize}
+	for _, msg := range f.Messages {
+		if len(msg.Str) == 0 || msg.Str[0] == "" || msg.HasFlag("fuzzy") || msg.Obsolete {
+			continue
+		}
+		r.Messages = append(r.Messages, &po.Message{
+			Ctxt:     msg.Ctxt,
+			Id:       msg.Id,
+			IdPlural: msg.IdPlural,
+			Str:      msg.Str,
+		})
+	}
+	return r
+}
+
+// Compare returns the differences of got from want, in the order of the
+// header fields, then of the messages of want.
+func Compare(got, want *po.File) []Difference {
+	return compare(got, want, true)
+}
+
+func compare(got, want *po.File, comments bool) []Difference {
+	var diffs = compareHeader(got, want)
+	var byKey = make(map[[2]string]*po.Message, len(got.Messages))
+	for _, msg := range got.Messages {
+		byKey[[2]string{msg.Ctxt, msg.Id}] = msg
+	}
+	var seen = make(map[[2]string]bool, len(want.Messages))
+	for _, w := range want.Messages {
+		var k = [2]string{w.Ctxt, w.Id}
+		seen[k] = true
+		if g := byKey[k]; g == nil {
+			diffs = append(diffs, Difference{w.Ctxt, w.Id, "message", "missing", "present"})
+		} else {
+			diffs = append(diffs, compareMessage(g, w, comments)...)
+		}
+	}
+	for _, g := range got.Messages {
+		if !seen[[2]string{g.Ctxt, g.Id}] {
+			diffs = append(diffs, Difference{g.Ctxt, g.Id, "message", "present", "missing"})
+		}
+	}
+	return diffs
+}
+
+func compareHeader(got, want *po.File) []Difference {
+	var keys []string
+	for k := range want.Header {
+		keys = append(keys, k)
+	}
+	for k := range got.Header {
+		if _, found := want.Header[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var diffs []Difference
+	for _, k := range keys {
+		var g, w = strings.Join(got.Header[k], "\n"), strings.Join(want.Header[k], "\n")
+		if g != w {
+			diffs = append(diffs, Difference{Field: "header " + k, Got: g, Want: w})
+		}
+	}
+	return diffs
+}
+
+func compareMessage(got, want *po.Message, comments bool) []Difference {
+	var diffs []Difference
+	var field = func(name, g, w string) {
+		if g != w {
+			diffs = append(diffs, Difference{want.Ctxt, want.Id, name, g, w})
+		}
+	}
+	field("obsolete", strconv.FormatBool(got.Obsolete), strconv.FormatBool(want.Obsolete))
+	field("msgid_plural", got.IdPlural, want.IdPlural)
+	for i := 0; i < len(got.Str) || i < len(want.Str); i++ {
+		var name = "msgstr"
+		if want.IdPlural != "" {
+			name = "msgstr[" + strconv.Itoa(i) + "]"
+		}
+		field(name, index(got.Str, i), index(want.Str, i))
+	}
+	if !comments {
+		return diffs
+	}
+	field("translator comments", strings.Join(got.TranslatorComments, "\n"), strings.Join(want.TranslatorComments, "\n"))
+	field("extracted comments", strings.Join(got.ExtractedComments, "\n"), strings.Join(want.ExtractedComments, "\n"))
+	field("references", references(got.References), references(want.References))
+	// msgcat puts the fuzzy flag first, and the format flags in a fixed order.
+	field("flags", sorted(got.Flags), sorted(want.Flags))
+	field("previous msgctxt", got.PrevCtxt, want.PrevCtxt)
+	field("previous msgid", got.PrevId, want.PrevId)
+	field("previous msgid_plural", got.PrevIdPlural, want.PrevIdPlural)
+	return diffs
+}
+
+func index(vals []string, i int) string {
+	if i < len(vals) {
+		return vals[i]
+	}
+	return ""
+}
+
+// references returns the references as written in PO files.
+func references(refs []po.Reference) string {
+	var vals = make([]string, len(refs))
+	for i, ref := range refs {
+		vals[i] = ref.String()
+	}
+	return strings.Join(vals, " ")
+}
+
+func sorted(vals []string) string {
+	vals = append([]string(nil), vals...)
+	sort.Strings(vals)
+	return strings.Join(vals, ", ")
+}
+
+// result returns an *Error holding the differences, if any.
+func result(diffs []Difference) error {
+	if len(diffs) == 0 {
+		return nil
+	}
+	return &Error{diffs}
+}
@@ -0,0 +1,68 @@
+package compat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/olebedev/gettext/po"
+)
+
+func open(t *testing.T, name string) *os.File {
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestFixtures(t *testing.T) {
+	sources, _ := filepath.Glob("testdata/*.po")
+	for _, src := range sources {
+		var name = filepath.Base(src)
+		if strings.Count(name, ".") > 1 {
+			continue
+		}
+		var base = strings.TrimSuffix(name, ".po")
+		if err := VerifyMsgcat(open(t, name), open(t, base+".msgcat.po")); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		if err := VerifyMsgfmt(open(t, name), open(t, base+".msgunfmt.po")); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		f, err := po.Parse(open(t, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyRoundTrip(f); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	var want = &po.File{Messages: []*po.Message{
+		{Id: "Open", Str: []string{"Otvoriť"}},
+		{Id: "Close", Str: []string{"Zavrieť"}, Comment: po.Comment{Flags: []string{"fuzzy", "c-format"}}},
+	}}
+	var got = &po.File{Messages: []*po.Message{
+		{Id: "Open", Str: []string{"Otvor"}},
+		{Id: "Close", Str: []string{"Zavrieť"}, Comment: po.Comment{Flags: []string{"c-format", "fuzzy"}}},
+		{Id: "Save", Str: []string{"Uložiť"}},
+	}}
+	var diffs = Compare(got, want)
+	var expected = []string{
+		`"Open": msgstr: got "Otvor", want "Otvoriť"`,
+		`"Save": message: got "present", want "missing"`,
+	}
+	if len(diffs) != len(expected) {
+		t.Fatalf("expected %d differences, got %v", len(expected), diffs)
+	}
+	for i, d := range diffs {
+		if d.String() != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], d)
+		}
+	}
+}
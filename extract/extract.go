@@ -0,0 +1,276 @@
+// Package extract extracts the translatable messages of Go source files into
+// a PO template, like xgettext.
+//
+// Messages are found in the calls of the functions and methods named by
+// keywords, e.g. "GetText" or "T", whose arguments are string literals.
+// Comments right above a call, or on the same line, are extracted for the
+// translators. The messages of html/template and text/template files are
+// extracted likewise from the calls of the functions of package tmpl.
+package extract
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// Keyword names a function whose calls are extracted, and the positions of
+// its msgctxt, msgid and msgid_plural arguments, starting at 1. Zero
+// positions denote arguments the function does not take.
+type Keyword struct {
+	Name               string
+	Ctxt, Id, IdPlural int
+}
+
+// DefaultKeywords are the lookup methods of po.File.
+var DefaultKeywords = []Keyword{
+	{Name: "GetText", Id: 1},
+	{Name: "NGetText", Id: 1, IdPlural: 2},
+	{Name: "PGetText", Ctxt: 1, Id: 2},
+	{Name: "NPGetText", Ctxt: 1, Id: 2, IdPlural: 3},
+}
+
+// ParseKeyword parses a keyword in the syntax of the xgettext --keyword
+// option: the name, optionally followed by a colon and the comma separated
+// positions of the msgid, the msgid_plural and, marked with "c", the msgctxt,
+// e.g. "T", "NT:1,2" or "PT:1c,2". The msgid defaults to the first argument.
+func ParseKeyword(s string) (Keyword, error) {
+	var name, spec, found = strings.Cut(s, ":")
+	var k = Keyword{Name: name, Id: 1}
+	if !token.IsIdentifier(name) {
+		return k, fmt.Errorf("extract: invalid keyword %q", s)
+	}
+	if !found {
+		return k, nil
+	}
+	var ids []int
+	for _, arg := range strings.Split(spec, ",") {
+		var ctxt = strings.HasSuffix(arg, "c")
+		var n, err = strconv.Atoi(strings.TrimSuffix(arg, "c"))
+		if err != nil || n < 1 {
+			return k, fmt.Errorf("extract: invalid keyword %q", s)
+		}
+		if ctxt {
+			k.Ctxt = n
+		} else {
+			ids = append(ids, n)
+		}
+	}
+	switch len(ids) {
+	case 2:
+		k.IdPlural = ids[1]
+		fallthrough
+	case 1:
+		k.Id = ids[0]
+	default:
+		return k, fmt.Errorf("extract: invalid keyword %q", s)
+	}
+	return k, nil
+}
+
+// FlagGoFormat marks the messages with Go format verbs.
+const FlagGoFormat = "go-format"
+
+// verbRe matches the fmt verbs, except "%%".
+var verbRe = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*(\d+|\*)?(\.(\d+|\*)?)?(\[\d+\])?[a-zA-Z]`)
+
+// Extractor collects the messages of Go source files.
+type Extractor struct {
+	Keywords []Keyword
+
+	// TemplateKeywords are the functions whose calls are extracted from
+	// templates, DefaultTemplateKeywords if nil.
+	TemplateKeywords []Keyword
+
+	// CommentTag, if set, only extracts the comments starting with it, e.g.
+	// "TRANSLATORS:".
+	CommentTag string
+
+	fset *token.FileSet
+	msgs []*po.Message
+	byId map[[2]string]*po.Message
+}
+
+// NewExtractor returns an extractor of the calls of the given keywords, or of
+// DefaultKeywords if none are given.
+func NewExtractor(keywords ...Keyword) *Extractor {
+	if len(keywords) == 0 {
+		keywords = DefaultKeywords
+	}
+	return &Extractor{Keywords: keywords, fset: token.NewFileSet(), byId: make(map[[2]string]*po.Message)}
+}
+
+// ParseFile extracts the messages of a Go source file. The source is read
+// from src, if not nil, as for go/parser.ParseFile, or from the named file.
+func (x *Extractor) ParseFile(filename string, src interface{}) error {
+	var file, err = parser.ParseFile(x.fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	x.extract(file)
+	return nil
+}
+
+func (x *Extractor) extract(file *ast.File) {
+	var comments = ast.NewCommentMap(x.fset, file, file.Comments)
+	var stack []ast.Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		var call, ok = n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var k = x.keyword(call)
+		if k == nil {
+			return true
+		}
+		var ctxt, okCtxt = arg(call, k.Ctxt)
+		var id, okId = arg(call, k.Id)
+		var idPlural, okPlural = arg(call, k.IdPlural)
+		if okCtxt && okId && okPlural && id != "" {
+			var pos = x.fset.Position(call.Pos())
+			x.add(ctxt, id, idPlural, po.Reference{File: pos.Filename, Line: pos.Line}, x.comments(comments, stack))
+		}
+		return true
+	})
+}
+
+// keyword returns the keyword of the function called, if any.
+func (x *Extractor) keyword(call *ast.CallExpr) *Keyword {
+	var name string
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		name = fn.Name
+	case *ast.SelectorExpr:
+		name = fn.Sel.Name
+	default:
+		return nil
+	}
+	for i := range x.Keywords {
+		if x.Keywords[i].Name == name {
+			return &x.Keywords[i]
+		}
+	}
+	return nil
+}
+
+// arg returns the value of the string constant at the position of the call's
+// arguments, or "" for position 0. It returns false unless the argument is a
+// string literal, or a concatenation of them.
+func arg(call *ast.CallExpr, pos int) (string, bool) {
+	if pos == 0 {
+		return "", true
+	}
+	if pos > len(call.Args) {
+		return "", false
+	}
+	return stringLit(call.Args[pos-1])
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			var s, err = strconv.Unquote(e.Value)
+			return s, err == nil
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			var x, okX = stringLit(e.X)
+			var y, okY = stringLit(e.Y)
+			return x + y, okX && okY
+		}
+	case *ast.ParenExpr:
+		return stringLit(e.X)
+	}
+	return "", false
+}
+
+// comments returns the lines of the comment right above the call, or else at
+// the end of its line, given the nodes enclosing the call.
+func (x *Extractor) comments(comments ast.CommentMap, stack []ast.Node) []string {
+	var line = x.fset.Position(stack[len(stack)-1].Pos()).Line
+	for i := len(stack) - 1; i >= 0; i-- {
+		for _, group := range comments[stack[i]] {
+			var end = x.fset.Position(group.End()).Line
+			if end != line && end != line-1 {
+				continue
+			}
+			var text = strings.TrimSpace(group.Text())
+			if x.CommentTag != "" && !strings.HasPrefix(text, x.CommentTag) {
+				return nil
+			}
+			return strings.Split(text, "\n")
+		}
+		if len(comments[stack[i]]) > 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// add adds the message, or merges it into the message with the same context
+// and msgid.
+func (x *Extractor) add(ctxt, id, idPlural string, ref po.Reference, comments []string) {
+	var m = x.byId[[2]string{ctxt, id}]
+	if m == nil {
+		m = &po.Message{Ctxt: ctxt, Id: id}
+		x.byId[[2]string{ctxt, id}] = m
+		x.msgs = append(x.msgs, m)
+	}
+	if m.IdPlural == "" && idPlural != "" {
+		m.IdPlural = idPlural
+		m.Str = nil
+	}
+	if m.Str == nil {
+		m.Str = []string{""}
+		if m.IdPlural != "" {
+			m.Str = []string{"", ""}
+		}
+	}
+	m.References = append(m.References, ref)
+	for _, c := range comments {
+		if !contains(m.ExtractedComments, c) {
+			m.ExtractedComments = append(m.ExtractedComments, c)
+		}
+	}
+	if !m.HasFlag(FlagGoFormat) && (verbRe.MatchString(id) || verbRe.MatchString(idPlural)) {
+		m.Flags = append(m.Flags, FlagGoFormat)
+	}
+}
+
+// Template returns the PO template of the messages extracted so far, in the
+// order they were found.
+func (x *Extractor) Template() *po.File {
+	var f = &po.File{
+		Header: textproto.MIMEHeader{
+			"Mime-Version":              {"1.0"},
+			"Content-Type":              {"text/plain; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"8bit"},
+		},
+		Messages:  append([]*po.Message(nil), x.msgs...),
+		Pluralize: po.PluralSelectorForLanguage("en"),
+	}
+	f.Reindex()
+	return f
+}
+
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package extract
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const src = `package main
+
+func main() {
+	// The verb, as on a button.
+	f.GetText("Open")
+	f.NGetText("%d file", "%d files", n) // n > 0
+	f.PGetText("menu", "Open")
+	f.GetText("Open")
+	f.GetText(id)
+	T("Multi" + "line")
+}
+`
+
+func TestExtract(t *testing.T) {
+	var x = NewExtractor(append(DefaultKeywords, Keyword{Name: "T", Id: 1})...)
+	if err := x.ParseFile("main.go", src); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	x.Template().WriteTo(&buf)
+	var expected = `msgid ""
+msgstr ""
+"Content-Transfer-Encoding: 8bit\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Mime-Version: 1.0\n"
+
+#. The verb, as on a button.
+#: main.go:5 main.go:8
+msgid "Open"
+msgstr ""
+
+#. n > 0
+#: main.go:6
+#, go-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+#: main.go:7
+msgctxt "menu"
+msgid "Open"
+msgstr ""
+
+#: main.go:10
+msgid "Multiline"
+msgstr ""
+
+`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestParseKeyword(t *testing.T) {
+	var cases = []struct {
+		spec     string
+		expected Keyword
+	}{
+		{"T", Keyword{Name: "T", Id: 1}},
+		{"NT:1,2", Keyword{Name: "NT", Id: 1, IdPlural: 2}},
+		{"PT:1c,2", Keyword{Name: "PT", Ctxt: 1, Id: 2}},
+		{"GetText:2", Keyword{Name: "GetText", Id: 2}},
+	}
+	for _, c := range cases {
+		if actual, err := ParseKeyword(c.spec); err != nil || !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("%s: expected %v, got %v, %v", c.spec, c.expected, actual, err)
+		}
+	}
+	for _, spec := range []string{"", "T:x", "T:0", "T:1c"} {
+		if _, err := ParseKeyword(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+const page = `{{define "title"}}{{pgettext "title" "Files"}}{{end}}
+<h1>{{template "title"}}</h1>
+{{/* The verb, as on a button. */}}
+<button>{{gettext "Open"}}</button>
+{{if .Files}}
+	<p>{{ngettext "%d file" "%d files" (len .Files) (len .Files)}}</p>
+{{else}}
+	<p>{{gettext .Empty}}</p> {{/* ignored */}}
+{{end}}
+{{range .Files}}{{printf "%s: %s" (gettext "Name") .Name}}{{end}}
+`
+
+func TestParseTemplate(t *testing.T) {
+	var x = NewExtractor()
+	if err := x.ParseTemplate("page.html", page); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.ParseFile("main.go", "package main\n\nfunc main() {\n\tf.GetText(\"Open\")\n}\n"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	x.Template().WriteTo(&buf)
+	var expected = `msgid ""
+msgstr ""
+"Content-Transfer-Encoding: 8bit\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Mime-Version: 1.0\n"
+
+#: page.html:1
+msgctxt "title"
+msgid "Files"
+msgstr ""
+
+#. The verb, as on a button.
+#: page.html:4 main.go:4
+msgid "Open"
+msgstr ""
+
+#: page.html:6
+#, go-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+#: page.html:10
+msgid "Name"
+msgstr ""
+
+`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	if err := x.ParseTemplate("broken.html", "{{gettext"); err == nil {
+		t.Error("expected an error for a malformed template")
+	}
+}
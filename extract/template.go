@@ -0,0 +1,191 @@
+package extract
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// DefaultTemplateKeywords are the translation functions of templates of
+// package tmpl.
+var DefaultTemplateKeywords = []Keyword{
+	{Name: "gettext", Id: 1},
+	{Name: "ngettext", Id: 1, IdPlural: 2},
+	{Name: "pgettext", Ctxt: 1, Id: 2},
+	{Name: "npgettext", Ctxt: 1, Id: 2, IdPlural: 3},
+}
+
+// templateCall is a call of a keyword found in a template.
+type templateCall struct {
+	pos                parse.Pos
+	ctxt, id, idPlural string
+	comments           []string
+}
+
+// ParseTemplate extracts the messages of an html/template or text/template
+// file, from the calls of the TemplateKeywords, or of
+// DefaultTemplateKeywords if nil, whose arguments are string constants, e.g.
+// {{gettext "Open"}}. The comment right before a call, on the line above or
+// on the same line, e.g. {{/* The verb. */}}, is extracted for the
+// translators. The source is read from src, if not nil, as for ParseFile, or
+// from the named file.
+func (x *Extractor) ParseTemplate(filename string, src interface{}) error {
+	var text, err = readSource(filename, src)
+	if err != nil {
+		return err
+	}
+	var trees = make(map[string]*parse.Tree)
+	var t = parse.New(filename)
+	t.Mode = parse.ParseComments | parse.SkipFuncCheck
+	if _, err := t.Parse(text, "", "", trees); err != nil {
+		return err
+	}
+	var keywords = x.TemplateKeywords
+	if keywords == nil {
+		keywords = DefaultTemplateKeywords
+	}
+	var line = func(pos parse.Pos) int {
+		return 1 + strings.Count(text[:pos], "\n")
+	}
+	var calls []templateCall
+	for _, tree := range trees {
+		x.walkTemplate(tree.Root, keywords, line, &calls)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].pos < calls[j].pos })
+	for _, c := range calls {
+		x.add(c.ctxt, c.id, c.idPlural, po.Reference{File: filename, Line: line(c.pos)}, c.comments)
+	}
+	return nil
+}
+
+// walkTemplate collects the calls of the keywords in the node of a template.
+func (x *Extractor) walkTemplate(node parse.Node, keywords []Keyword, line func(parse.Pos) int, calls *[]templateCall) {
+	var pipe = func(p *parse.PipeNode, comments []string) {
+		if p != nil {
+			x.templatePipe(p, keywords, comments, calls)
+		}
+	}
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		var comment *parse.CommentNode
+		for _, child := range n.Nodes {
+			if c, ok := child.(*parse.CommentNode); ok {
+				comment = c
+				continue
+			}
+			var comments []string
+			if comment != nil {
+				var end = line(comment.Pos) + strings.Count(comment.Text, "\n")
+				if l := line(child.Position()); end == l || end == l-1 {
+					comments = x.templateComment(comment.Text)
+				}
+			}
+			if a, ok := child.(*parse.ActionNode); ok {
+				pipe(a.Pipe, comments)
+			} else {
+				x.walkTemplate(child, keywords, line, calls)
+			}
+			if _, ok := child.(*parse.TextNode); !ok {
+				comment = nil
+			}
+		}
+	case *parse.IfNode:
+		pipe(n.Pipe, nil)
+		x.walkTemplate(n.List, keywords, line, calls)
+		x.walkTemplate(n.ElseList, keywords, line, calls)
+	case *parse.RangeNode:
+		pipe(n.Pipe, nil)
+		x.walkTemplate(n.List, keywords, line, calls)
+		x.walkTemplate(n.ElseList, keywords, line, calls)
+	case *parse.WithNode:
+		pipe(n.Pipe, nil)
+		x.walkTemplate(n.List, keywords, line, calls)
+		x.walkTemplate(n.ElseList, keywords, line, calls)
+	case *parse.TemplateNode:
+		pipe(n.Pipe, nil)
+	}
+}
+
+// templatePipe collects the calls of the keywords in a pipeline, and in the
+// pipelines of its arguments.
+func (x *Extractor) templatePipe(p *parse.PipeNode, keywords []Keyword, comments []string, calls *[]templateCall) {
+	for _, cmd := range p.Cmds {
+		for _, a := range cmd.Args {
+			if sub, ok := a.(*parse.PipeNode); ok {
+				x.templatePipe(sub, keywords, comments, calls)
+			}
+		}
+		var ident, ok = cmd.Args[0].(*parse.IdentifierNode)
+		if !ok {
+			continue
+		}
+		for _, k := range keywords {
+			if k.Name != ident.Ident {
+				continue
+			}
+			var ctxt, okCtxt = templateArg(cmd, k.Ctxt)
+			var id, okId = templateArg(cmd, k.Id)
+			var idPlural, okPlural = templateArg(cmd, k.IdPlural)
+			if okCtxt && okId && okPlural && id != "" {
+				*calls = append(*calls, templateCall{cmd.Pos, ctxt, id, idPlural, comments})
+			}
+			break
+		}
+	}
+}
+
+// templateArg returns the string constant at the position of the arguments
+// of the command, as arg does.
+func templateArg(cmd *parse.CommandNode, pos int) (string, bool) {
+	if pos == 0 {
+		return "", true
+	}
+	if pos >= len(cmd.Args) {
+		return "", false
+	}
+	var s, ok = cmd.Args[pos].(*parse.StringNode)
+	if !ok {
+		return "", false
+	}
+	return s.Text, true
+}
+
+// templateComment returns the lines of a template comment, e.g.
+// "/* The verb. */", unless it lacks the CommentTag.
+func (x *Extractor) templateComment(text string) []string {
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/"))
+	if text == "" || x.CommentTag != "" && !strings.HasPrefix(text, x.CommentTag) {
+		return nil
+	}
+	var lines = strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
+
+// readSource returns the source given as for go/parser.ParseFile: src, if
+// not nil, as a string, []byte or io.Reader, or else the named file.
+func readSource(filename string, src interface{}) (string, error) {
+	switch s := src.(type) {
+	case nil:
+		var data, err = os.ReadFile(filename)
+		return string(data), err
+	case string:
+		return s, nil
+	case []byte:
+		return string(s), nil
+	case io.Reader:
+		var data, err = io.ReadAll(s)
+		return string(data), err
+	}
+	return "", fmt.Errorf("extract: invalid source type %T", src)
+}
@@ -0,0 +1,318 @@
+// Package gettext mirrors the runtime API of C gettext, for porting code
+// written against it: the catalogs of text domains are bound to directories
+// with BindTextdomain, the default domain is set with Textdomain, and
+// messages are translated with Gettext, DGettext, DNGettext and the like,
+// for the locale set with SetLocale, or else that of the user, given by
+// UserLocale.
+//
+// The catalogs are loaded on first use from
+// <dir>/<locale>/LC_MESSAGES/<domain>.mo, or .po. The state is global to the
+// process, and safe for concurrent use. Translations are returned as they
+// are, without formatting their fmt verbs.
+//
+// Web applications translate each request in its own locale instead, with
+// the catalog of a po.Bundle selected by Middleware, and FromContext.
+package gettext
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// DefaultDir is the directory of the catalogs of the domains not bound by
+// BindTextdomain, as in C.
+const DefaultDir = "/usr/share/locale"
+
+// Category is a locale category, naming the directory of the catalogs of
+// DCGettext.
+type Category int
+
+// The locale categories.
+const (
+	LCCtype Category = iota
+	LCNumeric
+	LCTime
+	LCCollate
+	LCMonetary
+	LCMessages
+	LCAll
+)
+
+var categoryNames = [...]string{"LC_CTYPE", "LC_NUMERIC", "LC_TIME", "LC_COLLATE", "LC_MONETARY", "LC_MESSAGES", "LC_ALL"}
+
+func (c Category) String() string {
+	if c < 0 || int(c) >= len(categoryNames) {
+		return "LC_MESSAGES"
+	}
+	return categoryNames[c]
+}
+
+// catalogKey identifies a loaded catalog.
+type catalogKey struct {
+	domain   string
+	category Category
+	locale   string
+}
+
+var state = struct {
+	sync.Mutex
+	domain    string
+	dirs      map[string]string       // by domain
+	languages []string                // locales looked up, in order
+	localeSet bool                    // languages set, by SetLocale or from the environment
+	catalogs  map[catalogKey]*po.File // nil if missing
+}{domain: "messages"}
+
+// Textdomain sets the default domain of Gettext and the like, unless domain
+// is empty, and returns the default domain, "messages" initially.
+func Textdomain(domain string) string {
+	state.Lock()
+	defer state.Unlock()
+	if domain != "" {
+		state.domain = domain
+	}
+	return state.domain
+}
+
+// BindTextdomain sets the directory of the catalogs of the domain, unless dir
+// is empty, and returns the directory.
+func BindTextdomain(domain, dir string) string {
+	state.Lock()
+	defer state.Unlock()
+	if dir != "" {
+		if state.dirs == nil {
+			state.dirs = make(map[string]string)
+		}
+		state.dirs[domain] = dir
+		for k := range state.catalogs {
+			if k.domain == domain {
+				delete(state.catalogs, k)
+			}
+		}
+	}
+	return dirLocked(domain)
+}
+
+// dirLocked returns the directory of the catalogs of the domain.
+func dirLocked(domain string) string {
+	if dir, found := state.dirs[domain]; found {
+		return dir
+	}
+	return DefaultDir
+}
+
+// SetLocale sets the locale of the translations, e.g. "sk_SK.UTF-8", and
+// returns it. The empty locale selects that of the user, as
+// setlocale(LC_ALL, "") does, given by UserLocale, with the priority list of
+// the LANGUAGE variable, if set. Messages are not translated for the "C" and
+// "POSIX" locales. Until SetLocale is called, the locale is that of the user.
+func SetLocale(locale string) string {
+	var languages []string
+	if locale == "" {
+		locale, languages = userLanguages()
+	} else if !isCLocale(locale) {
+		languages = []string{locale}
+	}
+	state.Lock()
+	defer state.Unlock()
+	state.languages, state.localeSet = languages, true
+	return locale
+}
+
+// UserLocale returns the locale of the user: that of the LC_ALL, LC_MESSAGES
+// or LANG environment variables, in this order, or else that of the system,
+// i.e. the user default UI language on Windows, or "C".
+func UserLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			return locale
+		}
+	}
+	if locale := systemLocale(); locale != "" {
+		return locale
+	}
+	return "C"
+}
+
+// userLanguages returns the locale of the user, and the locales looked up
+// for it, in order.
+func userLanguages() (string, []string) {
+	var locale = UserLocale()
+	if isCLocale(locale) {
+		return locale, nil
+	}
+	var languages []string
+	if list := os.Getenv("LANGUAGE"); list != "" {
+		languages = strings.Split(list, ":")
+	}
+	return locale, append(languages, locale)
+}
+
+func isCLocale(locale string) bool {
+	return locale == "" || locale == "C" || locale == "POSIX" || strings.HasPrefix(locale, "C.")
+}
+
+// Gettext translates msgid in the default domain.
+func Gettext(msgid string) string {
+	return DCNPGettext("", LCMessages, "", msgid, "", 1)
+}
+
+// NGettext translates msgid in the default domain, in the plural form of n.
+// Untranslated messages are msgid for n = 1, and plural otherwise.
+func NGettext(msgid, plural string, n int) string {
+	return DCNPGettext("", LCMessages, "", msgid, plural, n)
+}
+
+// PGettext translates msgid in the given context of the default domain.
+func PGettext(ctxt, msgid string) string {
+	return DCNPGettext("", LCMessages, ctxt, msgid, "", 1)
+}
+
+// NPGettext is like NGettext, for msgid in the given context.
+func NPGettext(ctxt, msgid, plural string, n int) string {
+	return DCNPGettext("", LCMessages, ctxt, msgid, plural, n)
+}
+
+// DGettext translates msgid in the domain, or in the default domain if
+// empty.
+func DGettext(domain, msgid string) string {
+	return DCNPGettext(domain, LCMessages, "", msgid, "", 1)
+}
+
+// DNGettext is like NGettext, in the domain.
+func DNGettext(domain, msgid, plural string, n int) string {
+	return DCNPGettext(domain, LCMessages, "", msgid, plural, n)
+}
+
+// DPGettext is like PGettext, in the domain.
+func DPGettext(domain, ctxt, msgid string) string {
+	return DCNPGettext(domain, LCMessages, ctxt, msgid, "", 1)
+}
+
+// DNPGettext is like NPGettext, in the domain.
+func DNPGettext(domain, ctxt, msgid, plural string, n int) string {
+	return DCNPGettext(domain, LCMessages, ctxt, msgid, plural, n)
+}
+
+// DCGettext is like DGettext, with the catalogs of the category.
+func DCGettext(domain, msgid string, category Category) string {
+	return DCNPGettext(domain, category, "", msgid, "", 1)
+}
+
+// DCNGettext is like DNGettext, with the catalogs of the category.
+func DCNGettext(domain, msgid, plural string, n int, category Category) string {
+	return DCNPGettext(domain, category, "", msgid, plural, n)
+}
+
+// DCNPGettext translates msgid in the given context, the empty one for none,
+// of the domain, with the catalogs of the category, in the plural form of n
+// if plural is not empty. The catalogs of the locales are searched in turn.
+func DCNPGettext(domain string, category Category, ctxt, msgid, plural string, n int) string {
+	for _, f := range catalogs(domain, category) {
+		var m = f.Lookup(ctxt, msgid)
+		if m == nil {
+			continue
+		}
+		var i = 0
+		switch {
+		case plural == "":
+		case f.Pluralize != nil:
+			i = f.Pluralize(n)
+		case n != 1:
+			i = 1 // as in English, for catalogs without Plural-Forms
+		}
+		if i < len(m.Str) && m.Str[i] != "" {
+			return m.Str[i]
+		}
+	}
+	if plural != "" && n != 1 {
+		return plural
+	}
+	return msgid
+}
+
+// catalogs returns the catalogs of the domain and category for the locales,
+// in order, loading them as needed. Catalogs are loaded without holding the
+// lock, so that lookups of other catalogs do not wait for them.
+func catalogs(domain string, category Category) []*po.File {
+	state.Lock()
+	defer state.Unlock()
+	if domain == "" {
+		domain = state.domain
+	}
+	if !state.localeSet {
+		_, state.languages = userLanguages()
+		state.localeSet = true
+	}
+	var dir = dirLocked(domain)
+	var r []*po.File
+	for _, language := range state.languages {
+		for _, locale := range variants(language) {
+			var k = catalogKey{domain, category, locale}
+			var f, loaded = state.catalogs[k]
+			if !loaded {
+				state.Unlock()
+				f = load(filepath.Join(dir, locale, category.String(), domain))
+				state.Lock()
+				if cached, loaded := state.catalogs[k]; loaded {
+					f = cached // loaded meanwhile
+				} else if dirLocked(domain) == dir {
+					if state.catalogs == nil {
+						state.catalogs = make(map[catalogKey]*po.File)
+					}
+					state.catalogs[k] = f
+				}
+			}
+			if f != nil {
+				r = append(r, f)
+				break
+			}
+		}
+	}
+	return r
+}
+
+// load loads the catalog named name.mo, or name.po, or returns nil.
+func load(name string) *po.File {
+	for _, ext := range []string{".mo", ".po"} {
+		if f, err := po.LoadFile(name + ext); err == nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// variants returns the locales whose catalogs serve the locale, from the
+// most specific, as C gettext: "sr_RS.UTF-8@latin", "sr_RS@latin",
+// "sr_RS", "sr@latin", "sr".
+func variants(locale string) []string {
+	var r = []string{locale}
+	var add = func(l string) {
+		for _, v := range r {
+			if v == l {
+				return
+			}
+		}
+		r = append(r, l)
+	}
+	var modifier string
+	if i := strings.IndexByte(locale, '@'); i >= 0 {
+		locale, modifier = locale[:i], locale[i:]
+	}
+	if i := strings.IndexByte(locale, '.'); i >= 0 {
+		locale = locale[:i]
+	}
+	var language = locale
+	if i := strings.IndexByte(locale, '_'); i >= 0 {
+		language = locale[:i]
+	}
+	add(locale + modifier)
+	add(locale)
+	add(language + modifier)
+	add(language)
+	return r
+}
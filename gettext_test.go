@@ -0,0 +1,187 @@
+package gettext
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestGettext(t *testing.T) {
+	var root = t.TempDir()
+	var write = func(name, data string) {
+		name = filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("sk/LC_MESSAGES/app.po", `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+msgid "Open"
+msgstr "Otvoriť"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Otvor"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d súbor"
+msgstr[1] "%d súbory"
+msgstr[2] "%d súborov"
+
+#, fuzzy
+msgid "Close"
+msgstr "Zatvoriť"
+`)
+	write("sk/LC_MESSAGES/billing.po", `msgid "Open"
+msgstr "Otvoriť (billing)"
+
+msgid "%d invoice"
+msgid_plural "%d invoices"
+msgstr[0] "%d faktúra"
+msgstr[1] "%d faktúry"
+`)
+	write("sk/LC_TIME/app.po", `msgid "Open"
+msgstr "Otvoriť (time)"
+`)
+	write("de/LC_MESSAGES/app.po", `msgid "Save"
+msgstr "Speichern"
+`)
+
+	defer Textdomain(Textdomain(""))
+	defer SetLocale("C")
+	if domain := Textdomain("app"); domain != "app" {
+		t.Errorf("unexpected domain %q", domain)
+	}
+	if dir := BindTextdomain("app", root); dir != root {
+		t.Errorf("unexpected dir %q", dir)
+	}
+	BindTextdomain("billing", root)
+	if dir := BindTextdomain("other", ""); dir != DefaultDir {
+		t.Errorf("unexpected default dir %q", dir)
+	}
+
+	SetLocale("C")
+	if actual := Gettext("Open"); actual != "Open" {
+		t.Errorf("C: unexpected translation %q", actual)
+	}
+
+	SetLocale("sk_SK.UTF-8")
+	for _, c := range []struct{ actual, expected string }{
+		{Gettext("Open"), "Otvoriť"},
+		{PGettext("menu", "Open"), "Otvor"},
+		{Gettext("Close"), "Close"},
+		{Gettext("Save"), "Save"},
+		{NGettext("%d file", "%d files", 1), "%d súbor"},
+		{NGettext("%d file", "%d files", 3), "%d súbory"},
+		{NGettext("%d file", "%d files", 5), "%d súborov"},
+		{NGettext("%d day", "%d days", 1), "%d day"},
+		{NGettext("%d day", "%d days", 2), "%d days"},
+		{DGettext("billing", "Open"), "Otvoriť (billing)"},
+		{DNGettext("billing", "%d invoice", "%d invoices", 1), "%d faktúra"},
+		{DNGettext("billing", "%d invoice", "%d invoices", 2), "%d faktúry"},
+		{DGettext("", "Open"), "Otvoriť"},
+		{DGettext("missing", "Open"), "Open"},
+		{DCGettext("app", "Open", LCTime), "Otvoriť (time)"},
+	} {
+		if c.actual != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, c.actual)
+		}
+	}
+
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	t.Setenv("LANGUAGE", "sk:de")
+	if locale := SetLocale(""); locale != "de_DE.UTF-8" {
+		t.Errorf("unexpected locale %q", locale)
+	}
+	if actual := Gettext("Open"); actual != "Otvoriť" {
+		t.Errorf("unexpected translation %q", actual)
+	}
+	if actual := Gettext("Save"); actual != "Speichern" {
+		t.Errorf("unexpected fallback translation %q", actual)
+	}
+}
+
+func TestGettextConcurrent(t *testing.T) {
+	var root = t.TempDir()
+	var name = filepath.Join(root, "sk", "LC_MESSAGES", "app.po")
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte("msgid \"Open\"\nmsgstr \"Otvoriť\"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	defer SetLocale("C")
+	SetLocale("sk")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if actual := DGettext("app", "Open"); actual != "Otvoriť" && actual != "Open" {
+					t.Errorf("unexpected translation %q", actual)
+				}
+				BindTextdomain("app", root)
+			}
+		}()
+	}
+	wg.Wait()
+	if actual := DGettext("app", "Open"); actual != "Otvoriť" {
+		t.Errorf("unexpected translation %q", actual)
+	}
+}
+
+func TestUserLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "sk_SK.UTF-8")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	if actual := UserLocale(); actual != "sk_SK.UTF-8" {
+		t.Errorf("expected LC_MESSAGES, got %q", actual)
+	}
+	t.Setenv("LC_ALL", "C")
+	if actual := UserLocale(); actual != "C" {
+		t.Errorf("expected LC_ALL, got %q", actual)
+	}
+}
+
+func TestUserLocaleByDefault(t *testing.T) {
+	var root = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sk", "LC_MESSAGES"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "sk", "LC_MESSAGES", "cli.po"), []byte("msgid \"Open\"\nmsgstr \"Otvoriť\"\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LC_ALL", "sk_SK.UTF-8")
+	t.Setenv("LANGUAGE", "")
+	BindTextdomain("cli", root)
+	state.Lock()
+	state.localeSet = false
+	state.Unlock()
+	defer SetLocale("C")
+	if actual := DGettext("cli", "Open"); actual != "Otvoriť" {
+		t.Errorf("expected the translation of the user locale, got %q", actual)
+	}
+}
+
+func TestVariants(t *testing.T) {
+	var actual = variants("sr_RS.UTF-8@latin")
+	var expected = []string{"sr_RS.UTF-8@latin", "sr_RS@latin", "sr_RS", "sr@latin", "sr"}
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Package lite looks up translations in compiled catalogs, with a minimal
+// footprint for TinyGo and WebAssembly targets.
+//
+// It depends on no other package, and in particular neither parses nor
+// writes PO files: catalogs are built with New, e.g. by the code po2go
+// generates, or compiled from PO files with the Compile method of po.File.
+package lite
+
+// Message is a compiled translation.
+type Message struct {
+	Ctxt string   // msgctxt, if any
+	Id   string   // msgid
+	Str  []string // msgstr, or msgstr[n] of plural messages
+}
+
+// Catalog holds the compiled translations of a locale. A nil catalog returns
+// the source strings.
+type Catalog struct {
+	plural func(n int) int
+	msgs   map[key][]string
+}
+
+type key struct {
+	ctxt, id string
+}
+
+// New returns a catalog of the messages, selecting their plural forms with
+// plural, or as in English if nil.
+func New(plural func(n int) int, msgs []Message) *Catalog {
+	if plural == nil {
+		plural = english
+	}
+	var c = &Catalog{plural, make(map[key][]string, len(msgs))}
+	for _, msg := range msgs {
+		c.msgs[key{msg.Ctxt, msg.Id}] = msg.Str
+	}
+	return c
+}
+
+func english(n int) int {
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
+
+// Len returns the number of messages in the catalog.
+func (c *Catalog) Len() int {
+	if c == nil {
+		return 0
+	}
+	return len(c.msgs)
+}
+
+// GetText returns the translation of id, or id if missing.
+func (c *Catalog) GetText(id string) string {
+	return c.lookup("", id, 0, id)
+}
+
+// PGetText returns the translation of id in the context ctxt, or id if
+// missing.
+func (c *Catalog) PGetText(ctxt, id string) string {
+	return c.lookup(ctxt, id, 0, id)
+}
+
+// NGetText returns the translation of id for the quantity n, or id or
+// idPlural if missing.
+func (c *Catalog) NGetText(id, idPlural string, n int) string {
+	return c.NPGetText("", id, idPlural, n)
+}
+
+// NPGetText is like NGetText, for the message in the context ctxt.
+func (c *Catalog) NPGetText(ctxt, id, idPlural string, n int) string {
+	var fallback = id
+	if n != 1 {
+		fallback = idPlural
+	}
+	if c == nil {
+		return fallback
+	}
+	return c.lookup(ctxt, id, c.plural(n), fallback)
+}
+
+func (c *Catalog) lookup(ctxt, id string, i int, fallback string) string {
+	if c == nil {
+		return fallback
+	}
+	if strs := c.msgs[key{ctxt, id}]; i < len(strs) && strs[i] != "" {
+		return strs[i]
+	}
+	return fallback
+}
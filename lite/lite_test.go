@@ -0,0 +1,40 @@
+package lite
+
+import "testing"
+
+func TestCatalog(t *testing.T) {
+	var c = New(func(n int) int {
+		switch {
+		case n == 1:
+			return 0
+		case n >= 2 && n <= 4:
+			return 1
+		}
+		return 2
+	}, []Message{
+		{Id: "Open", Str: []string{"Otvoriť"}},
+		{Ctxt: "menu", Id: "Quit", Str: []string{"Koniec"}},
+		{Id: "%d file", Str: []string{"%d súbor", "%d súbory", ""}},
+	})
+	var tests = []struct {
+		actual, expected string
+	}{
+		{c.GetText("Open"), "Otvoriť"},
+		{c.GetText("Save"), "Save"},
+		{c.GetText("Quit"), "Quit"},
+		{c.PGetText("menu", "Quit"), "Koniec"},
+		{c.NGetText("%d file", "%d files", 1), "%d súbor"},
+		{c.NGetText("%d file", "%d files", 3), "%d súbory"},
+		{c.NGetText("%d file", "%d files", 5), "%d files"},
+	}
+	var nilCatalog *Catalog
+	tests = append(tests, []struct{ actual, expected string }{
+		{nilCatalog.GetText("Open"), "Open"},
+		{nilCatalog.NGetText("%d file", "%d files", 3), "%d files"},
+	}...)
+	for i, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%d: expected %q, got %q", i, test.expected, test.actual)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package gettext
+
+// systemLocale returns "", the locale of Unix systems being that of the
+// environment.
+func systemLocale() string {
+	return ""
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package gettext
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                 = syscall.NewLazyDLL("kernel32.dll")
+	getUserDefaultUILanguage = kernel32.NewProc("GetUserDefaultUILanguage")
+	lcidToLocaleName         = kernel32.NewProc("LCIDToLocaleName")
+)
+
+// localeNameMaxLength is LOCALE_NAME_MAX_LENGTH.
+const localeNameMaxLength = 85
+
+// systemLocale returns the user default UI language, e.g. "sk_SK", or "" if
+// unknown.
+func systemLocale() string {
+	if getUserDefaultUILanguage.Find() != nil || lcidToLocaleName.Find() != nil {
+		return ""
+	}
+	var lang, _, _ = getUserDefaultUILanguage.Call()
+	var buf [localeNameMaxLength]uint16
+	var n, _, _ = lcidToLocaleName.Call(lang, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)), 0)
+	if n == 0 {
+		return ""
+	}
+	return strings.Replace(syscall.UTF16ToString(buf[:]), "-", "_", -1)
+}
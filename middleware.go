@@ -0,0 +1,133 @@
+package gettext
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// Source is a part of a request its locale is detected from.
+type Source int
+
+// The sources of the locale of a request.
+const (
+	// FromQuery is the query parameter named by MiddlewareOptions.Query.
+	FromQuery Source = iota
+	// FromCookie is the cookie named by MiddlewareOptions.Cookie.
+	FromCookie
+	// FromHeader is the Accept-Language header.
+	FromHeader
+)
+
+// MiddlewareOptions configure the detection of the locale of requests by
+// Middleware.
+type MiddlewareOptions struct {
+	// Order lists the sources of the locale, by priority. The default is
+	// FromQuery, FromCookie, FromHeader.
+	Order []Source
+	// Query is the name of the query parameter of the locale, "lang" by
+	// default.
+	Query string
+	// Cookie is the name of the cookie of the locale, "lang" by default.
+	Cookie string
+	// Headers makes the middleware set the Content-Language and Vary headers
+	// of the responses, as po.LanguageHeaders does. Responses for which no
+	// locale matches get the Vary header too, as they depend on the same
+	// request headers.
+	Headers bool
+	// Logger, if set, receives a debug record of the locale selected for
+	// each request, "" if none, with the language tags it was selected from.
+	Logger *slog.Logger
+}
+
+// contextKey is the key of the locale of a request in its context.
+type contextKey struct{}
+
+// localized is the locale of a request, and its catalog.
+type localized struct {
+	locale string
+	file   *po.File
+}
+
+// NewContext returns a copy of ctx carrying the locale and its catalog.
+func NewContext(ctx context.Context, locale string, f *po.File) context.Context {
+	return context.WithValue(ctx, contextKey{}, localized{locale, f})
+}
+
+// FromContext returns the catalog carried by ctx, e.g. that of the locale of
+// a request selected by Middleware, or nil.
+func FromContext(ctx context.Context) *po.File {
+	var l, _ = ctx.Value(contextKey{}).(localized)
+	return l.file
+}
+
+// LocaleFromContext returns the locale carried by ctx, or "".
+func LocaleFromContext(ctx context.Context) string {
+	var l, _ = ctx.Value(contextKey{}).(localized)
+	return l.locale
+}
+
+// Middleware returns a middleware detecting the locale of each request, as
+// negotiated by po.Bundle.MatchLocale with the locales of its sources in
+// turn, and storing the catalog of the bundle for it in the context of the
+// request, for FromContext. The context carries no catalog if no locale
+// matches and the bundle has no default.
+func Middleware(b *po.Bundle, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	if opts.Order == nil {
+		opts.Order = []Source{FromQuery, FromCookie, FromHeader}
+	}
+	if opts.Query == "" {
+		opts.Query = "lang"
+	}
+	if opts.Cookie == "" {
+		opts.Cookie = "lang"
+	}
+	var headers po.LanguageHeaders
+	for _, source := range opts.Order {
+		if source == FromCookie {
+			headers.Cookie = opts.Cookie
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var tags []string
+			for _, source := range opts.Order {
+				switch source {
+				case FromQuery:
+					tags = append(tags, languageTag(r.URL.Query().Get(opts.Query)))
+				case FromCookie:
+					if c, err := r.Cookie(opts.Cookie); err == nil {
+						tags = append(tags, languageTag(c.Value))
+					}
+				case FromHeader:
+					tags = append(tags, po.ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+				}
+			}
+			// Equal qualities keep the order of the sources.
+			var locale = b.MatchLocale(strings.Join(tags, ","))
+			if opts.Logger != nil {
+				opts.Logger.LogAttrs(r.Context(), slog.LevelDebug, "gettext: locale selected",
+					slog.String("locale", locale), slog.Any("tags", tags))
+			}
+			if locale != "" {
+				r = r.WithContext(NewContext(r.Context(), locale, b.File(locale)))
+			}
+			if opts.Headers {
+				headers.Set(w.Header(), locale)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// languageTag returns the language tag of a query parameter or cookie,
+// without what would be parsed as other tags or parameters.
+func languageTag(value string) string {
+	if i := strings.IndexAny(value, ",;"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,83 @@
+package gettext
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/olebedev/gettext/po"
+)
+
+func TestMiddleware(t *testing.T) {
+	var b = po.NewBundle()
+	for locale, str := range map[string]string{"de": "Öffnen", "sk": "Otvoriť", "en": "Open"} {
+		f, err := po.Parse(strings.NewReader("msgid \"Open\"\nmsgstr \"" + str + "\"\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Add(locale, f)
+	}
+	var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f := FromContext(r.Context()); f != nil {
+			w.Write([]byte(LocaleFromContext(r.Context()) + ": " + f.GetText("Open")))
+		}
+	})
+	var serve = func(opts MiddlewareOptions, target, cookie, accept string) *httptest.ResponseRecorder {
+		var r = httptest.NewRequest("GET", target, nil)
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "lang", Value: cookie})
+		}
+		if accept != "" {
+			r.Header.Set("Accept-Language", accept)
+		}
+		var w = httptest.NewRecorder()
+		Middleware(b, opts)(handler).ServeHTTP(w, r)
+		return w
+	}
+	for _, c := range []struct {
+		opts                   MiddlewareOptions
+		target, cookie, accept string
+		expected               string
+	}{
+		{MiddlewareOptions{}, "/?lang=sk", "de", "en", "sk: Otvoriť"},
+		{MiddlewareOptions{}, "/?lang=fr", "de-AT", "en", "de: Öffnen"},
+		{MiddlewareOptions{}, "/", "", "fr, de-CH;q=0.5", "de: Öffnen"},
+		{MiddlewareOptions{}, "/", "sk,en;q=2", "", "sk: Otvoriť"},
+		{MiddlewareOptions{}, "/", "", "fr", ""},
+		{MiddlewareOptions{Order: []Source{FromHeader, FromQuery}}, "/?lang=sk", "", "en", "en: Open"},
+		{MiddlewareOptions{Order: []Source{FromCookie}, Cookie: "lang"}, "/?lang=sk", "de", "en", "de: Öffnen"},
+		{MiddlewareOptions{Query: "locale"}, "/?locale=sk&lang=de", "", "", "sk: Otvoriť"},
+	} {
+		if actual := serve(c.opts, c.target, c.cookie, c.accept).Body.String(); actual != c.expected {
+			t.Errorf("%s %q %q: expected %q, got %q", c.target, c.cookie, c.accept, c.expected, actual)
+		}
+	}
+
+	var w = serve(MiddlewareOptions{Headers: true}, "/", "", "de")
+	if lang := w.Header().Get("Content-Language"); lang != "de" {
+		t.Errorf("unexpected Content-Language %q", lang)
+	}
+	if vary := w.Header().Values("Vary"); strings.Join(vary, ", ") != "Accept-Language, Cookie" {
+		t.Errorf("unexpected Vary %q", vary)
+	}
+	// The responses for no locale depend on the same headers.
+	w = serve(MiddlewareOptions{Headers: true}, "/", "", "fr")
+	if lang := w.Header().Get("Content-Language"); lang != "" {
+		t.Errorf("unexpected Content-Language %q", lang)
+	}
+	if vary := w.Header().Values("Vary"); strings.Join(vary, ", ") != "Accept-Language, Cookie" {
+		t.Errorf("unexpected Vary %q", vary)
+	}
+
+	var buf bytes.Buffer
+	var logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	serve(MiddlewareOptions{Logger: logger}, "/?lang=sk", "", "de")
+	for _, expected := range []string{"level=DEBUG", `msg="gettext: locale selected"`, "locale=sk", "tags=\"[sk de]\""} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %s in %q", expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package po
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Attachment links a message to visual context for translators, and is
+// stored in an extracted comment, e.g. "#. screenshot: https://...", or
+// read from an extension comment, e.g. "#% screenshot=https://...".
+type Attachment struct {
+	Kind string // one of AttachmentKinds
+	URL  string
+}
+
+// AttachmentKinds lists the kinds of attachments recognized in extracted comments.
+var AttachmentKinds = []string{"screenshot", "design", "context"}
+
+func (a Attachment) String() string {
+	return a.Kind + ": " + a.URL
+}
+
+// Attachments returns the attachments recorded in the extracted comments,
+// then in the extension comments.
+func (c Comment) Attachments() []Attachment {
+	var r []Attachment
+	for _, line := range c.ExtractedComments {
+		if a, ok := parseAttachment(line); ok {
+			r = append(r, a)
+		}
+	}
+	for _, ext := range c.Extensions {
+		if a, ok := extensionAttachment(ext); ok {
+			r = append(r, a)
+		}
+	}
+	return r
+}
+
+// AddAttachment records the attachment in the extracted comments, unless
+// already present.
+func (c *Comment) AddAttachment(a Attachment) {
+	for _, existing := range c.Attachments() {
+		if existing == a {
+			return
+		}
+	}
+	c.ExtractedComments = append(c.ExtractedComments, a.String())
+}
+
+// RemoveAttachments removes the attachments of the given kind from the
+// extracted and extension comments, or all attachments if kind is empty.
+func (c *Comment) RemoveAttachments(kind string) {
+	var kept []string
+	for _, line := range c.ExtractedComments {
+		if a, ok := parseAttachment(line); ok && (kind == "" || a.Kind == kind) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	c.ExtractedComments = kept
+	var exts []Extension
+	for _, ext := range c.Extensions {
+		if a, ok := extensionAttachment(ext); ok && (kind == "" || a.Kind == kind) {
+			continue
+		}
+		exts = append(exts, ext)
+	}
+	c.Extensions = exts
+}
+
+// extensionAttachment returns the attachment of an extension comment named
+// after its kind, if its value is a URL.
+func extensionAttachment(ext Extension) (Attachment, bool) {
+	var u, _ = ext.Value.(string)
+	return parseAttachment(ext.Name + ": " + u)
+}
+
+func parseAttachment(line string) (Attachment, bool) {
+	var i = strings.Index(line, ": ")
+	if i == -1 || !contains(AttachmentKinds, line[:i]) {
+		return Attachment{}, false
+	}
+	var a = Attachment{line[:i], strings.TrimSpace(line[i+2:])}
+	if u, err := url.Parse(a.URL); err != nil || u.Scheme == "" {
+		return Attachment{}, false
+	}
+	return a, true
+}
@@ -0,0 +1,62 @@
+package po
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAttachments(t *testing.T) {
+	var c = Comment{ExtractedComments: []string{
+		"Shown on the checkout page",
+		"screenshot: https://example.com/checkout.png",
+		"design: not a link",
+	}}
+	c.AddAttachment(Attachment{"design", "https://figma.example.com/file/1"})
+	c.AddAttachment(Attachment{"screenshot", "https://example.com/checkout.png"})
+
+	var expected = []Attachment{
+		{"screenshot", "https://example.com/checkout.png"},
+		{"design", "https://figma.example.com/file/1"},
+	}
+	if actual := c.Attachments(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	c.RemoveAttachments("screenshot")
+	var lines = []string{
+		"Shown on the checkout page",
+		"design: not a link",
+		"design: https://figma.example.com/file/1",
+	}
+	if !reflect.DeepEqual(lines, c.ExtractedComments) {
+		t.Errorf("expected %v, got %v", lines, c.ExtractedComments)
+	}
+}
+
+func TestAttachmentExtensions(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`#. design: https://figma.example.com/file/1
+#% screenshot=https://example.com/login.png
+#% context=not a link
+msgid "Log in"
+msgstr "Prihlásiť"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg = f.Messages[0]
+	var expected = []Attachment{
+		{"design", "https://figma.example.com/file/1"},
+		{"screenshot", "https://example.com/login.png"},
+	}
+	if actual := msg.Attachments(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	if len(f.Warnings) != 1 {
+		t.Errorf("expected a warning for the invalid context, got %v", f.Warnings)
+	}
+	msg.RemoveAttachments("screenshot")
+	if _, found := msg.Extension("screenshot"); found || len(msg.Attachments()) != 1 {
+		t.Errorf("expected the screenshot to be removed, got %v", msg.Extensions)
+	}
+}
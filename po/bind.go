@@ -0,0 +1,43 @@
+package po
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Bind sets the string fields of the struct v points to, tagged with their
+// msgid, e.g. `po:"Open"`, to their translations, so that applications refer
+// to translations as typed fields rather than by strings. The msgctxt, if
+// any, is given by a msgctxt tag, e.g. `po:"Open" msgctxt:"menu"`. Untagged
+// structs are bound recursively, and other untagged fields left as they are.
+// Untranslated messages are set to their msgid.
+func (f *File) Bind(v interface{}) error {
+	var rv = reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("po: Bind of %T, not a pointer to a struct", v)
+	}
+	return f.bind(rv.Elem())
+}
+
+func (f *File) bind(v reflect.Value) error {
+	var t = v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		var field = t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		var id, tagged = field.Tag.Lookup("po")
+		switch {
+		case !tagged && field.Type.Kind() == reflect.Struct:
+			if err := f.bind(v.Field(i)); err != nil {
+				return err
+			}
+		case !tagged:
+		case field.Type.Kind() != reflect.String:
+			return fmt.Errorf("po: Bind of field %s.%s of type %s, not a string", t, field.Name, field.Type)
+		default:
+			v.Field(i).SetString(f.PGetText(field.Tag.Get("msgctxt"), id))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package po
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid "Open"
+msgstr "Otvoriť"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Otvoriť…"
+
+msgid "Save %s"
+msgstr "Uložiť %s"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var strs struct {
+		Open     string `po:"Open"`
+		MenuOpen string `po:"Open" msgctxt:"menu"`
+		Dialog   struct {
+			Save string `po:"Save %s"`
+			Quit string `po:"Quit"`
+		}
+		Count int
+	}
+	if err := f.Bind(&strs); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range [][2]string{
+		{strs.Open, "Otvoriť"},
+		{strs.MenuOpen, "Otvoriť…"},
+		{strs.Dialog.Save, "Uložiť %s"},
+		{strs.Dialog.Quit, "Quit"},
+	} {
+		if actual, expected := test[0], test[1]; actual != expected {
+			t.Errorf("expected %q, got %q", expected, actual)
+		}
+	}
+	if err := f.Bind(strs); err == nil {
+		t.Error("expected an error binding a struct")
+	}
+	var bad struct {
+		N int `po:"Open"`
+	}
+	if err := f.Bind(&bad); err == nil {
+		t.Error("expected an error binding an int")
+	}
+}
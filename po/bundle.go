@@ -0,0 +1,277 @@
+package po
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bundle holds the catalogs of an application, keyed by locale.
+// It is safe for concurrent use.
+type Bundle struct {
+	mu      sync.RWMutex
+	files   map[string]*File
+	domains map[string]map[string]*File // by locale and domain
+	logger  *slog.Logger
+	updated map[string]time.Time // by locale
+	misses  *missLog
+
+	defaultLocale string
+	fallbacks     map[string][]string // by locale
+	chains        map[string][]string // fallback chains, by locale looked up
+}
+
+// maxChains bounds the number of fallback chains cached, as the locales
+// looked up may come from requests.
+const maxChains = 1024
+
+// NewBundle returns an empty bundle.
+func NewBundle() *Bundle {
+	return &Bundle{
+		files:   make(map[string]*File),
+		domains: make(map[string]map[string]*File),
+		updated: make(map[string]time.Time),
+	}
+}
+
+// SetLogger makes the bundle log the catalogs added to it to l, or nothing
+// if l is nil.
+func (b *Bundle) SetLogger(l *slog.Logger) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.logger = l
+}
+
+// Add sets the catalog of the given locale, replacing its domains.
+func (b *Bundle) Add(locale string, f *File) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.files[locale] = f
+	delete(b.domains, locale)
+	b.updated[locale] = time.Now()
+	b.misses.track(locale, f)
+	b.log("po: catalog added", slog.String("locale", locale), slog.Int("messages", len(f.Messages)))
+}
+
+// AddDomain sets the catalog of a domain of the given locale, e.g. that of
+// a package, and composes the catalog of the locale from its domains.
+//
+// Messages present in several domains are looked up in the first of them,
+// in the order of their names, whatever the order they were added in. The
+// header is that of the first domain.
+func (b *Bundle) AddDomain(locale, domain string, f *File) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.domains[locale] == nil {
+		b.domains[locale] = make(map[string]*File)
+	}
+	b.domains[locale][domain] = f
+	b.files[locale] = compose(b.domains[locale])
+	b.updated[locale] = time.Now()
+	b.misses.track(locale, b.files[locale])
+	b.log("po: domain added", slog.String("locale", locale), slog.String("domain", domain),
+		slog.Int("messages", len(f.Messages)), slog.Int("locale_messages", len(b.files[locale].Messages)))
+}
+
+// log records an event, if the bundle has a logger.
+func (b *Bundle) log(msg string, attrs ...slog.Attr) {
+	if b.logger != nil {
+		b.logger.LogAttrs(context.Background(), slog.LevelInfo, msg, attrs...)
+	}
+}
+
+// Domains returns the domains of the given locale, sorted.
+func (b *Bundle) Domains(locale string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return sortedDomains(b.domains[locale])
+}
+
+func sortedDomains(domains map[string]*File) []string {
+	var r = make([]string, 0, len(domains))
+	for domain := range domains {
+		r = append(r, domain)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// compose returns a catalog with the messages of the domains, in order,
+// without those overridden by a previous domain.
+func compose(domains map[string]*File) *File {
+	var r = &File{}
+	var seen = make(map[key]bool)
+	for i, domain := range sortedDomains(domains) {
+		var f = domains[domain]
+		if i == 0 {
+			r.Header, r.Pluralize, r.headerOrder = f.Header, f.Pluralize, f.headerOrder
+		}
+		for _, msg := range f.Messages {
+			if k := (key{msg.Ctxt, msg.Id}); !msg.Obsolete && !seen[k] {
+				seen[k] = true
+				r.Messages = append(r.Messages, msg)
+			}
+		}
+	}
+	r.reindex()
+	return r
+}
+
+// File returns the catalog of the given locale, or nil.
+func (b *Bundle) File(locale string) *File {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.files[locale]
+}
+
+// Locales returns the locales of the bundle, sorted.
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var r = make([]string, 0, len(b.files))
+	for locale := range b.files {
+		r = append(r, locale)
+	}
+	sort.Strings(r)
+	return r
+}
+
+// CompatibilityError reports a catalog generated for another version of the
+// application than the one using it.
+type CompatibilityError struct {
+	Locale  string
+	Version string // version of the catalog, empty if undeclared
+	Want    string // version of the application
+}
+
+func (e *CompatibilityError) Error() string {
+	if e.Version == "" {
+		return fmt.Sprintf("po: catalog %s declares no %s, want %s", e.Locale, SchemaHeader, e.Want)
+	}
+	return fmt.Sprintf("po: catalog %s has %s %s, want %s", e.Locale, SchemaHeader, e.Version, e.Want)
+}
+
+// CheckCompatibility checks that every catalog of the bundle declares the
+// given schema version, so that deployments can refuse or warn about
+// catalogs with stale msgids. The returned error joins a
+// *CompatibilityError per incompatible catalog.
+func (b *Bundle) CheckCompatibility(version string) error {
+	var errs []error
+	for _, locale := range b.Locales() {
+		if v := b.File(locale).SchemaVersion(); v != version {
+			errs = append(errs, &CompatibilityError{locale, v, version})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetDefault sets the locale every fallback chain ends with, e.g. that of
+// the source strings, or none if empty.
+func (b *Bundle) SetDefault(locale string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.defaultLocale = locale
+	b.chains = nil
+}
+
+// SetFallbacks sets the locales looked up, in order, for the messages
+// missing from the catalog of the given locale, instead of its parents.
+func (b *Bundle) SetFallbacks(locale string, fallbacks ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.fallbacks == nil {
+		b.fallbacks = make(map[string][]string)
+	}
+	b.fallbacks[locale] = fallbacks
+	b.chains = nil
+}
+
+// Fallbacks returns the locales whose catalogs are searched, in order, for
+// the messages of the given locale: the locale itself, its fallbacks or its
+// parents ("pt_BR", then "pt"), and the default locale.
+func (b *Bundle) Fallbacks(locale string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.fallbacksLocked(locale)
+}
+
+func (b *Bundle) fallbacksLocked(locale string) []string {
+	locale = strings.Replace(locale, "-", "_", -1)
+	var chain = []string{locale}
+	if fallbacks, found := b.fallbacks[locale]; found {
+		chain = append(chain, fallbacks...)
+	} else {
+		for i := strings.LastIndexAny(locale, "_@"); i > 0; i = strings.LastIndexAny(locale, "_@") {
+			locale = locale[:i]
+			chain = append(chain, locale)
+		}
+	}
+	if b.defaultLocale != "" && !contains(chain, b.defaultLocale) {
+		chain = append(chain, b.defaultLocale)
+	}
+	return chain
+}
+
+// chain returns the fallback chain of the locale, cached so that lookups do
+// not allocate. It must not be modified.
+func (b *Bundle) chain(locale string) []string {
+	b.mu.RLock()
+	var chain, found = b.chains[locale]
+	b.mu.RUnlock()
+	if found {
+		return chain
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	chain = b.fallbacksLocked(locale)
+	if b.chains == nil {
+		b.chains = make(map[string][]string)
+	}
+	if len(b.chains) < maxChains {
+		b.chains[locale] = chain
+	}
+	return chain
+}
+
+// GetText translates id with the first catalog of the fallback chain of the
+// locale that has it translated.
+func (b *Bundle) GetText(locale, id string, data ...interface{}) string {
+	return b.NPGetText(locale, "", id, "", 1, data...)
+}
+
+// PGetText is like GetText, for the message in the given context.
+func (b *Bundle) PGetText(locale, ctxt, id string, data ...interface{}) string {
+	return b.NPGetText(locale, ctxt, id, "", 1, data...)
+}
+
+// NGetText is like GetText, for the plural form of the quantity n.
+func (b *Bundle) NGetText(locale, id, idPlural string, n int, data ...interface{}) string {
+	return b.NPGetText(locale, "", id, idPlural, n, data...)
+}
+
+// NPGetText is like NGetText, for the message in the given context.
+func (b *Bundle) NPGetText(locale, ctxt, id, idPlural string, n int, data ...interface{}) string {
+	for _, l := range b.chain(locale) {
+		var f = b.File(l)
+		if f == nil {
+			continue
+		}
+		var i = 0
+		if idPlural != "" {
+			i = f.pluralize(n)
+		}
+		if e := f.getByIds(ctxt, id, i); e.translated(i) {
+			return e.format(i, "", data)
+		}
+	}
+	var str = id
+	if idPlural != "" && n != 1 {
+		str = idPlural
+	}
+	return (*entry)(nil).format(0, str, data)
+}
@@ -0,0 +1,192 @@
+package po
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	var b = NewBundle()
+	var de, fr, sk = &File{}, &File{}, &File{}
+	de.SetSchemaVersion("42")
+	fr.SetSchemaVersion("41")
+	b.Add("de", de)
+	b.Add("fr", fr)
+	b.Add("sk", sk)
+
+	var err = b.CheckCompatibility("42")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	var compat *CompatibilityError
+	if !errors.As(err, &compat) || compat.Locale != "fr" || compat.Version != "41" {
+		t.Errorf("unexpected error %v", err)
+	}
+	var expected = "po: catalog fr has X-Catalog-Schema 41, want 42\npo: catalog sk declares no X-Catalog-Schema, want 42"
+	if err.Error() != expected {
+		t.Errorf("expected %q, got %q", expected, err.Error())
+	}
+
+	fr.SetSchemaVersion("42")
+	sk.SetSchemaVersion("42")
+	if err = b.CheckCompatibility("42"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestAddDomain(t *testing.T) {
+	var b = NewBundle()
+	var billing = &File{Messages: []*Message{
+		{Id: "Invoice", Str: []string{"Faktúra"}},
+		{Id: "Cancel", Str: []string{"Stornovať"}},
+	}}
+	var app = &File{Messages: []*Message{
+		{Id: "Cancel", Str: []string{"Zrušiť"}},
+	}}
+	b.AddDomain("sk", "billing", billing)
+	b.AddDomain("sk", "app", app)
+
+	var f = b.File("sk")
+	if f.GetText("Invoice") != "Faktúra" || f.GetText("Cancel") != "Zrušiť" {
+		t.Errorf("unexpected lookups %q, %q", f.GetText("Invoice"), f.GetText("Cancel"))
+	}
+	if domains := b.Domains("sk"); len(domains) != 2 || domains[0] != "app" {
+		t.Errorf("unexpected domains %v", domains)
+	}
+
+	billing.reindex()
+	b.Add("sk", billing)
+	if b.File("sk").GetText("Cancel") != "Stornovať" || len(b.Domains("sk")) != 0 {
+		t.Error("expected Add to replace the domains")
+	}
+}
+
+func TestBundleLogger(t *testing.T) {
+	var buf bytes.Buffer
+	var b = NewBundle()
+	b.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+	b.Add("sk", &File{Messages: []*Message{{Id: "Open"}}})
+	b.AddDomain("de", "billing", &File{})
+	var expected = "level=INFO msg=\"po: catalog added\" locale=sk messages=1\n" +
+		"level=INFO msg=\"po: domain added\" locale=de domain=billing messages=0 locale_messages=0\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	var b = NewBundle()
+	b.SetDefault("en")
+	b.SetFallbacks("gl", "es", "pt")
+	var tests = []struct {
+		locale   string
+		expected []string
+	}{
+		{"pt-BR", []string{"pt_BR", "pt", "en"}},
+		{"sr_RS@latin", []string{"sr_RS@latin", "sr_RS", "sr", "en"}},
+		{"en_GB", []string{"en_GB", "en"}},
+		{"gl", []string{"gl", "es", "pt", "en"}},
+	}
+	for _, test := range tests {
+		if actual := b.Fallbacks(test.locale); !reflect.DeepEqual(test.expected, actual) {
+			t.Errorf("%s: expected %v, got %v", test.locale, test.expected, actual)
+		}
+	}
+}
+
+func TestBundleGetText(t *testing.T) {
+	var parse = func(src string) *File {
+		f, err := Parse(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	var b = NewBundle()
+	b.Add("pt", parse(`
+msgid ""
+msgstr ""
+"Language: pt\n"
+
+msgid "Bus"
+msgstr "Autocarro"
+
+msgid "Open"
+msgstr "Abrir"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d ficheiro"
+msgstr[1] "%d ficheiros"
+`))
+	b.Add("pt_BR", parse(`
+msgid ""
+msgstr ""
+"Language: pt_BR\n"
+
+msgid "Bus"
+msgstr "Ônibus"
+
+msgid "Open"
+msgstr ""
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d arquivo"
+msgstr[1] ""
+`))
+	var tests = []struct {
+		actual, expected string
+	}{
+		{b.GetText("pt-BR", "Bus"), "Ônibus"},
+		{b.GetText("pt_BR", "Open"), "Abrir"},
+		{b.GetText("pt_BR", "Save"), "Save"},
+		{b.GetText("fr", "Bus"), "Bus"},
+		{b.NGetText("pt_BR", "%d file", "%d files", 1, 1), "1 arquivo"},
+		{b.NGetText("pt_BR", "%d file", "%d files", 2, 2), "2 ficheiros"},
+		{b.NGetText("fr", "%d file", "%d files", 2, 2), "2 files"},
+	}
+	for i, test := range tests {
+		if test.actual != test.expected {
+			t.Errorf("%d: expected %q, got %q", i, test.expected, test.actual)
+		}
+	}
+}
+
+func TestBundleFallbackCache(t *testing.T) {
+	var b = NewBundle()
+	b.Add("es", &File{Messages: []*Message{{Id: "Open", Str: []string{"Abrir"}}}})
+	b.Add("pt", &File{Messages: []*Message{{Id: "Open", Str: []string{"Abrir (pt)"}}}})
+	b.SetFallbacks("gl", "es")
+	if actual := b.GetText("gl", "Open"); actual != "Abrir" {
+		t.Errorf("expected %q, got %q", "Abrir", actual)
+	}
+	b.SetFallbacks("gl", "pt")
+	if actual := b.GetText("gl", "Open"); actual != "Abrir (pt)" {
+		t.Errorf("expected the changed fallbacks, got %q", actual)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { b.GetText("gl-ES", "Open") }); allocs != 0 {
+		t.Errorf("expected lookups not to allocate, got %v allocations", allocs)
+	}
+}
+
+func BenchmarkBundleGetText(b *testing.B) {
+	var bundle = NewBundle()
+	bundle.Add("pt", &File{Messages: []*Message{{Id: "Open", Str: []string{"Abrir"}}}})
+	bundle.SetDefault("en")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bundle.GetText("pt-BR", "Open")
+	}
+}
@@ -0,0 +1,129 @@
+package po
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// charsetRe matches the charset declared by the Content-Type header.
+var charsetRe = regexp.MustCompile(`"Content-Type:[^"]*charset=([^\s"\\;]+)`)
+
+// charsetPeek is the length of the start of a file searched for its charset.
+const charsetPeek = 8 << 10
+
+// isUTF8Charset returns true if text in the charset needs no conversion to
+// UTF-8, including the "CHARSET" placeholder of templates.
+func isUTF8Charset(charset string) bool {
+	switch normalizeCharset(charset) {
+	case "", "utf8", "ascii", "usascii", "charset":
+		return true
+	}
+	return false
+}
+
+// isLatin1Charset returns true for the names of ISO-8859-1.
+func isLatin1Charset(charset string) bool {
+	switch normalizeCharset(charset) {
+	case "iso88591", "latin1", "l1", "iso885911987", "cp819", "ibm819":
+		return true
+	}
+	return false
+}
+
+func normalizeCharset(charset string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(charset))
+}
+
+// decodeCharset returns a reader of r decoded to UTF-8 from the charset
+// declared by its header, and the charset, or "" if r needs no decoding.
+// Charsets other than ISO-8859-1 are decoded by opts.CharsetReader, and left
+// as they are if it is not set.
+func decodeCharset(r io.Reader, opts ParseOptions) (io.Reader, string, error) {
+	var br = bufio.NewReaderSize(r, charsetPeek)
+	var start, _ = br.Peek(charsetPeek)
+	var m = charsetRe.FindSubmatch(start)
+	if m == nil || isUTF8Charset(string(m[1])) {
+		return br, "", nil
+	}
+	var charset = string(m[1])
+	if opts.CharsetReader != nil {
+		var dr, err = opts.CharsetReader(charset, br)
+		if err != nil {
+			return nil, "", fmt.Errorf("po: charset %s: %w", charset, err)
+		}
+		return dr, charset, nil
+	}
+	if isLatin1Charset(charset) {
+		return &latin1Reader{r: br}, charset, nil
+	}
+	return br, "", nil
+}
+
+// encodeCharset returns a writer encoding the UTF-8 written to it in the
+// charset, by opts.CharsetWriter, or as ISO-8859-1.
+func encodeCharset(w io.Writer, charset string, opts WriteOptions) (io.Writer, error) {
+	if opts.CharsetWriter != nil {
+		var ew, err = opts.CharsetWriter(charset, w)
+		if err != nil {
+			return nil, fmt.Errorf("po: charset %s: %w", charset, err)
+		}
+		return ew, nil
+	}
+	if isLatin1Charset(charset) {
+		return &latin1Writer{w: w}, nil
+	}
+	return nil, fmt.Errorf("po: charset %s: no CharsetWriter to encode the file", charset)
+}
+
+// latin1Reader decodes ISO-8859-1 to UTF-8.
+type latin1Reader struct {
+	r   io.Reader
+	buf []byte // decoded bytes not read yet
+}
+
+func (r *latin1Reader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		var in = make([]byte, (len(p)+1)/2)
+		var n, err = r.r.Read(in)
+		for _, b := range in[:n] {
+			r.buf = utf8.AppendRune(r.buf, rune(b))
+		}
+		if n == 0 {
+			return 0, err
+		}
+	}
+	var n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// latin1Writer encodes UTF-8 in ISO-8859-1.
+type latin1Writer struct {
+	w       io.Writer
+	partial []byte // start of a rune split by the last write
+}
+
+func (w *latin1Writer) Write(p []byte) (int, error) {
+	var in = append(w.partial, p...)
+	var out = make([]byte, 0, len(in))
+	for len(in) > 0 {
+		if !utf8.FullRune(in) {
+			break
+		}
+		var c, size = utf8.DecodeRune(in)
+		if c > 0xff || c == utf8.RuneError && size == 1 {
+			return 0, fmt.Errorf("po: %q cannot be encoded in ISO-8859-1", in[:size])
+		}
+		out = append(out, byte(c))
+		in = in[size:]
+	}
+	w.partial = append(w.partial[:0], in...)
+	if _, err := w.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
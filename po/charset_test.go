@@ -0,0 +1,89 @@
+package po
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParseLatin1(t *testing.T) {
+	var src = "msgid \"\"\nmsgstr \"\"\n\"Content-Type: text/plain; charset=ISO-8859-1\\n\"\n\n" +
+		"#  Fran\xe7ais\nmsgid \"Open\"\nmsgstr \"Ouvrir le fichier \xab\xa0test\xa0\xbb\"\n\n"
+	var f, err = Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := f.GetText("Open"), "Ouvrir le fichier « test »"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if actual, expected := strings.TrimSpace(f.Messages[0].TranslatorComments[0]), "Français"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != src {
+		t.Errorf("expected:\n%q\ngot:\n%q", src, buf.String())
+	}
+	buf.Reset()
+	if err := NewEncoder(&buf, WriteOptions{UTF8Charset: true}).Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "charset=UTF-8") || !strings.Contains(buf.String(), "« test »") {
+		t.Errorf("expected UTF-8 output, got:\n%q", buf.String())
+	}
+
+	f.Set("", "Close", "Fermer ✗")
+	if _, err := f.WriteTo(io.Discard); err == nil {
+		t.Error("expected an error for a character missing from ISO-8859-1")
+	}
+}
+
+func TestCharsetReader(t *testing.T) {
+	var src = "msgid \"\"\nmsgstr \"\"\n\"Content-Type: text/plain; charset=windows-1252\\n\"\n\nmsgid \"Open\"\nmsgstr \"Ouvrir \xe0\"\n\n"
+	var decoded, encoded []string
+	var f, err = ParseWithOptions(strings.NewReader(src), ParseOptions{
+		CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+			decoded = append(decoded, charset)
+			return &latin1Reader{r: input}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := f.GetText("Open"), "Ouvrir à"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if _, err := f.WriteTo(io.Discard); err == nil {
+		t.Error("expected an error without a CharsetWriter")
+	}
+	var buf bytes.Buffer
+	err = NewEncoder(&buf, WriteOptions{
+		CharsetWriter: func(charset string, output io.Writer) (io.Writer, error) {
+			encoded = append(encoded, charset)
+			return &latin1Writer{w: output}, nil
+		},
+	}).Encode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != src {
+		t.Errorf("expected:\n%q\ngot:\n%q", src, buf.String())
+	}
+	if len(decoded) != 1 || decoded[0] != "windows-1252" || len(encoded) != 1 || encoded[0] != "windows-1252" {
+		t.Errorf("unexpected charsets %v and %v", decoded, encoded)
+	}
+
+	var unsupported = errors.New("unsupported")
+	_, err = ParseWithOptions(strings.NewReader(src), ParseOptions{
+		CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+			return nil, unsupported
+		},
+	})
+	if !errors.Is(err, unsupported) {
+		t.Errorf("expected %v, got %v", unsupported, err)
+	}
+}
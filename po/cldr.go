@@ -0,0 +1,316 @@
+package po
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PluralCategory is a CLDR plural category.
+type PluralCategory string
+
+// The CLDR plural categories.
+const (
+	Zero  PluralCategory = "zero"
+	One   PluralCategory = "one"
+	Two   PluralCategory = "two"
+	Few   PluralCategory = "few"
+	Many  PluralCategory = "many"
+	Other PluralCategory = "other"
+)
+
+// PluralRule classifies quantities, including fractional ones, by the plural
+// rules of a language.
+type PluralRule interface {
+	// Category returns the CLDR plural category of n.
+	Category(n float64) PluralCategory
+	// Index returns the plural form of n, as indexes msgstr. Integers have
+	// the form selected by the Plural-Forms of the language, and fractions
+	// the form used for their category.
+	Index(n float64) int
+}
+
+// operands are the CLDR plural operands of a number: its integer digits i,
+// the number v of its visible fraction digits, and the fraction digits f.
+// Numbers are written in the shortest form, so that 1.50 has v=1 and f=5.
+type operands struct {
+	n       float64
+	i, v, f int64
+}
+
+func newOperands(n float64) operands {
+	var o = operands{n: math.Abs(n)}
+	var s = strconv.FormatFloat(o.n, 'f', -1, 64)
+	var frac string
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		s, frac = s[:dot], s[dot+1:]
+	}
+	o.i, _ = strconv.ParseInt(s, 10, 64)
+	o.v = int64(len(frac))
+	o.f, _ = strconv.ParseInt("0"+frac, 10, 64)
+	return o
+}
+
+// in returns true if x is between the bounds, inclusive.
+func in(x, min, max int64) bool {
+	return min <= x && x <= max
+}
+
+// cldrRule is the plural rule of a language, with the plural form used for
+// each of its categories of fractions.
+type cldrRule struct {
+	selector PluralSelector
+	category func(o operands) PluralCategory
+	forms    map[PluralCategory]int
+}
+
+func (r *cldrRule) Category(n float64) PluralCategory {
+	return r.category(newOperands(n))
+}
+
+func (r *cldrRule) Index(n float64) int {
+	if n == math.Trunc(n) && math.Abs(n) <= 1<<53 {
+		return r.selector(int(n))
+	}
+	if form, found := r.forms[r.Category(n)]; found {
+		return form
+	}
+	return r.forms[Other]
+}
+
+func categoryOther(o operands) PluralCategory {
+	return Other
+}
+
+func categoryOne(o operands) PluralCategory {
+	if o.n == 1 {
+		return One
+	}
+	return Other
+}
+
+func categoryDanish(o operands) PluralCategory {
+	if o.n == 1 || o.f != 0 && in(o.i, 0, 1) {
+		return One
+	}
+	return Other
+}
+
+func categoryFrench(o operands) PluralCategory {
+	if in(o.i, 0, 1) {
+		return One
+	}
+	return Other
+}
+
+func categoryHebrew(o operands) PluralCategory {
+	switch {
+	case o.i == 1 && o.v == 0:
+		return One
+	case o.i == 2 && o.v == 0:
+		return Two
+	}
+	return Other
+}
+
+func categoryLatvian(o operands) PluralCategory {
+	var n10, n100 = o.i % 10, o.i % 100
+	var f10, f100 = o.f % 10, o.f % 100
+	switch {
+	case o.v == 0 && (n10 == 0 || in(n100, 11, 19)) || o.v == 2 && in(f100, 11, 19):
+		return Zero
+	case o.v == 0 && n10 == 1 && n100 != 11 || o.v == 2 && f10 == 1 && f100 != 11 || o.v != 2 && f10 == 1:
+		return One
+	}
+	return Other
+}
+
+func categoryIrish(o operands) PluralCategory {
+	switch {
+	case o.n == 1:
+		return One
+	case o.n == 2:
+		return Two
+	case o.v == 0 && in(o.i, 3, 6):
+		return Few
+	case o.v == 0 && in(o.i, 7, 10):
+		return Many
+	}
+	return Other
+}
+
+func categoryRomanian(o operands) PluralCategory {
+	switch {
+	case o.i == 1 && o.v == 0:
+		return One
+	case o.v != 0 || o.n == 0 || in(o.i%100, 2, 19):
+		return Few
+	}
+	return Other
+}
+
+func categoryLithuanian(o operands) PluralCategory {
+	var n10, n100 = o.i % 10, o.i % 100
+	switch {
+	case o.f != 0:
+		return Many
+	case n10 == 1 && !in(n100, 11, 19):
+		return One
+	case in(n10, 2, 9) && !in(n100, 11, 19):
+		return Few
+	}
+	return Other
+}
+
+func categoryRussian(o operands) PluralCategory {
+	var i10, i100 = o.i % 10, o.i % 100
+	switch {
+	case o.v != 0:
+		return Other
+	case i10 == 1 && i100 != 11:
+		return One
+	case in(i10, 2, 4) && !in(i100, 12, 14):
+		return Few
+	}
+	return Many
+}
+
+func categorySerbian(o operands) PluralCategory {
+	var i10, i100 = o.i % 10, o.i % 100
+	var f10, f100 = o.f % 10, o.f % 100
+	switch {
+	case o.v == 0 && i10 == 1 && i100 != 11 || f10 == 1 && f100 != 11:
+		return One
+	case o.v == 0 && in(i10, 2, 4) && !in(i100, 12, 14) || in(f10, 2, 4) && !in(f100, 12, 14):
+		return Few
+	}
+	return Other
+}
+
+func categoryCzech(o operands) PluralCategory {
+	switch {
+	case o.v != 0:
+		return Many
+	case o.i == 1:
+		return One
+	case in(o.i, 2, 4):
+		return Few
+	}
+	return Other
+}
+
+func categoryPolish(o operands) PluralCategory {
+	var i10, i100 = o.i % 10, o.i % 100
+	switch {
+	case o.v != 0:
+		return Other
+	case o.i == 1:
+		return One
+	case in(i10, 2, 4) && !in(i100, 12, 14):
+		return Few
+	}
+	return Many
+}
+
+func categorySlovenian(o operands) PluralCategory {
+	switch {
+	case o.v != 0:
+		return Few
+	case o.i%100 == 1:
+		return One
+	case o.i%100 == 2:
+		return Two
+	case in(o.i%100, 3, 4):
+		return Few
+	}
+	return Other
+}
+
+// cldrCategories maps the languages of pluralExprs to their CLDR plural
+// categories, and the plural forms of their fractions.
+var cldrCategories = map[string]struct {
+	category func(o operands) PluralCategory
+	forms    map[PluralCategory]int
+}{
+	"ja":    {categoryOther, map[PluralCategory]int{Other: 0}},
+	"vi":    {categoryOther, map[PluralCategory]int{Other: 0}},
+	"ko":    {categoryOther, map[PluralCategory]int{Other: 0}},
+	"zh":    {categoryOther, map[PluralCategory]int{Other: 0}},
+	"en":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"de":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"nl":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"sv":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"da":    {categoryDanish, map[PluralCategory]int{One: 0, Other: 1}},
+	"no":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"nb":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"nn":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"fo":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"es":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"pt":    {categoryFrench, map[PluralCategory]int{One: 0, Other: 1}},
+	"it":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"bg":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"el":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"fi":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"et":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"he":    {categoryHebrew, map[PluralCategory]int{One: 0, Two: 1, Other: 1}},
+	"eo":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"hu":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"tr":    {categoryOne, map[PluralCategory]int{One: 0, Other: 1}},
+	"pt_BR": {categoryFrench, map[PluralCategory]int{One: 0, Other: 1}},
+	"fr":    {categoryFrench, map[PluralCategory]int{One: 0, Other: 1}},
+	"lv":    {categoryLatvian, map[PluralCategory]int{One: 0, Zero: 1, Other: 1}},
+	"ga":    {categoryIrish, map[PluralCategory]int{One: 0, Two: 1, Other: 2}},
+	"ro":    {categoryRomanian, map[PluralCategory]int{One: 0, Few: 1, Other: 2}},
+	"lt":    {categoryLithuanian, map[PluralCategory]int{One: 0, Few: 1, Other: 2}},
+	"ru":    {categoryRussian, map[PluralCategory]int{One: 0, Few: 1, Many: 2, Other: 1}},
+	"uk":    {categoryRussian, map[PluralCategory]int{One: 0, Few: 1, Many: 2, Other: 1}},
+	"be":    {categoryRussian, map[PluralCategory]int{One: 0, Few: 1, Many: 2, Other: 1}},
+	"sr":    {categorySerbian, map[PluralCategory]int{One: 0, Few: 1, Other: 2}},
+	"hr":    {categorySerbian, map[PluralCategory]int{One: 0, Few: 1, Other: 2}},
+	"cs":    {categoryCzech, map[PluralCategory]int{One: 0, Few: 1, Many: 1, Other: 2}},
+	"sk":    {categoryCzech, map[PluralCategory]int{One: 0, Few: 1, Many: 1, Other: 2}},
+	"pl":    {categoryPolish, map[PluralCategory]int{One: 0, Few: 1, Many: 2, Other: 1}},
+	"sl":    {categorySlovenian, map[PluralCategory]int{One: 0, Two: 1, Few: 2, Other: 3}},
+}
+
+// PluralRuleForLanguage returns the plural rule of the language, like
+// PluralSelectorForLanguage, or nil if unknown.
+func PluralRuleForLanguage(lang string) PluralRule {
+	lang = pluralLanguage(lang)
+	if lang == "" {
+		return nil
+	}
+	var c = cldrCategories[lang]
+	return &cldrRule{lookupPluralSelector(pluralExprs[lang]), c.category, c.forms}
+}
+
+// pluralLanguage returns the language code of the plural rules of lang,
+// e.g. "pt_BR" for "pt-BR", "en" for "en_GB" and "sr" for "sr_RS@latin", or
+// "" if unknown.
+func pluralLanguage(lang string) string {
+	lang = pluralLocale(lang)
+	for lang != "" {
+		if _, found := cldrCategories[lang]; found {
+			return lang
+		}
+		var i = strings.LastIndexByte(lang, '_')
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+	}
+	return ""
+}
+
+// pluralRule returns the plural rule of the language of the file, or nil if
+// unknown or the file declares other Plural-Forms.
+func (f *File) pluralRule() PluralRule {
+	var lang = pluralLanguage(f.Header.Get("Language"))
+	if lang == "" {
+		return nil
+	}
+	if pluralForms := f.Header.Get("Plural-Forms"); pluralForms != "" && strings.Replace(pluralForms, " ", "", -1) != strings.Replace(pluralExprs[lang], " ", "", -1) {
+		return nil
+	}
+	return PluralRuleForLanguage(lang)
+}
@@ -0,0 +1,90 @@
+package po
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPluralRuleCategory(t *testing.T) {
+	var tests = []struct {
+		lang     string
+		n        float64
+		category PluralCategory
+		index    int
+	}{
+		{"en", 1, One, 0},
+		{"en", 0, Other, 1},
+		{"en", 1.5, Other, 1},
+		{"fr", 0, One, 0},
+		{"fr", 1.5, One, 0},
+		{"fr", 2, Other, 1},
+		{"pt-BR", 0.5, One, 0},
+		{"ru", 1, One, 0},
+		{"ru", 3, Few, 1},
+		{"ru", 11, Many, 2},
+		{"ru", 21, One, 0},
+		{"ru", 1.5, Other, 1},
+		{"ru_RU", 5, Many, 2},
+		{"ru_RU.UTF-8", 5, Many, 2},
+		{"sr_RS@latin", 3, Few, 1},
+		{"cs", 1.5, Many, 1},
+		{"cs", 5, Other, 2},
+		{"lv", 0, Zero, 2},
+		{"lv", 10, Zero, 1},
+		{"lv", 0.1, One, 0},
+		{"lv", 2.5, Other, 1},
+		{"sl", 102, Two, 1},
+		{"sl", 0.5, Few, 2},
+		{"ga", 7, Many, 2},
+		{"ja", 1, Other, 0},
+	}
+	for _, test := range tests {
+		var rule = PluralRuleForLanguage(test.lang)
+		if rule == nil {
+			t.Errorf("%s: no rule", test.lang)
+			continue
+		}
+		if actual := rule.Category(test.n); actual != test.category {
+			t.Errorf("%s %v: expected %s, got %s", test.lang, test.n, test.category, actual)
+		}
+		if actual := rule.Index(test.n); actual != test.index {
+			t.Errorf("%s %v: expected form %d, got %d", test.lang, test.n, test.index, actual)
+		}
+	}
+	if PluralRuleForLanguage("tlh") != nil {
+		t.Error("tlh: expected no rule")
+	}
+}
+
+func TestNGetTextFloat(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "%g kilometer"
+msgid_plural "%g kilometers"
+msgstr[0] "%g километр"
+msgstr[1] "%g километра"
+msgstr[2] "%g километров"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		n        float64
+		expected string
+	}{
+		{1, "1 километр"},
+		{3, "3 километра"},
+		{5, "5 километров"},
+		{1.5, "1.5 километра"},
+		{21, "21 километр"},
+	}
+	for _, test := range tests {
+		if actual := f.NGetTextFloat("%g kilometer", "%g kilometers", test.n, test.n); actual != test.expected {
+			t.Errorf("%v: expected %q, got %q", test.n, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package po
+
+import "github.com/olebedev/gettext/lite"
+
+// Compile returns the translated messages of the file, neither obsolete nor
+// untranslated, as a lite catalog. Fuzzy messages are left out, unless
+// UseFuzzy is set.
+func (f *File) Compile() *lite.Catalog {
+	var msgs = make([]lite.Message, 0, len(f.Messages))
+	for _, msg := range f.Messages {
+		if !msg.Obsolete && !msg.isUntranslated() && (f.UseFuzzy || !msg.HasFlag("fuzzy")) {
+			msgs = append(msgs, lite.Message{Ctxt: msg.Ctxt, Id: msg.Id, Str: msg.Str})
+		}
+	}
+	return lite.New(f.Pluralize, msgs)
+}
@@ -0,0 +1,114 @@
+package po
+
+// ConcatOptions controls how catalogs are concatenated.
+type ConcatOptions struct {
+	// UseFirst resolves conflicting translations with the first one, like
+	// msgcat --use-first, without flagging the message fuzzy or reporting it.
+	UseFirst bool
+	// Strict fails on the first conflicting translation with a
+	// *ConflictError, unless UseFirst is set.
+	Strict bool
+}
+
+// Concat concatenates the catalogs, like msgcat, and returns the result,
+// with the header of the first file with one. Messages sharing their context
+// and msgid are unified in the place of the first one, with the comments,
+// references and flags of all of them, and the first translation, preferring
+// those that are not fuzzy. Messages with conflicting translations are
+// flagged fuzzy and reported in the Warnings of the result. Obsolete
+// messages are dropped for the active messages of other files.
+func Concat(files []*File, opts ConcatOptions) (*File, error) {
+	var r = &File{}
+	var byKey = make(map[key]*Message)
+	var conflicts = make(map[*Message]bool)
+	for _, f := range files {
+		f.mu.RLock()
+		if r.Header == nil && len(f.Header) > 0 {
+			r.Header, r.HeaderComment, r.headerOrder = cloneHeader(f.Header), f.HeaderComment, f.headerOrder
+			r.Pluralize = f.Pluralize
+		}
+		if r.Pluralize == nil {
+			r.Pluralize = f.Pluralize
+		}
+		for _, msg := range f.Messages {
+			var k = key{msg.Ctxt, msg.Id}
+			var prev = byKey[k]
+			switch {
+			case prev == nil:
+				var m = copyMessage(msg)
+				byKey[k] = m
+				r.Messages = append(r.Messages, m)
+			case msg.Obsolete && !prev.Obsolete:
+			case prev.Obsolete && !msg.Obsolete:
+				*prev = *copyMessage(msg)
+			case concatMessage(prev, msg) && !opts.UseFirst:
+				if opts.Strict {
+					f.mu.RUnlock()
+					return nil, &ConflictError{msg.Ctxt, msg.Id, prev.Str, msg.Str}
+				}
+				prev.markFuzzy()
+				conflicts[prev] = true
+			}
+		}
+		f.mu.RUnlock()
+	}
+	for _, msg := range r.Messages {
+		if conflicts[msg] {
+			r.Warnings = append(r.Warnings, Problem{"conflict", Warning, msg, 0, "conflicting translations"})
+		}
+	}
+	r.reindex()
+	return r, nil
+}
+
+// copyMessage returns a copy of the message, with its own comments, flags
+// and msgstrs.
+func copyMessage(msg *Message) *Message {
+	var m = *msg
+	m.TranslatorComments = append([]string(nil), msg.TranslatorComments...)
+	m.ExtractedComments = append([]string(nil), msg.ExtractedComments...)
+	m.References = append([]Reference(nil), msg.References...)
+	m.Flags = append([]string(nil), msg.Flags...)
+	m.Str = append([]string(nil), msg.Str...)
+	return &m
+}
+
+// concatMessage unifies msg into the message m with the same context and
+// msgid, returning true if their translations conflict.
+func concatMessage(m, msg *Message) bool {
+	m.TranslatorComments = appendMissing(m.TranslatorComments, msg.TranslatorComments)
+	m.ExtractedComments = appendMissing(m.ExtractedComments, msg.ExtractedComments)
+	m.References = appendMissingReferences(m.References, msg.References)
+	var fuzzy, msgFuzzy = m.HasFlag("fuzzy"), msg.HasFlag("fuzzy")
+	for _, flag := range msg.Flags {
+		if flag != "fuzzy" && !contains(m.Flags, flag) {
+			m.Flags = append(m.Flags, flag)
+		}
+	}
+	switch {
+	case msg.isUntranslated() || sameStrings(m.Str, msg.Str):
+		return false
+	case m.isUntranslated() || fuzzy && !msgFuzzy:
+		m.IdPlural, m.Str, m.StrIndices = msg.IdPlural, append([]string(nil), msg.Str...), msg.StrIndices
+		if fuzzy && !msgFuzzy {
+			m.Flags = removeFlag(m.Flags, "fuzzy")
+		} else if msgFuzzy {
+			m.markFuzzy()
+		}
+		return false
+	case msgFuzzy && !fuzzy:
+		return false
+	}
+	return true
+}
+
+// removeFlag returns the flags without flag.
+func removeFlag(flags []string, flag string) []string {
+	var r = flags[:0]
+	for _, f := range flags {
+		if f != flag {
+			r = append(r, f)
+		}
+	}
+	return r
+}
@@ -0,0 +1,104 @@
+package po
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	var parse = func(src string) *File {
+		var f, err = Parse(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	var a = parse(`
+msgid ""
+msgstr ""
+"Language: sk\n"
+
+#: a.go:1
+msgid "Open"
+msgstr "Otvoriť"
+
+#: a.go:2
+msgid "Close"
+msgstr ""
+
+#, fuzzy
+msgid "Save"
+msgstr "Uložiť?"
+
+#~ msgid "Quit"
+#~ msgstr "Skončiť"
+`[1:])
+	var b = parse(`
+msgid ""
+msgstr ""
+"Language: cs\n"
+
+#. Button
+#: b.go:1
+#, c-format
+msgid "Open"
+msgstr "Otevřít"
+
+#: b.go:2
+msgid "Close"
+msgstr "Zavrieť"
+
+msgid "Save"
+msgstr "Uložiť"
+
+msgid "Quit"
+msgstr "Skončiť"
+`[1:])
+	var f, err = Concat([]*File{a, b}, ConcatOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = `
+msgid ""
+msgstr ""
+"Language: sk\n"
+
+#. Button
+#: a.go:1 b.go:1
+#, fuzzy, c-format
+msgid "Open"
+msgstr "Otvoriť"
+
+#: a.go:2 b.go:2
+msgid "Close"
+msgstr "Zavrieť"
+
+msgid "Save"
+msgstr "Uložiť"
+
+msgid "Quit"
+msgstr "Skončiť"
+
+`[1:]
+	var buf bytes.Buffer
+	f.WriteTo(&buf)
+	if actual := buf.String(); actual != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, actual)
+	}
+	if len(f.Warnings) != 1 || f.Warnings[0].Msg.Id != "Open" || f.Warnings[0].Rule != "conflict" {
+		t.Errorf("unexpected warnings %v", f.Warnings)
+	}
+	if len(a.Messages[0].References) != 1 || a.Messages[2].Str[0] != "Uložiť?" {
+		t.Error("expected the files to be left untouched")
+	}
+
+	if f, err = Concat([]*File{a, b}, ConcatOptions{UseFirst: true}); err != nil || f.Messages[0].HasFlag("fuzzy") || len(f.Warnings) != 0 {
+		t.Errorf("expected the first translation to be used (%v)", err)
+	}
+	var conflict *ConflictError
+	if _, err = Concat([]*File{a, b}, ConcatOptions{Strict: true}); !errors.As(err, &conflict) || conflict.Id != "Open" {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+}
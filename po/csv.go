@@ -0,0 +1,164 @@
+package po
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strings"
+)
+
+// The columns of the spreadsheets of WriteCSV, after which come msgstr[n]
+// for each plural form, then the translator comments and the flags.
+var csvColumns = []string{"msgctxt", "msgid", "msgid_plural"}
+
+// WriteCSV writes the messages of the file, except the obsolete ones, as
+// comma separated values for spreadsheets, one message per row after a row
+// naming the columns: msgctxt, msgid, msgid_plural, msgstr[n] for each plural
+// form, comments, with the translator comments on separate lines, and flags.
+func (f *File) WriteCSV(w io.Writer) error {
+	return f.writeCSV(w, ',')
+}
+
+// WriteTSV is like WriteCSV, with tab separated values.
+func (f *File) WriteTSV(w io.Writer) error {
+	return f.writeCSV(w, '\t')
+}
+
+func (f *File) writeCSV(w io.Writer, comma rune) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var nforms, nplurals = 1, f.nplurals()
+	for _, msg := range f.Messages {
+		if msg.IdPlural != "" && !msg.Obsolete {
+			nforms = max(nforms, nplurals, len(msg.Str))
+		}
+	}
+	var cw = csv.NewWriter(w)
+	cw.Comma = comma
+	var row = append([]string(nil), csvColumns...)
+	for i := 0; i < nforms; i++ {
+		row = append(row, fmt.Sprintf("msgstr[%d]", i))
+	}
+	cw.Write(append(row, "comments", "flags"))
+	for _, msg := range f.Messages {
+		if msg.Obsolete {
+			continue
+		}
+		row = append(row[:0], msg.Ctxt, msg.Id, msg.IdPlural)
+		for i := 0; i < nforms; i++ {
+			var str string
+			if i < len(msg.Str) && (i == 0 || msg.IdPlural != "") {
+				str = msg.Str[i]
+			}
+			row = append(row, str)
+		}
+		cw.Write(append(row, strings.Join(msg.TranslatorComments, "\n"), strings.Join(msg.Flags, ", ")))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ParseCSV reads messages written by WriteCSV, e.g. edited in a spreadsheet,
+// into a file without header. The columns are found by their names in the
+// first row, and may be in any order, or missing except for msgid. They are
+// merged back into the catalog with Update, which leaves the fields of the
+// missing columns alone. A language column, if any, sets the Language and
+// the plural forms, which are otherwise those of English.
+func ParseCSV(r io.Reader) (*File, error) {
+	return parseCSV(r, ',')
+}
+
+// ParseTSV is like ParseCSV, for tab separated values.
+func ParseTSV(r io.Reader) (*File, error) {
+	return parseCSV(r, '\t')
+}
+
+func parseCSV(r io.Reader, comma rune) (*File, error) {
+	var cr = csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	var names, err = cr.Read()
+	if err == io.EOF {
+		return &File{Pluralize: pluralNeq1}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var columns = make(map[string]int)
+	var strs []int // columns of msgstr[n], by n
+	for i, name := range names {
+		var n int
+		if _, err := fmt.Sscanf(name, "msgstr[%d]", &n); err == nil && n >= 0 && n < maxPlurals {
+			for len(strs) <= n {
+				strs = append(strs, -1)
+			}
+			strs[n] = i
+		} else {
+			columns[strings.TrimSpace(name)] = i
+		}
+	}
+	if i, found := columns["msgstr"]; found && len(strs) == 0 {
+		strs = []int{i}
+	}
+	if _, found := columns["msgid"]; !found {
+		return nil, errors.New("po: no msgid column")
+	}
+	var cell = func(row []string, i int) string {
+		if i >= 0 && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	var column = func(row []string, name string) string {
+		if i, found := columns[name]; found {
+			return cell(row, i)
+		}
+		return ""
+	}
+	var f = &File{Pluralize: pluralNeq1}
+	if len(strs) == 0 {
+		f.absent |= strField
+	}
+	if _, found := columns["comments"]; !found {
+		f.absent |= commentsField
+	}
+	if _, found := columns["flags"]; !found {
+		f.absent |= flagsField
+	}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if lang := column(row, "language"); lang != "" && f.Header == nil {
+			f.Header = textproto.MIMEHeader{"Language": {lang}}
+			if pluralize := PluralSelectorForLanguage(lang); pluralize != nil {
+				f.Pluralize = pluralize
+			}
+		}
+		var msg = &Message{Ctxt: column(row, "msgctxt"), Id: column(row, "msgid"), IdPlural: column(row, "msgid_plural")}
+		if msg.IdPlural == "" {
+			msg.Str = []string{cell(row, -1)}
+			if len(strs) > 0 {
+				msg.Str[0] = cell(row, strs[0])
+			}
+		} else {
+			for _, i := range strs {
+				msg.Str = append(msg.Str, cell(row, i))
+			}
+		}
+		if comments := column(row, "comments"); comments != "" {
+			msg.TranslatorComments = strings.Split(comments, "\n")
+		}
+		for _, flag := range strings.Split(column(row, "flags"), ",") {
+			if flag = strings.TrimSpace(flag); flag != "" {
+				msg.Flags = append(msg.Flags, flag)
+			}
+		}
+		f.Messages = append(f.Messages, msg)
+	}
+	return f, nil
+}
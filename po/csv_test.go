@@ -0,0 +1,154 @@
+package po
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Language: sk\n"
+
+# Keep it short.
+msgctxt "menu"
+msgid "Open"
+msgstr "Otvoriť"
+
+#, fuzzy, c-format
+msgid "Say \"%s\",\nthen quit"
+msgstr "Povedz \"%s\",\npotom skonči"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d súbor"
+msgstr[1] "%d súbory"
+msgstr[2] "%d súborov"
+
+#~ msgid "Close"
+#~ msgstr "Zavrieť"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tsv := range []bool{false, true} {
+		var buf bytes.Buffer
+		var write, parse = f.WriteCSV, ParseCSV
+		if tsv {
+			write, parse = f.WriteTSV, ParseTSV
+		}
+		if err = write(&buf); err != nil {
+			t.Fatal(err)
+		}
+		var firstRow = "msgctxt,msgid,msgid_plural,msgstr[0],msgstr[1],msgstr[2],comments,flags\n"
+		if tsv {
+			firstRow = strings.Replace(firstRow, ",", "\t", -1)
+		}
+		if !strings.HasPrefix(buf.String(), firstRow) {
+			t.Errorf("unexpected columns in:\n%s", buf.String())
+		}
+		parsed, err := parse(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parsed.Messages) != 3 {
+			t.Fatalf("expected 3 messages, got %v", parsed.Messages)
+		}
+		for i, msg := range parsed.Messages {
+			var expected = f.Messages[i]
+			if msg.Ctxt != expected.Ctxt || msg.Id != expected.Id || msg.IdPlural != expected.IdPlural ||
+				!reflect.DeepEqual(msg.Str, expected.Str) || !reflect.DeepEqual(msg.TranslatorComments, expected.TranslatorComments) || !reflect.DeepEqual(msg.Flags, expected.Flags) {
+				t.Errorf("expected %v, got %v", expected, msg)
+			}
+		}
+		if n := f.Update(parsed); n != 0 {
+			t.Errorf("expected no changes, got %d", n)
+		}
+	}
+}
+
+func TestParseCSVUpdate(t *testing.T) {
+	var f, _ = Parse(strings.NewReader(po))
+	var edited, err = ParseCSV(strings.NewReader(`
+flags,msgid,msgstr,notes
+,Nonexistent,Neexistuje,
+c-format,"The set of {$SET_NAME} is {{$XXX}, ...}.","Množina {$SET_NAME} je {{$XXX}, ...}.",checked
+,You have one egg,Máš jedno vajíčko,
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited.Messages[2].Ctxt = "The number of eggs you need."
+	if n := f.Update(edited); n != 1 {
+		t.Errorf("expected 1 change, got %d", n)
+	}
+	var msg = f.Lookup("", "The set of {$SET_NAME} is {{$XXX}, ...}.")
+	if msg == nil || msg.Str[0] != "Množina {$SET_NAME} je {{$XXX}, ...}." || !msg.HasFlag("c-format") {
+		t.Errorf("expected the message to be updated, got %v", msg)
+	}
+	if msg = f.Lookup("The number of eggs you need.", "You have one egg"); len(msg.Str) != 3 {
+		t.Errorf("expected the plural message to be left alone, got %v", msg)
+	}
+	if _, err = ParseCSV(strings.NewReader("id,str\nOpen,Otvoriť\n")); err == nil {
+		t.Errorf("expected an error for the missing msgid column")
+	}
+}
+
+func TestParseCSVUpdateColumns(t *testing.T) {
+	var f, _ = Parse(strings.NewReader(po))
+	var id = "The set of {$SET_NAME} is {{$XXX}, ...}."
+	var before = f.Lookup("", id)
+	before.TranslatorComments, before.Flags = []string{"keep"}, []string{"c-format"}
+	edited, err := ParseCSV(strings.NewReader("msgid,msgstr\n\"" + id + "\",Množina\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := f.Update(edited); n != 1 {
+		t.Errorf("expected 1 change, got %d", n)
+	}
+	var msg = f.Lookup("", id)
+	if msg.Str[0] != "Množina" || !reflect.DeepEqual(msg.TranslatorComments, before.TranslatorComments) || !reflect.DeepEqual(msg.Flags, before.Flags) {
+		t.Errorf("expected only the translation to be updated, got %v", msg)
+	}
+	if edited, err = ParseCSV(strings.NewReader("msgid,comments\n\"" + id + "\",checked\n")); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.Update(edited); n != 1 {
+		t.Errorf("expected 1 change, got %d", n)
+	}
+	if msg = f.Lookup("", id); msg.Str[0] != "Množina" || !reflect.DeepEqual(msg.TranslatorComments, []string{"checked"}) {
+		t.Errorf("expected only the comments to be updated, got %v", msg)
+	}
+}
+
+func TestParseCSVPlural(t *testing.T) {
+	var f, err = ParseCSV(strings.NewReader(`
+msgid,msgid_plural,msgstr[0],msgstr[1]
+%d file,%d files,%d Datei,%d Dateien
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := f.NGetText("%d file", "%d files", 3); actual != "%d Dateien" {
+		t.Errorf("unexpected plural %q", actual)
+	}
+	if actual := f.NGetTextFloat("%d file", "%d files", 1); actual != "%d Datei" {
+		t.Errorf("unexpected plural %q", actual)
+	}
+	if f, err = ParseCSV(strings.NewReader("")); err != nil || f.NGetText("%d file", "%d files", 2) != "%d files" {
+		t.Errorf("unexpected plural of an empty file, error %v", err)
+	}
+	f, err = ParseCSV(strings.NewReader(`
+language,msgid,msgid_plural,msgstr[0],msgstr[1],msgstr[2]
+sk,%d file,%d files,%d súbor,%d súbory,%d súborov
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := f.NGetText("%d file", "%d files", 5); actual != "%d súborov" || f.Language() != "sk" {
+		t.Errorf("unexpected plural %q in %q", actual, f.Language())
+	}
+}
@@ -0,0 +1,129 @@
+package po
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Miss is a lookup of a message missing from a catalog, or untranslated.
+type Miss struct {
+	Locale string    `json:"locale"`
+	Ctxt   string    `json:"ctxt,omitempty"`
+	Id     string    `json:"id"`
+	Time   time.Time `json:"time"`
+}
+
+// missLog keeps the last lookup misses of the catalogs of a bundle.
+type missLog struct {
+	mu     sync.Mutex
+	misses []Miss // ring buffer
+	next   int
+	full   bool
+}
+
+// track makes the lookups of missing messages in f record them, if the
+// misses are tracked.
+func (l *missLog) track(locale string, f *File) {
+	if l == nil {
+		return
+	}
+	var record = func(ctxt, id string) {
+		l.record(Miss{locale, ctxt, id, time.Now()})
+	}
+	f.missed.Store(&record)
+}
+
+func (l *missLog) record(m Miss) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.misses[l.next] = m
+	l.next = (l.next + 1) % len(l.misses)
+	l.full = l.full || l.next == 0
+}
+
+// recent returns the recorded misses, oldest first.
+func (l *missLog) recent() []Miss {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.full {
+		return append([]Miss(nil), l.misses[:l.next]...)
+	}
+	return append(append([]Miss(nil), l.misses[l.next:]...), l.misses[:l.next]...)
+}
+
+// TrackMisses makes the bundle keep the last n lookups of messages missing
+// from its catalogs, for DebugHandler, or stops tracking them if n is 0. The
+// misses are those of the lookups of the catalogs, present and added later,
+// whether through the bundle or not, and are recorded besides calling their
+// OnMiss hooks.
+func (b *Bundle) TrackMisses(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.misses = nil
+	if n > 0 {
+		b.misses = &missLog{misses: make([]Miss, n)}
+	}
+	for locale, f := range b.files {
+		f.missed.Store(nil)
+		b.misses.track(locale, f)
+	}
+}
+
+// CatalogInfo describes a catalog of a bundle.
+type CatalogInfo struct {
+	Messages int       `json:"messages"`
+	Bytes    int       `json:"bytes"` // estimated memory used by the messages
+	Domains  []string  `json:"domains,omitempty"`
+	Updated  time.Time `json:"updated"`
+}
+
+// DebugInfo describes the state of a bundle.
+type DebugInfo struct {
+	Locales map[string]CatalogInfo `json:"locales"`
+	Misses  []Miss                 `json:"misses,omitempty"`
+}
+
+// Debug returns the state of the bundle.
+func (b *Bundle) Debug() DebugInfo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var info = DebugInfo{Locales: make(map[string]CatalogInfo, len(b.files))}
+	for locale, f := range b.files {
+		info.Locales[locale] = CatalogInfo{
+			Messages: len(f.Messages),
+			Bytes:    f.size(),
+			Domains:  sortedDomains(b.domains[locale]),
+			Updated:  b.updated[locale],
+		}
+	}
+	if b.misses != nil {
+		info.Misses = b.misses.recent()
+	}
+	return info
+}
+
+// DebugHandler returns an HTTP handler serving the state of the bundle as
+// JSON, e.g. to be mounted at /debug/gettext.
+func (b *Bundle) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		var enc = json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(b.Debug())
+	})
+}
+
+// size estimates the memory used by the messages of the file, in bytes.
+func (f *File) size() int {
+	const overhead = 256 // message struct, slices and index entries
+	var n = 0
+	for _, msg := range f.Messages {
+		n += overhead + len(msg.Ctxt) + len(msg.Id) + len(msg.IdPlural)
+		for _, str := range msg.Str {
+			n += len(str)
+		}
+	}
+	return n
+}
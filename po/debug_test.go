@@ -0,0 +1,51 @@
+package po
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	var b = NewBundle()
+	var sk = &File{Messages: []*Message{{Id: "Open", Str: []string{"Otvoriť"}}}}
+	sk.reindex()
+	var hooked []string
+	sk.OnMiss = func(ctxt, id string) { hooked = append(hooked, id) }
+	b.Add("sk", sk)
+	b.TrackMisses(2)
+	b.AddDomain("de", "billing", &File{})
+	sk.GetText("Open")
+	sk.GetText("Save")
+	b.File("de").PGetText("menu", "Quit")
+	sk.GetText("Close")
+
+	var rec = httptest.NewRecorder()
+	b.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/gettext", nil))
+	var info DebugInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if sk := info.Locales["sk"]; sk.Messages != 1 || sk.Bytes == 0 || sk.Updated.IsZero() {
+		t.Errorf("unexpected sk info %+v", sk)
+	}
+	if de := info.Locales["de"]; !reflect.DeepEqual(de.Domains, []string{"billing"}) {
+		t.Errorf("unexpected de info %+v", de)
+	}
+	var misses []string
+	for _, m := range info.Misses {
+		misses = append(misses, m.Locale+" "+m.Ctxt+" "+m.Id)
+	}
+	if expected := []string{"de menu Quit", "sk  Close"}; !reflect.DeepEqual(expected, misses) {
+		t.Errorf("expected %v, got %v", expected, misses)
+	}
+	if expected := []string{"Save", "Close"}; !reflect.DeepEqual(expected, hooked) {
+		t.Errorf("expected OnMiss to be kept, got %v", hooked)
+	}
+	b.TrackMisses(0)
+	sk.GetText("Quit")
+	if len(hooked) != 3 || b.Debug().Misses != nil {
+		t.Errorf("expected only OnMiss to be called, got %v", hooked)
+	}
+}
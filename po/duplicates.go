@@ -0,0 +1,84 @@
+package po
+
+// DuplicatePolicy selects how parsing resolves the messages sharing their
+// context and msgid with a previous one, which are usually the sign of a
+// broken merge. Obsolete messages are resolved among themselves.
+type DuplicatePolicy int
+
+const (
+	// DuplicatesKeep keeps all the messages, the last of which is used by
+	// lookups. Validate reports the duplicates.
+	DuplicatesKeep DuplicatePolicy = iota
+	// DuplicatesError fails with a *ParseError wrapping a *DuplicateError,
+	// or records it in File.Errors and drops the duplicate in lenient mode.
+	DuplicatesError
+	// DuplicatesFirst keeps the first message only.
+	DuplicatesFirst
+	// DuplicatesLast keeps the last message only, in place of the first.
+	DuplicatesLast
+	// DuplicatesMerge keeps the first message, with the comments,
+	// references and flags of the duplicates added, and the translation of
+	// the first translated one if it has none.
+	DuplicatesMerge
+)
+
+// resolveDuplicates resolves the duplicate messages of msgs by the policy,
+// returning the messages kept, and the errors of the duplicates dropped in
+// lenient mode.
+func resolveDuplicates(msgs []*Message, lines map[*Message]int, policy DuplicatePolicy, lenient bool) ([]*Message, []ParseError, error) {
+	if policy == DuplicatesKeep {
+		return msgs, nil, nil
+	}
+	type dupKey struct {
+		ctxt, id string
+		obsolete bool
+	}
+	var seen = make(map[dupKey]int, len(msgs)) // index in r
+	var r = msgs[:0]
+	var errs []ParseError
+	for _, msg := range msgs {
+		var k = dupKey{msg.Ctxt, msg.Id, msg.Obsolete}
+		var i, dup = seen[k]
+		if !dup {
+			seen[k] = len(r)
+			r = append(r, msg)
+			continue
+		}
+		switch policy {
+		case DuplicatesError:
+			var err = ParseError{Line: lines[msg], Err: &DuplicateError{msg.Ctxt, msg.Id}}
+			err.Msg = err.Err.Error()[len("po: "):]
+			if !lenient {
+				return nil, nil, &err
+			}
+			errs = append(errs, err)
+		case DuplicatesLast:
+			r[i] = msg
+		case DuplicatesMerge:
+			mergeDuplicate(r[i], msg)
+		}
+	}
+	return r, errs, nil
+}
+
+// mergeDuplicate adds the comments, references and flags of dup missing from
+// msg to it, and the translation of dup if msg has none.
+func mergeDuplicate(msg, dup *Message) {
+	msg.TranslatorComments = appendMissing(msg.TranslatorComments, dup.TranslatorComments)
+	msg.ExtractedComments = appendMissing(msg.ExtractedComments, dup.ExtractedComments)
+	msg.References = appendMissingReferences(msg.References, dup.References)
+	msg.Flags = appendMissing(msg.Flags, dup.Flags)
+	if msg.isUntranslated() && !dup.isUntranslated() && (msg.IdPlural == "") == (dup.IdPlural == "") {
+		msg.IdPlural, msg.Str, msg.StrIndices = dup.IdPlural, dup.Str, dup.StrIndices
+	}
+}
+
+// appendMissing appends the values of add missing from vals to it.
+func appendMissing(vals, add []string) []string {
+	for _, v := range add {
+		if !contains(vals, v) {
+			vals = append(vals, v)
+		}
+	}
+	return vals
+}
@@ -0,0 +1,106 @@
+package po
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDuplicates(t *testing.T) {
+	var src = `
+#: a.go:1
+msgid "Open"
+msgstr ""
+
+msgid "Close"
+msgstr "Zavrieť"
+
+#. Verb
+#: b.go:2
+#, fuzzy
+msgid "Open"
+msgstr "Otvoriť"
+
+#~ msgid "Open"
+#~ msgstr "Otvor"
+
+`[1:]
+	var tests = []struct {
+		policy   DuplicatePolicy
+		expected string
+	}{
+		{DuplicatesKeep, src},
+		{DuplicatesFirst, `
+#: a.go:1
+msgid "Open"
+msgstr ""
+
+msgid "Close"
+msgstr "Zavrieť"
+
+#~ msgid "Open"
+#~ msgstr "Otvor"
+
+`[1:]},
+		{DuplicatesLast, `
+#. Verb
+#: b.go:2
+#, fuzzy
+msgid "Open"
+msgstr "Otvoriť"
+
+msgid "Close"
+msgstr "Zavrieť"
+
+#~ msgid "Open"
+#~ msgstr "Otvor"
+
+`[1:]},
+		{DuplicatesMerge, `
+#. Verb
+#: a.go:1 b.go:2
+#, fuzzy
+msgid "Open"
+msgstr "Otvoriť"
+
+msgid "Close"
+msgstr "Zavrieť"
+
+#~ msgid "Open"
+#~ msgstr "Otvor"
+
+`[1:]},
+	}
+	for _, test := range tests {
+		var f, err = ParseWithOptions(strings.NewReader(src), ParseOptions{Duplicates: test.policy})
+		if err != nil {
+			t.Errorf("%d: %v", test.policy, err)
+			continue
+		}
+		var buf bytes.Buffer
+		f.WriteTo(&buf)
+		if actual := buf.String(); actual != test.expected {
+			t.Errorf("%d: expected:\n%v\ngot:\n%v", test.policy, test.expected, actual)
+		}
+	}
+
+	var _, err = ParseWithOptions(strings.NewReader(src), ParseOptions{Duplicates: DuplicatesError})
+	var perr *ParseError
+	var dup *DuplicateError
+	if !errors.As(err, &perr) || perr.Line != 8 || !errors.As(err, &dup) || dup.Id != "Open" {
+		t.Errorf("unexpected error %v", err)
+	}
+	f, err := ParseWithOptions(strings.NewReader(src), ParseOptions{Duplicates: DuplicatesError, Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, e := range f.Errors {
+		lines = append(lines, e.Line)
+	}
+	if !reflect.DeepEqual([]int{8}, lines) || len(f.Messages) != 3 {
+		t.Errorf("unexpected errors %v or messages %d", f.Errors, len(f.Messages))
+	}
+}
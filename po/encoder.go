@@ -0,0 +1,212 @@
+package po
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/textproto"
+	"sort"
+)
+
+// WriteOptions controls how PO files are written.
+type WriteOptions struct {
+	// PadPlurals writes empty msgstr[n] entries for the plural forms missing
+	// from the plural messages, up to the nplurals of the file. Several tools
+	// reject catalogs with missing indices.
+	PadPlurals bool
+	// UTF8Charset declares the charset of the output as UTF-8 in the
+	// Content-Type header, which is always correct for parsed catalogs,
+	// instead of propagating a legacy charset declaration.
+	UTF8Charset bool
+	// Minify strips the comments of the messages, and drops the obsolete and
+	// untranslated ones, and the fuzzy ones unless the file has UseFuzzy, for
+	// the smallest catalog giving the same lookups.
+	Minify bool
+	// HeaderOrder selects the order of the header fields, alphabetical by
+	// default.
+	HeaderOrder HeaderOrder
+	// Progress, if set, is called periodically while writing, and once done.
+	Progress func(Progress)
+	// Width is the maximum length of the lines of the quoted strings, which
+	// are broken after spaces to fit, 79 by default like GNU gettext. Lines
+	// without spaces may be longer.
+	Width int
+	// NoWrap breaks the quoted strings after their newlines only, like the
+	// --no-wrap option of GNU gettext.
+	NoWrap bool
+	// CharsetWriter, if set, returns a writer encoding the UTF-8 written to
+	// it in the charset, for the files decoded by ParseOptions.CharsetReader.
+	// ISO-8859-1 is encoded without it.
+	CharsetWriter func(charset string, output io.Writer) (io.Writer, error)
+	// SortBy selects the order of the messages, as in the file by default.
+	SortBy SortBy
+}
+
+// SortBy selects the order messages are written in. Obsolete messages are
+// written last in any order.
+type SortBy int
+
+const (
+	// SortByOriginalOrder keeps the messages in the order of the file.
+	SortByOriginalOrder SortBy = iota
+	// SortByID sorts the messages by msgid, then msgctxt, like msgcat
+	// --sort-output.
+	SortByID
+	// SortByReference sorts the references of the messages, and the messages
+	// by their first reference, then msgid, like msgcat --sort-by-file.
+	// Messages without references come first.
+	SortByReference
+)
+
+// width returns the line width of the quoted strings, or 0 for no wrapping.
+func (opts WriteOptions) width() int {
+	switch {
+	case opts.NoWrap:
+		return 0
+	case opts.Width > 0:
+		return opts.Width
+	}
+	return defaultWidth
+}
+
+// Encoder writes PO files to an output stream.
+type Encoder struct {
+	w    io.Writer
+	opts WriteOptions
+}
+
+// NewEncoder returns an encoder writing to w with the given options.
+func NewEncoder(w io.Writer, opts WriteOptions) *Encoder {
+	return &Encoder{w, opts}
+}
+
+// Encode writes the file to the stream.
+func (e *Encoder) Encode(f *File) error {
+	var _, err = f.write(e.w, e.opts)
+	return err
+}
+
+func (f *File) write(w io.Writer, opts WriteOptions) (n int64, err error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var wr = newWriter()
+	wr.width = opts.width()
+	var header = f.Header
+	if opts.UTF8Charset && len(header) > 0 {
+		header = utf8Header(header)
+	}
+	if !opts.Minify {
+		wr.raw(f.HeaderComment)
+		if len(header) == 0 && len(f.HeaderComment) > 0 {
+			wr.newline()
+		}
+	}
+	// TODO: Probably better to make a type for the header and implement WriterTo
+	if len(header) > 0 {
+		wr.quo("msgid ", "")
+		var buf bytes.Buffer
+		for _, name := range f.headerNames(header, opts.HeaderOrder) {
+			for _, v := range header.Values(name) {
+				buf.WriteString(name + ": " + v + "\n")
+			}
+		}
+		wr.lines("msgstr ", buf.String())
+		wr.newline()
+	}
+	var nplurals int
+	if opts.PadPlurals {
+		nplurals = f.nplurals()
+	}
+	for i, msg := range sortMessages(f.Messages, opts.SortBy) {
+		if opts.Progress != nil && i%progressInterval == 0 && i > 0 {
+			opts.Progress(Progress{i, int64(wr.buf.Len())})
+		}
+		if c := f.comments[msg]; c != nil && !opts.Minify {
+			wr.raw(c)
+			wr.newline()
+		}
+		if opts.Minify {
+			if msg.Obsolete || msg.isUntranslated() || msg.HasFlag("fuzzy") && !f.UseFuzzy {
+				continue
+			}
+			var stripped = *msg
+			stripped.Comment = Comment{}
+			msg = &stripped
+		}
+		if opts.SortBy == SortByReference && len(msg.References) > 1 {
+			var sorted = *msg
+			sorted.References = sortReferences(msg.References)
+			msg = &sorted
+		}
+		if msg.IdPlural != "" && len(msg.Str) < nplurals {
+			var padded = *msg
+			padded.Str = make([]string, nplurals)
+			copy(padded.Str, msg.Str)
+			msg = &padded
+		}
+		wr.message(msg)
+		wr.newline()
+	}
+	if !opts.Minify {
+		wr.raw(f.comments[nil])
+	}
+	if opts.Progress != nil {
+		opts.Progress(Progress{len(f.Messages), int64(wr.buf.Len())})
+	}
+	if f.charset != "" && !opts.UTF8Charset {
+		if _, params, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil && !isUTF8Charset(params["charset"]) {
+			if w, err = encodeCharset(w, params["charset"], opts); err != nil {
+				wr.to(io.Discard)
+				return 0, err
+			}
+		}
+	}
+	return wr.to(w)
+}
+
+// utf8Header returns a copy of the header, with charset=UTF-8 in Content-Type.
+func utf8Header(h textproto.MIMEHeader) textproto.MIMEHeader {
+	var r = make(textproto.MIMEHeader, len(h))
+	for k, v := range h {
+		r[k] = v
+	}
+	var mediaType, params, err = mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+	if params == nil {
+		params = make(map[string]string)
+	}
+	params["charset"] = "UTF-8"
+	r.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	return r
+}
+
+// sortMessages returns the messages in the given order.
+func sortMessages(msgs []*Message, by SortBy) []*Message {
+	if by == SortByOriginalOrder {
+		return msgs
+	}
+	var r = append([]*Message(nil), msgs...)
+	sort.SliceStable(r, func(i, j int) bool {
+		var a, b = r[i], r[j]
+		if a.Obsolete != b.Obsolete {
+			return b.Obsolete
+		}
+		if by == SortByReference {
+			var refA, okA = firstReference(a.References)
+			var refB, okB = firstReference(b.References)
+			if okA != okB {
+				return okB
+			}
+			if c := compareReferences(refA, refB); c != 0 {
+				return c < 0
+			}
+		}
+		if a.Id != b.Id {
+			return a.Id < b.Id
+		}
+		return a.Ctxt < b.Ctxt
+	})
+	return r
+}
@@ -0,0 +1,79 @@
+package po
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrBadHeader is returned for a header that cannot be parsed, or a
+	// header field with an invalid value.
+	ErrBadHeader = errors.New("po: malformed header")
+	// ErrNoHeader is returned when a required header field is missing.
+	ErrNoHeader = errors.New("po: missing header field")
+	// ErrBadMO is returned for a file that is not a valid MO file.
+	ErrBadMO = errors.New("po: malformed MO file")
+)
+
+// ParseError reports malformed input, with its location.
+type ParseError struct {
+	Line    int    // line of the input, starting at 1
+	Column  int    // byte offset in the line, starting at 1, or 0 if unknown
+	Msg     string // description of the error
+	Context string // text of the offending line
+	Err     error  // underlying error, if any
+}
+
+// SyntaxError is the former name of ParseError.
+//
+// Deprecated: use ParseError.
+type SyntaxError = ParseError
+
+func (e *ParseError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("po: line %d, column %d: %s", e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("po: line %d: %s", e.Line, e.Msg)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// PluralFormsError reports a Plural-Forms header that is not recognized.
+type PluralFormsError struct {
+	Expr string
+}
+
+func (e *PluralFormsError) Error() string {
+	return "po: unrecognized plural form selector: " + e.Expr
+}
+
+// DuplicateError reports a message with the same context and msgid as
+// another.
+type DuplicateError struct {
+	Ctxt string
+	Id   string
+}
+
+func (e *DuplicateError) Error() string {
+	if e.Ctxt != "" {
+		return fmt.Sprintf("po: duplicate message %q in context %q", e.Id, e.Ctxt)
+	}
+	return fmt.Sprintf("po: duplicate message %q", e.Id)
+}
+
+// ConflictError reports a message translated differently by the catalogs
+// being concatenated.
+type ConflictError struct {
+	Ctxt       string
+	Id         string
+	Str, Other []string // the conflicting msgstrs
+}
+
+func (e *ConflictError) Error() string {
+	if e.Ctxt != "" {
+		return fmt.Sprintf("po: conflicting translations of %q in context %q: %q and %q", e.Id, e.Ctxt, e.Str, e.Other)
+	}
+	return fmt.Sprintf("po: conflicting translations of %q: %q and %q", e.Id, e.Str, e.Other)
+}
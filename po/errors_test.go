@@ -0,0 +1,117 @@
+package po
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypedErrors(t *testing.T) {
+	var _, err = Parse(strings.NewReader("msgid \"Open\"\nmsgstr \"Otvori\\q\"\n"))
+	var syntax *ParseError
+	if !errors.As(err, &syntax) || syntax.Line != 2 || syntax.Column != 8 || syntax.Context != `msgstr "Otvori\q"` {
+		t.Errorf("expected a syntax error on line 2, column 8, got %v", err)
+	}
+
+	_, err = Parse(strings.NewReader("msgid \"Open\"\nmsgstr \"Otvori\"\n\n#~ msgid \"Close\"\n#~ msgstr[x] \"Zavri\"\n"))
+	if !errors.As(err, &syntax) || syntax.Line != 5 || syntax.Column != 11 {
+		t.Errorf("expected a syntax error on line 5, column 11, got %v", err)
+	}
+
+	_, err = Parse(strings.NewReader("msgid \"Open\"\nmsgstr \"Otvori\"\n\nmsgi \"Close\"\n"))
+	if !errors.As(err, &syntax) || syntax.Error() != `po: line 4, column 1: unexpected "msgi \"Close\""` {
+		t.Errorf("expected an unexpected line error, got %v", err)
+	}
+
+	_, err = Parse(strings.NewReader("msgid \"\"\nmsgstr \"Plural-Forms: nplurals=2; plural=n>;\\n\"\n"))
+	var plural *PluralFormsError
+	if !errors.As(err, &plural) || plural.Expr != "nplurals=2; plural=n>;" {
+		t.Errorf("expected a plural forms error, got %v", err)
+	}
+
+	_, err = Parse(strings.NewReader("msgid \"\"\nmsgstr \"Language\\n\"\n"))
+	if !errors.Is(err, ErrBadHeader) {
+		t.Errorf("expected ErrBadHeader, got %v", err)
+	}
+
+	var f = &File{Messages: []*Message{{Ctxt: "a", Id: "Open"}, {Ctxt: "b", Id: "Open"}}}
+	_, err = f.RenameNamespace("a", "b")
+	var dup *DuplicateError
+	if !errors.As(err, &dup) || dup.Ctxt != "b" || dup.Id != "Open" {
+		t.Errorf("expected a duplicate error, got %v", err)
+	}
+
+	if _, err = f.RevisionDate(); !errors.Is(err, ErrNoHeader) {
+		t.Errorf("expected ErrNoHeader, got %v", err)
+	}
+
+	if f, err = Parse(strings.NewReader("")); err != nil || len(f.Messages) != 0 {
+		t.Errorf("expected an empty file, got %v, %v", f, err)
+	}
+}
+
+func TestParseWarnings(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"X-Generator: Poedit\n"
+"X-Generator: Weblate\n"
+
+#, sparkly
+msgid "Open"
+msgstr "Otvori\x21"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, w := range f.Warnings {
+		actual = append(actual, w.String())
+	}
+	var expected = []string{
+		`line 8: "Open": warning: escape: suspicious escape sequence \x`,
+		"header: warning: header: repeated header field X-Generator",
+		`line 6: "Open": warning: flags: unknown flag sparkly`,
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected:\n%v\ngot:\n%v", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestParseLenient(t *testing.T) {
+	var input = `
+msgid "Open"
+msgstr "Otvori\q"
+
+msgid "Close"
+msgstr "Zavri"
+
+msgid "Save"
+msgstr[x] "Uloz"
+msgstr[1] "Ulozit"
+
+msgid "Quit"
+msgstr "Koniec"
+`[1:]
+	if _, err := Parse(strings.NewReader(input)); err == nil {
+		t.Fatal("expected an error")
+	}
+	var f, err = ParseWithOptions(strings.NewReader(input), ParseOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, msg := range f.Messages {
+		ids = append(ids, msg.Id)
+	}
+	if !reflect.DeepEqual(ids, []string{"Close", "Quit"}) {
+		t.Errorf("expected the malformed entries to be skipped, got %q", ids)
+	}
+	if len(f.Errors) != 2 || f.Errors[0].Line != 2 || f.Errors[1].Line != 8 {
+		t.Errorf("expected errors on lines 2 and 8, got %v", f.Errors)
+	}
+	if f.GetText("Quit") != "Koniec" {
+		t.Errorf("expected the messages after the errors to be parsed")
+	}
+}
@@ -0,0 +1,157 @@
+package po
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// escapes maps the characters written as a letter escape sequence in quoted
+// strings to the letter.
+var escapes = map[byte]byte{
+	'\a': 'a', '\b': 'b', '\f': 'f', '\n': 'n', '\r': 'r', '\t': 't', '\v': 'v',
+	'"': '"', '\\': '\\',
+}
+
+// unescapes is the inverse of escapes, also accepting \' and \? as in C.
+var unescapes = map[byte]byte{
+	'a': '\a', 'b': '\b', 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v',
+	'"': '"', '\\': '\\', '\'': '\'', '?': '?',
+}
+
+// written returns true if quote writes escape sequences starting with c,
+// after the backslash.
+func written(c byte) bool {
+	return unescapes[c] != 0 && escapes[unescapes[c]] == c || '0' <= c && c <= '7'
+}
+
+// quote returns s as a quoted string of a PO file, with C escape sequences.
+// Unlike strconv.Quote, other characters are kept as is, including invalid
+// UTF-8, and control characters without a letter escape are written in
+// octal.
+func quote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		var c = s[i]
+		if e, ok := escapes[c]; ok {
+			b.WriteByte('\\')
+			b.WriteByte(e)
+		} else if c < ' ' || c == 0x7f {
+			b.WriteByte('\\')
+			b.WriteByte('0' + c>>6)
+			b.WriteByte('0' + c>>3&7)
+			b.WriteByte('0' + c&7)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+var errBadEscape = errors.New("invalid escape sequence")
+
+// unquote returns the content of a quoted string of a PO file, interpreting
+// the C escape sequences, including octal \ooo, hexadecimal \xhh and the
+// universal character names \uhhhh and \Uhhhhhhhh.
+func unquote(s string) (string, error) {
+	var b, err = appendUnquoted(nil, []byte(s))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// appendUnquoted appends the content of the quoted string s to b, like
+// unquote, so that the parser reuses its buffer.
+func appendUnquoted(b, s []byte) ([]byte, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return b, errors.New("missing quotes")
+	}
+	s = s[1 : len(s)-1]
+	if bytes.IndexByte(s, '\\') == -1 {
+		if bytes.IndexByte(s, '"') != -1 {
+			return b, errors.New("unescaped quote")
+		}
+		return append(b, s...), nil
+	}
+	for i := 0; i < len(s); i++ {
+		var c = s[i]
+		if c == '"' {
+			return b, errors.New("unescaped quote")
+		}
+		if c != '\\' {
+			b = append(b, c)
+			continue
+		}
+		if i++; i == len(s) {
+			return b, errBadEscape
+		}
+		c = s[i]
+		switch {
+		case unescapes[c] != 0:
+			b = append(b, unescapes[c])
+		case '0' <= c && c <= '7':
+			var n = 0
+			for j := 0; j < 3 && i < len(s) && '0' <= s[i] && s[i] <= '7'; j++ {
+				n = n*8 + int(s[i]-'0')
+				i++
+			}
+			if n > 0xff {
+				return b, errBadEscape
+			}
+			b = append(b, byte(n))
+			i--
+		case c == 'x':
+			var n, digits = 0, 0
+			for i+1 < len(s) && digits < 2 && unhex(s[i+1]) >= 0 {
+				n = n*16 + unhex(s[i+1])
+				digits++
+				i++
+			}
+			if digits == 0 {
+				return b, errBadEscape
+			}
+			b = append(b, byte(n))
+		case c == 'u' || c == 'U':
+			var size = 4
+			if c == 'U' {
+				size = 8
+			}
+			if i+size >= len(s) {
+				return b, errBadEscape
+			}
+			var r rune
+			for _, d := range s[i+1 : i+1+size] {
+				if unhex(d) < 0 {
+					return b, errBadEscape
+				}
+				r = r*16 + rune(unhex(d))
+			}
+			if !utf8.ValidRune(r) {
+				return b, errBadEscape
+			}
+			b = utf8.AppendRune(b, r)
+			i += size
+		default:
+			return b, errBadEscape
+		}
+	}
+	return b, nil
+}
+
+// unhex returns the value of the hexadecimal digit c, or -1.
+func unhex(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
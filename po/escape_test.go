@@ -0,0 +1,93 @@
+package po
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	for _, tt := range []struct{ in, out string }{
+		{"Open", `"Open"`},
+		{"say \"hi\"\\n", `"say \"hi\"\\n"`},
+		{"a\tb\r\n", `"a\tb\r\n"`},
+		{"\x00\x1b\x7f", `"\000\033\177"`},
+		{"Kaviareň\u200b", "\"Kaviareň\u200b\""},
+		{"\xff", "\"\xff\""},
+	} {
+		if actual := quote(tt.in); actual != tt.out {
+			t.Errorf("quote(%q): expected %s, got %s", tt.in, tt.out, actual)
+		}
+		if actual, err := unquote(tt.out); err != nil || actual != tt.in {
+			t.Errorf("unquote(%s): expected %q, got %q, %v", tt.out, tt.in, actual, err)
+		}
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	for _, tt := range []struct{ in, out string }{
+		{`"\a\b\f\v\'\?"`, "\a\b\f\v'?"},
+		{`"\101\0\1012"`, "A\x00A2"},
+		{`"\x41\x4a4\xe9"`, "AJ4\xe9"},
+		{`"Café \U0001F600"`, "Café \U0001F600"},
+	} {
+		if actual, err := unquote(tt.in); err != nil || actual != tt.out {
+			t.Errorf("unquote(%s): expected %q, got %q, %v", tt.in, tt.out, actual, err)
+		}
+	}
+	for _, in := range []string{`"\q"`, `"\"`, `"\x"`, `"\400"`, `"\u12"`, `"a"b"`, `"a`, `a`} {
+		if actual, err := unquote(in); err == nil {
+			t.Errorf("unquote(%s): expected an error, got %q", in, actual)
+		}
+	}
+}
+
+func TestEscapeRoundTrip(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid "Line\tone\nLine \"two\"\n\\ end"
+msgstr "Riadok\001\x7f\n\u200b"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg = f.Messages[0]
+	if msg.Id != "Line\tone\nLine \"two\"\n\\ end" || msg.Str[0] != "Riadok\x01\x7f\n\u200b" {
+		t.Fatalf("unexpected message %q %q", msg.Id, msg.Str)
+	}
+	var buf bytes.Buffer
+	if _, err = f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\"Riadok\\001\\177\\n\"\n\"\u200b\"\n") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+	reparsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := reparsed.Messages[0]; actual.Id != msg.Id || actual.Str[0] != msg.Str[0] {
+		t.Errorf("expected the strings to round-trip, got %q %q", actual.Id, actual.Str)
+	}
+}
+
+func TestEscapeRoundTripWarnings(t *testing.T) {
+	var f = NewFile("sk")
+	f.Set("", "a\rb", "c\x01d")
+	f.Set("", "\a\b\f\v", "\"\\\t\x7f")
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	reparsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reparsed.Warnings) != 0 {
+		t.Errorf("unexpected warnings %v", reparsed.Warnings)
+	}
+	for i, msg := range reparsed.Messages {
+		if expected := f.Messages[i]; msg.Id != expected.Id || msg.Str[0] != expected.Str[0] {
+			t.Errorf("expected %q %q, got %q %q", expected.Id, expected.Str, msg.Id, msg.Str)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package po
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Extension is a custom "#% name=value" comment of a message.
+type Extension struct {
+	Name  string
+	Value interface{} // parsed by the registered Directive, or the raw string
+}
+
+// Directive parses and formats the values of the extension comments with a
+// given name, e.g. "#% screenshot=https://example.com/login.png".
+type Directive interface {
+	Name() string
+	Parse(value string) (interface{}, error)
+	Format(value interface{}) string
+}
+
+// NewDirective returns a Directive calling parse and format.
+func NewDirective(name string, parse func(string) (interface{}, error), format func(interface{}) string) Directive {
+	return funcDirective{name, parse, format}
+}
+
+type funcDirective struct {
+	name   string
+	parse  func(string) (interface{}, error)
+	format func(interface{}) string
+}
+
+func (d funcDirective) Name() string                            { return d.name }
+func (d funcDirective) Parse(value string) (interface{}, error) { return d.parse(value) }
+func (d funcDirective) Format(value interface{}) string         { return d.format(value) }
+
+var (
+	directivesMu sync.RWMutex
+	directives   = make(map[string]Directive)
+)
+
+// The directives registered by the package: max-length, as the limit of
+// MaxLength, and the kinds of attachments of Attachments, as their URLs.
+func init() {
+	RegisterDirective(NewDirective("max-length", func(s string) (interface{}, error) {
+		var n, err = strconv.Atoi(s)
+		if err == nil && n < 0 {
+			err = errors.New("negative length")
+		}
+		return n, err
+	}, func(v interface{}) string {
+		return fmt.Sprint(v)
+	}))
+	for _, kind := range AttachmentKinds {
+		var kind = kind
+		RegisterDirective(NewDirective(kind, func(s string) (interface{}, error) {
+			if _, ok := parseAttachment(kind + ": " + s); !ok {
+				return nil, errors.New("not a URL")
+			}
+			return s, nil
+		}, func(v interface{}) string {
+			return fmt.Sprint(v)
+		}))
+	}
+}
+
+// RegisterDirective makes the parser and writer use d for the extension
+// comments with its name. Extensions without a registered directive are
+// kept as strings. It panics if a directive with the same name is already
+// registered, including those of the package: max-length, screenshot,
+// design and context.
+func RegisterDirective(d Directive) {
+	directivesMu.Lock()
+	defer directivesMu.Unlock()
+	if _, dup := directives[d.Name()]; dup {
+		panic("po: RegisterDirective called twice for directive " + d.Name())
+	}
+	directives[d.Name()] = d
+}
+
+func lookupDirective(name string) Directive {
+	directivesMu.RLock()
+	defer directivesMu.RUnlock()
+	return directives[name]
+}
+
+// Extension returns the value of the named extension comment, if present.
+func (c Comment) Extension(name string) (interface{}, bool) {
+	for _, ext := range c.Extensions {
+		if ext.Name == name {
+			return ext.Value, true
+		}
+	}
+	return nil, false
+}
+
+// SetExtension sets the value of the named extension comment.
+func (c *Comment) SetExtension(name string, value interface{}) {
+	for i := range c.Extensions {
+		if c.Extensions[i].Name == name {
+			c.Extensions[i].Value = value
+			return
+		}
+	}
+	c.Extensions = append(c.Extensions, Extension{name, value})
+}
+
+// parseExtension parses a "name=value" extension comment. Values rejected
+// by their directive are returned as strings, with the error.
+func parseExtension(line string) (Extension, error) {
+	var name, value, _ = strings.Cut(line, "=")
+	var ext = Extension{strings.TrimSpace(name), strings.TrimSpace(value)}
+	if d := lookupDirective(ext.Name); d != nil {
+		v, err := d.Parse(ext.Value.(string))
+		if err != nil {
+			return ext, fmt.Errorf("invalid %s extension: %v", ext.Name, err)
+		}
+		ext.Value = v
+	}
+	return ext, nil
+}
+
+// String formats the extension as a "name=value" comment.
+func (ext Extension) String() string {
+	if d := lookupDirective(ext.Name); d != nil {
+		if _, raw := ext.Value.(string); !raw {
+			return ext.Name + "=" + d.Format(ext.Value)
+		}
+	}
+	return fmt.Sprintf("%s=%v", ext.Name, ext.Value)
+}
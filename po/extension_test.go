@@ -0,0 +1,57 @@
+package po
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func init() {
+	RegisterDirective(NewDirective("test-width", func(s string) (interface{}, error) {
+		return strconv.Atoi(s)
+	}, func(v interface{}) string {
+		return strconv.Itoa(v.(int))
+	}))
+}
+
+func TestExtensions(t *testing.T) {
+	var src = `
+#. Login button.
+#% test-width=20
+#% screenshot=https://example.com/login.png
+msgid "Log in"
+msgstr "Prihlásiť"
+
+#% test-width=wide
+msgid "Log out"
+msgstr "Odhlásiť"
+
+`[1:]
+	var f, err = Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := f.Messages[0].Extension("test-width"); v != 20 {
+		t.Errorf("expected a width of 20, got %#v", v)
+	}
+	if v, _ := f.Messages[0].Extension("screenshot"); v != "https://example.com/login.png" {
+		t.Errorf("expected the raw screenshot URL, got %#v", v)
+	}
+	if len(f.Warnings) != 1 || f.Warnings[0].Line != 7 || f.Warnings[0].Msg != f.Messages[1] {
+		t.Errorf("expected a warning for the invalid width, got %v", f.Warnings)
+	}
+
+	var buf bytes.Buffer
+	f.WriteTo(&buf)
+	if buf.String() != src {
+		t.Errorf("expected:\n%s\ngot:\n%s", src, buf.String())
+	}
+
+	f.Messages[1].SetExtension("test-width", 30)
+	buf.Reset()
+	f.Messages[1].WriteTo(&buf)
+	if !strings.HasPrefix(buf.String(), "#% test-width=30\n") {
+		t.Errorf("unexpected message:\n%s", buf.String())
+	}
+}
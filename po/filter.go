@@ -0,0 +1,106 @@
+package po
+
+import (
+	"path"
+	"strings"
+)
+
+// Filter returns a catalog of the messages of the file satisfying pred, like
+// msgattrib, e.g. f.Filter(Not(Obsolete)) to strip the obsolete messages.
+// The catalog shares the messages of the file, and a copy of its header.
+func (f *File) Filter(pred func(*Message) bool) *File {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var r = &File{
+		Header:          cloneHeader(f.Header),
+		HeaderComment:   f.HeaderComment,
+		Pluralize:       f.Pluralize,
+		ContextFallback: f.ContextFallback,
+		UseFuzzy:        f.UseFuzzy,
+		headerOrder:     f.headerOrder,
+	}
+	for _, msg := range f.Messages {
+		if pred(msg) {
+			r.Messages = append(r.Messages, msg)
+		}
+	}
+	r.reindex()
+	return r
+}
+
+// Translated matches the messages fully translated, and not fuzzy.
+func Translated(m *Message) bool {
+	return m.IsTranslated() && !m.HasFlag("fuzzy")
+}
+
+// Untranslated matches the messages not fully translated, and not fuzzy.
+func Untranslated(m *Message) bool {
+	return !m.IsTranslated() && !m.HasFlag("fuzzy")
+}
+
+// Fuzzy matches the messages flagged fuzzy.
+func Fuzzy(m *Message) bool {
+	return m.HasFlag("fuzzy")
+}
+
+// Obsolete matches the obsolete messages.
+func Obsolete(m *Message) bool {
+	return m.Obsolete
+}
+
+// HasFlag returns a predicate matching the messages with the flag, e.g.
+// "c-format".
+func HasFlag(flag string) func(*Message) bool {
+	return func(m *Message) bool {
+		return m.HasFlag(flag)
+	}
+}
+
+// MatchesReference returns a predicate matching the messages with a
+// reference to a file matching the pattern, as path.Match, e.g. "cmd/*.go".
+// Patterns without a "/" are matched against the base names of the files.
+func MatchesReference(pattern string) func(*Message) bool {
+	return func(m *Message) bool {
+		for _, ref := range m.References {
+			var file = ref.File
+			if !strings.Contains(pattern, "/") {
+				file = path.Base(file)
+			}
+			if matched, _ := path.Match(pattern, file); matched {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns a predicate matching the messages pred does not match.
+func Not(pred func(*Message) bool) func(*Message) bool {
+	return func(m *Message) bool {
+		return !pred(m)
+	}
+}
+
+// And returns a predicate matching the messages all of preds match.
+func And(preds ...func(*Message) bool) func(*Message) bool {
+	return func(m *Message) bool {
+		for _, pred := range preds {
+			if !pred(m) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a predicate matching the messages any of preds match.
+func Or(preds ...func(*Message) bool) func(*Message) bool {
+	return func(m *Message) bool {
+		for _, pred := range preds {
+			if pred(m) {
+				return true
+			}
+		}
+		return false
+	}
+}
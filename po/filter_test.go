@@ -0,0 +1,66 @@
+package po
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Language: sk\n"
+
+#: cmd/main.go:10
+#, c-format
+msgid "%d files"
+msgstr "%d súborov"
+
+#: internal/ui/menu.go:3
+#, fuzzy
+msgid "Open"
+msgstr "Otvor"
+
+#: internal/ui/menu.go:4 cmd/main.go:12
+msgid "Close"
+msgstr ""
+
+#~ msgid "Quit"
+#~ msgstr "Koniec"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		name     string
+		pred     func(*Message) bool
+		expected []string
+	}{
+		{"translated", Translated, []string{"%d files", "Quit"}},
+		{"untranslated", Untranslated, []string{"Close"}},
+		{"fuzzy", Fuzzy, []string{"Open"}},
+		{"obsolete", Obsolete, []string{"Quit"}},
+		{"not obsolete", Not(Obsolete), []string{"%d files", "Open", "Close"}},
+		{"c-format", HasFlag("c-format"), []string{"%d files"}},
+		{"main.go", MatchesReference("main.go"), []string{"%d files", "Close"}},
+		{"internal/ui/*.go", MatchesReference("internal/ui/*.go"), []string{"Open", "Close"}},
+		{"translated or fuzzy", And(Not(Obsolete), Or(Translated, Fuzzy)), []string{"%d files", "Open"}},
+	}
+	for _, test := range tests {
+		var r = f.Filter(test.pred)
+		var ids []string
+		for _, msg := range r.Messages {
+			ids = append(ids, msg.Id)
+		}
+		if !reflect.DeepEqual(test.expected, ids) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, ids)
+		}
+		if r.Header.Get("Language") != "sk" {
+			t.Errorf("%s: expected the header to be kept", test.name)
+		}
+	}
+	if actual := f.Filter(Fuzzy).PGetText("", "Open"); actual != "Open" {
+		t.Errorf("expected the fuzzy translation to be unused, got %q", actual)
+	}
+}
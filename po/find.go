@@ -0,0 +1,86 @@
+package po
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FindField is a set of the fields of messages searched by File.Find.
+type FindField int
+
+// The fields searched.
+const (
+	FindId         FindField = 1 << iota // msgid and msgid_plural
+	FindStr                              // msgstrs
+	FindCtxt                             // msgctxt
+	FindComments                         // translator and extracted comments
+	FindReferences                       // references, e.g. "main.go:12"
+	FindFlags                            // flags, e.g. "c-format"
+
+	FindAll = FindId | FindStr | FindCtxt | FindComments | FindReferences | FindFlags
+)
+
+// FindOptions select the messages returned by File.Find. A message is found
+// if a field searched matches both Text and Regexp, where set, and it
+// satisfies Pred, if set.
+type FindOptions struct {
+	Text       string         // substring searched for
+	IgnoreCase bool           // whether Text matches regardless of case
+	Regexp     *regexp.Regexp // regular expression searched for
+	Fields     FindField      // fields searched, FindId|FindStr if 0
+	Pred       func(*Message) bool
+}
+
+// Find returns the messages matching the options, in order, e.g. for
+// translation editors searching catalogs. Obsolete messages are searched
+// too, and left out with Pred: Not(Obsolete).
+func (f *File) Find(opts FindOptions) []*Message {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if opts.Fields == 0 {
+		opts.Fields = FindId | FindStr
+	}
+	var text = opts.Text
+	if opts.IgnoreCase {
+		text = strings.ToLower(text)
+	}
+	var match = func(s string) bool {
+		var folded = s
+		if opts.IgnoreCase {
+			folded = strings.ToLower(s)
+		}
+		return strings.Contains(folded, text) && (opts.Regexp == nil || opts.Regexp.MatchString(s))
+	}
+	var r []*Message
+	for _, m := range f.Messages {
+		if opts.Pred != nil && !opts.Pred(m) {
+			continue
+		}
+		if opts.Text == "" && opts.Regexp == nil || m.matchFields(opts.Fields, match) {
+			r = append(r, m)
+		}
+	}
+	return r
+}
+
+// matchFields returns true if match returns true for one of the fields.
+func (m *Message) matchFields(fields FindField, match func(string) bool) bool {
+	var in = func(field FindField, values ...string) bool {
+		if fields&field == 0 {
+			return false
+		}
+		for _, v := range values {
+			if match(v) {
+				return true
+			}
+		}
+		return false
+	}
+	return in(FindId, m.Id, m.IdPlural) ||
+		in(FindStr, m.Str...) ||
+		in(FindCtxt, m.Ctxt) ||
+		in(FindComments, m.TranslatorComments...) ||
+		in(FindComments, m.ExtractedComments...) ||
+		in(FindReferences, referenceStrings(m.References)...) ||
+		in(FindFlags, m.Flags...)
+}
@@ -0,0 +1,62 @@
+package po
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	f, err := Parse(strings.NewReader(`# Shown on the toolbar.
+#. TRANSLATORS: a verb
+#: ui/toolbar.go:12
+msgid "Open"
+msgstr "Otvoriť"
+
+#: ui/menu.go:3
+#, c-format
+msgctxt "menu"
+msgid "Open %s"
+msgstr "Otvor %s"
+
+#: cmd/main.go:40
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d súbor"
+msgstr[1] "%d súbory"
+
+#~ msgid "Opened"
+#~ msgstr "Otvorené"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids = func(msgs []*Message) string {
+		var r []string
+		for _, m := range msgs {
+			r = append(r, m.Id)
+		}
+		return strings.Join(r, ", ")
+	}
+	for _, c := range []struct {
+		opts     FindOptions
+		expected string
+	}{
+		{FindOptions{Text: "Open"}, "Open, Open %s, Opened"},
+		{FindOptions{Text: "open", IgnoreCase: true, Pred: Not(Obsolete)}, "Open, Open %s"},
+		{FindOptions{Text: "súbory"}, "%d file"},
+		{FindOptions{Text: "súbory", Fields: FindId}, ""},
+		{FindOptions{Regexp: regexp.MustCompile(`^Otvor\b`)}, "Open %s"},
+		{FindOptions{Text: "menu", Fields: FindCtxt}, "Open %s"},
+		{FindOptions{Text: "verb", Fields: FindComments}, "Open"},
+		{FindOptions{Text: "toolbar", Fields: FindComments}, "Open"},
+		{FindOptions{Regexp: regexp.MustCompile(`^ui/`), Fields: FindReferences}, "Open, Open %s"},
+		{FindOptions{Text: "c-format", Fields: FindFlags}, "Open %s"},
+		{FindOptions{Text: "main", Fields: FindAll}, "%d file"},
+		{FindOptions{Pred: Obsolete}, "Opened"},
+	} {
+		if actual := ids(f.Find(c.opts)); actual != c.expected {
+			t.Errorf("%+v: expected %q, got %q", c.opts, c.expected, actual)
+		}
+	}
+}
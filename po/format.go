@@ -0,0 +1,228 @@
+package po
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// directive is a conversion specification of a format string.
+type directive struct {
+	arg  int    // number of the argument converted, starting at 1
+	conv string // type of the argument, e.g. "d" for "%i", or "ls" for "%ls"
+	text string // as written, e.g. "%5.2f"
+}
+
+// cFormatRe matches the conversion specifications of C format strings, with
+// the argument number, width, precision, length modifier and conversion.
+var cFormatRe = regexp.MustCompile(`%(?:(\d+)\$)?[-+ #0']*(\d+|\*(?:\d+\$)?)?(?:\.(\d+|\*(?:\d+\$)?)?)?(hh|h|ll|l|L|q|j|z|Z|t)?([diouxXeEfFgGaAcCsSpn%])`)
+
+// cConversions maps the C conversions to the type of their argument.
+var cConversions = map[byte]string{
+	'd': "d", 'i': "d",
+	'o': "u", 'u': "u", 'x': "u", 'X': "u",
+	'e': "f", 'E': "f", 'f': "f", 'F': "f", 'g': "f", 'G': "f", 'a': "f", 'A': "f",
+	'c': "c", 'C': "lc", 's': "s", 'S': "ls", 'p': "p", 'n': "n",
+}
+
+// cDirectives returns the conversion specifications of the C format string,
+// including the int arguments of "*" widths and precisions.
+func cDirectives(s string) []directive {
+	var r []directive
+	var next = 1 // argument of the next unnumbered conversion
+	var star = func(spec, text string) {
+		if !strings.HasPrefix(spec, "*") {
+			return
+		}
+		var arg = next
+		if n, err := strconv.Atoi(strings.TrimSuffix(spec[1:], "$")); err == nil {
+			arg = n
+		} else {
+			next++
+		}
+		r = append(r, directive{arg, "d", text})
+	}
+	for _, m := range cFormatRe.FindAllStringSubmatch(s, -1) {
+		if m[5] == "%" {
+			continue
+		}
+		star(m[2], m[0])
+		star(m[3], m[0])
+		var arg = next
+		if n, err := strconv.Atoi(m[1]); err == nil {
+			arg = n
+		} else {
+			next++
+		}
+		r = append(r, directive{arg, m[4] + cConversions[m[5][0]], m[0]})
+	}
+	return r
+}
+
+// goVerbs maps the fmt verbs to the type of their argument. The verbs of
+// several types are compatible with them, and %v and %T with any type.
+var goVerbs = map[byte]string{
+	'v': "v", 'T': "v",
+	'd': "d", 'b': "d", 'o': "d", 'O': "d", 'c': "d", 'U': "d",
+	'x': "x", 'X': "x",
+	's': "s", 'q': "s",
+	'e': "f", 'E': "f", 'f': "f", 'F': "f", 'g': "f", 'G': "f",
+	't': "t", 'p': "p",
+}
+
+// goDirectives returns the verbs of the Go format string, with the argument
+// indexes, e.g. "%[2]d", and the int arguments of "*" widths and
+// precisions, as interpreted by fmt.
+func goDirectives(s string) []directive {
+	var r []directive
+	var next = 1 // argument of the next verb
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		var start, first = i, len(r)
+		i++
+		for i < len(s) && strings.IndexByte("+-# 0", s[i]) >= 0 {
+			i++
+		}
+		var index = func() {
+			if i < len(s) && s[i] == '[' {
+				if j := strings.IndexByte(s[i:], ']'); j > 0 {
+					if n, err := strconv.Atoi(s[i+1 : i+j]); err == nil && n > 0 {
+						next = n
+					}
+					i += j + 1
+				}
+			}
+		}
+		var star = func() {
+			index()
+			if i < len(s) && s[i] == '*' {
+				r = append(r, directive{arg: next, conv: "d"})
+				next++
+				i++
+			}
+			for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+				i++
+			}
+		}
+		star()
+		if i < len(s) && s[i] == '.' {
+			i++
+			star()
+		}
+		index()
+		if i == len(s) || s[i] == '%' {
+			continue
+		}
+		var conv, known = goVerbs[s[i]]
+		if !known {
+			conv = string(s[i])
+		}
+		r = append(r, directive{arg: next, conv: conv})
+		next++
+		for k := first; k < len(r); k++ {
+			r[k].text = s[start : i+1]
+		}
+	}
+	return r
+}
+
+// sameGoType returns true if the types of fmt verbs are compatible.
+func sameGoType(a, b string) bool {
+	return a == b || a == "v" || b == "v" ||
+		a == "x" && (b == "d" || b == "s") || b == "x" && (a == "d" || a == "s")
+}
+
+// formats maps the format flags checked to the parser of their format
+// strings, and the comparison of the types of their arguments.
+var formats = map[string]struct {
+	directives func(string) []directive
+	same       func(a, b string) bool
+}{
+	"c-format":  {cDirectives, nil},
+	"go-format": {goDirectives, sameGoType},
+}
+
+// compareDirectives describes the differences between the conversions of
+// the source string and of its translation, named str. If lenient, as for
+// plural forms, the translation may leave out some arguments. The types of
+// the arguments are compared with same, if not nil.
+func compareDirectives(src, dst []directive, str string, lenient bool, same func(a, b string) bool) []string {
+	if same == nil {
+		same = func(a, b string) bool { return a == b }
+	}
+	var byArg = func(ds []directive) map[int]directive {
+		var m = make(map[int]directive, len(ds))
+		for _, d := range ds {
+			if _, found := m[d.arg]; !found {
+				m[d.arg] = d
+			}
+		}
+		return m
+	}
+	var want, got = byArg(src), byArg(dst)
+	var args []int
+	for arg := range want {
+		args = append(args, arg)
+	}
+	for arg := range got {
+		if _, found := want[arg]; !found {
+			args = append(args, arg)
+		}
+	}
+	sort.Ints(args)
+	var r []string
+	for _, arg := range args {
+		var w, inSrc = want[arg]
+		var g, inDst = got[arg]
+		switch {
+		case !inDst && !lenient:
+			r = append(r, fmt.Sprintf("a format specification for argument %d, %s, doesn't exist in %s", arg, w.text, str))
+		case !inSrc:
+			r = append(r, fmt.Sprintf("a format specification for argument %d, %s, in %s doesn't exist in the msgid", arg, g.text, str))
+		case inDst && !same(w.conv, g.conv):
+			r = append(r, fmt.Sprintf("format specifications in the msgid and %s for argument %d are not the same: %s and %s", str, arg, w.text, g.text))
+		}
+	}
+	return r
+}
+
+// strName returns the name of the i-th msgstr of the message, e.g. "msgstr"
+// or "msgstr[1]".
+func strName(m *Message, i int) string {
+	if m.IdPlural == "" {
+		return "msgstr"
+	}
+	return fmt.Sprintf("msgstr[%d]", i)
+}
+
+// formatRule checks the conversion specifications of the translations of C
+// and Go format strings against those of their msgid, or msgid_plural, like
+// msgfmt --check-format. Plural forms may leave out arguments.
+var formatRule = NewRule("format", Error, func(c *RuleContext) {
+	if c.Msg.HasFlag("fuzzy") {
+		return
+	}
+	var src = c.Msg.Id
+	if c.Msg.IdPlural != "" {
+		src = c.Msg.IdPlural
+	}
+	for _, flag := range c.Msg.Flags {
+		var format, found = formats[flag]
+		if !found {
+			continue
+		}
+		var want = format.directives(src)
+		for i, str := range c.Msg.Str {
+			if str == "" {
+				continue
+			}
+			for _, text := range compareDirectives(want, format.directives(str), strName(c.Msg, i), c.Msg.IdPlural != "", format.same) {
+				c.Report("%s", text)
+			}
+		}
+	}
+})
@@ -0,0 +1,275 @@
+//go:build ignore
+
+// gen_plurals generates plurals_cldr.go, the Plural-Forms of the CLDR
+// languages, from the CLDR supplemental plurals.xml:
+//
+//	go run gen_plurals.go [-src plurals.xml] [-out plurals_cldr.go]
+//
+// The source may be a file or a URL. The rules are evaluated for integers
+// only, as gettext does, so that the categories used only by fractions, e.g.
+// "other" in Russian, or by the compact decimal notation, e.g. "many" in
+// Spanish for "1M", have no plural form.
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	src = flag.String("src", "https://raw.githubusercontent.com/unicode-org/cldr/main/common/supplemental/plurals.xml", "CLDR plurals.xml file or URL")
+	out = flag.String("out", "plurals_cldr.go", "output file")
+)
+
+type supplementalData struct {
+	Plurals []struct {
+		Type  string `xml:"type,attr"`
+		Rules []struct {
+			Locales string `xml:"locales,attr"`
+			Rules   []struct {
+				Count string `xml:"count,attr"`
+				Text  string `xml:",chardata"`
+			} `xml:"pluralRule"`
+		} `xml:"pluralRules"`
+	} `xml:"plurals"`
+}
+
+func main() {
+	flag.Parse()
+	var data, err = read(*src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var sd supplementalData
+	if err := xml.Unmarshal(data, &sd); err != nil {
+		log.Fatal(err)
+	}
+	var forms = make(map[string]string)
+	for _, plurals := range sd.Plurals {
+		if plurals.Type != "cardinal" {
+			continue
+		}
+		for _, rules := range plurals.Rules {
+			var conds []string
+			for _, rule := range rules.Rules {
+				var text, samples, _ = strings.Cut(rule.Text, "@")
+				if !strings.Contains("@"+samples, "@integer") {
+					continue // used by fractions only
+				}
+				if compact(text) {
+					continue // used by the compact decimal notation
+				}
+				var cond, err = condition(strings.TrimSpace(text))
+				if err != nil {
+					log.Fatalf("%s: %s: %v", rules.Locales, rule.Count, err)
+				}
+				conds = append(conds, cond)
+			}
+			var pluralForms = pluralForms(conds)
+			for _, locale := range strings.Fields(rules.Locales) {
+				if locale != "root" {
+					forms[locale] = pluralForms
+				}
+			}
+		}
+	}
+	var locales []string
+	for locale := range forms {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gen_plurals.go from the CLDR plural rules; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package po\n\n")
+	fmt.Fprintf(&b, "// cldrPluralForms maps the CLDR locales to the Plural-Forms of their plural\n")
+	fmt.Fprintf(&b, "// rules for integers, with the forms in the order of the CLDR categories.\n")
+	fmt.Fprintf(&b, "var cldrPluralForms = map[string]string{\n")
+	for _, locale := range locales {
+		fmt.Fprintf(&b, "\t%q: %q,\n", locale, forms[locale])
+	}
+	fmt.Fprintf(&b, "}\n")
+	var code, ferr = format.Source(b.Bytes())
+	if ferr != nil {
+		log.Fatal(ferr)
+	}
+	if err := os.WriteFile(*out, code, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func read(src string) ([]byte, error) {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		return os.ReadFile(src)
+	}
+	var resp, err = http.Get(src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", src, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// pluralForms returns the Plural-Forms of the conditions of the categories,
+// the last of which is used for the numbers matching no other.
+func pluralForms(conds []string) string {
+	if len(conds) <= 1 {
+		return "nplurals=1; plural=0;"
+	}
+	var expr string
+	for i, cond := range conds[:len(conds)-1] {
+		expr += fmt.Sprintf("%s ? %d : ", cond, i)
+	}
+	return fmt.Sprintf("nplurals=%d; plural=%s%d;", len(conds), expr, len(conds)-1)
+}
+
+// The constant conditions.
+const (
+	always = "1"
+	never  = "0"
+)
+
+// condition translates a CLDR rule condition, e.g.
+// "v = 0 and i % 10 = 1 and i % 100 != 11", into a C expression of n, for
+// integers n. Operands other than n and i are 0 for integers, and their
+// relations are evaluated.
+func condition(text string) (string, error) {
+	if text == "" {
+		return always, nil
+	}
+	var ors []string
+	for _, or := range strings.Split(text, " or ") {
+		var ands []string
+		var and = always
+		for _, rel := range strings.Split(or, " and ") {
+			var cond, err = relation(strings.TrimSpace(rel))
+			if err != nil {
+				return "", err
+			}
+			if cond == never {
+				and = never
+				break
+			}
+			if cond != always {
+				ands = append(ands, cond)
+			}
+		}
+		if and == never {
+			continue
+		}
+		if len(ands) == 0 {
+			return always, nil
+		}
+		ors = append(ors, strings.Join(ands, " && "))
+	}
+	if len(ors) == 0 {
+		return never, nil
+	}
+	if len(ors) == 1 {
+		return ors[0], nil
+	}
+	return "(" + strings.Join(ors, " || ") + ")", nil
+}
+
+// compact returns true if the rule condition has a relation on the exponent
+// of the compact decimal notation, e.g. "e = 0 and i % 1000000 = 0".
+func compact(text string) bool {
+	for _, or := range strings.Split(text, " or ") {
+		for _, rel := range strings.Split(or, " and ") {
+			if fields := strings.Fields(rel); len(fields) > 0 && (fields[0] == "e" || fields[0] == "c") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// relation translates a relation, e.g. "n % 100 != 12..14".
+func relation(text string) (string, error) {
+	var op = "="
+	var lhs, rhs, found = strings.Cut(text, "!=")
+	if found {
+		op = "!="
+	} else if lhs, rhs, found = strings.Cut(text, "="); !found {
+		return "", fmt.Errorf("bad relation %q", text)
+	}
+	var operand, mod, hasMod = strings.Cut(strings.TrimSpace(lhs), "%")
+	operand = strings.TrimSpace(operand)
+	var m int
+	if hasMod {
+		var err error
+		if m, err = strconv.Atoi(strings.TrimSpace(mod)); err != nil {
+			return "", fmt.Errorf("bad modulus in %q", text)
+		}
+	}
+	type span struct{ min, max int }
+	var spans []span
+	for _, r := range strings.Split(rhs, ",") {
+		var lo, hi, isRange = strings.Cut(strings.TrimSpace(r), "..")
+		var min, err = strconv.Atoi(lo)
+		if err != nil {
+			return "", fmt.Errorf("bad range in %q", text)
+		}
+		var max = min
+		if isRange {
+			if max, err = strconv.Atoi(hi); err != nil {
+				return "", fmt.Errorf("bad range in %q", text)
+			}
+		}
+		spans = append(spans, span{min, max})
+	}
+	switch operand {
+	case "n", "i":
+	case "v", "w", "f", "t", "c", "e":
+		// 0 for integers.
+		var in = false
+		for _, s := range spans {
+			in = in || s.min <= 0 && 0 <= s.max
+		}
+		if in == (op == "=") {
+			return always, nil
+		}
+		return never, nil
+	default:
+		return "", fmt.Errorf("unknown operand in %q", text)
+	}
+	var x = "n"
+	if hasMod {
+		x = fmt.Sprintf("n%%%d", m)
+	}
+	var terms []string
+	for _, s := range spans {
+		switch {
+		case s.min == s.max && op == "=":
+			terms = append(terms, fmt.Sprintf("%s==%d", x, s.min))
+		case s.min == s.max:
+			terms = append(terms, fmt.Sprintf("%s!=%d", x, s.min))
+		case op == "=":
+			terms = append(terms, fmt.Sprintf("%s>=%d && %s<=%d", x, s.min, x, s.max))
+		default:
+			terms = append(terms, fmt.Sprintf("(%s<%d || %s>%d)", x, s.min, x, s.max))
+		}
+	}
+	if op == "!=" {
+		if len(terms) == 1 {
+			return terms[0], nil
+		}
+		return "(" + strings.Join(terms, " && ") + ")", nil
+	}
+	if len(terms) == 1 && !strings.Contains(terms[0], "&&") {
+		return terms[0], nil
+	}
+	return "(" + strings.Join(terms, " || ") + ")", nil
+}
@@ -0,0 +1,352 @@
+package po
+
+import (
+	"fmt"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Contact is a person or team named in the header, e.g. the Last-Translator
+// "Marcel Telka <marcel@telka.sk>".
+type Contact struct {
+	Name    string
+	Address string // email address or URL
+}
+
+// ParseContact parses a contact in the "Name <address>" form. The name or
+// the address may be missing.
+func ParseContact(s string) Contact {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, ">") {
+		if i := strings.LastIndex(s, "<"); i != -1 {
+			return Contact{strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1 : len(s)-1])}
+		}
+	}
+	if !strings.ContainsAny(s, " \t") && (strings.Contains(s, "@") || strings.Contains(s, "://")) {
+		return Contact{Address: s}
+	}
+	return Contact{Name: s}
+}
+
+// String formats the contact in the "Name <address>" form.
+func (c Contact) String() string {
+	switch {
+	case c.Address == "":
+		return c.Name
+	case c.Name == "":
+		return "<" + c.Address + ">"
+	}
+	return c.Name + " <" + c.Address + ">"
+}
+
+// LastTranslator returns the contact in the Last-Translator header.
+func (f *File) LastTranslator() Contact {
+	return ParseContact(f.Header.Get("Last-Translator"))
+}
+
+// SetLastTranslator sets the Last-Translator header.
+func (f *File) SetLastTranslator(c Contact) {
+	f.setHeader("Last-Translator", c.String())
+}
+
+// LanguageTeam returns the contact in the Language-Team header.
+func (f *File) LanguageTeam() Contact {
+	return ParseContact(f.Header.Get("Language-Team"))
+}
+
+// SetLanguageTeam sets the Language-Team header.
+func (f *File) SetLanguageTeam(c Contact) {
+	f.setHeader("Language-Team", c.String())
+}
+
+func (f *File) setHeader(key, value string) {
+	if f.Header == nil {
+		f.Header = make(map[string][]string)
+	}
+	f.Header.Set(key, value)
+}
+
+// DateLayout is the layout of the dates in the header, e.g.
+// "2014-05-10 18:15+0200".
+const DateLayout = "2006-01-02 15:04-0700"
+
+// CreationDate returns the time in the POT-Creation-Date header.
+func (f *File) CreationDate() (time.Time, error) {
+	return f.headerDate("POT-Creation-Date")
+}
+
+// SetCreationDate sets the POT-Creation-Date header.
+func (f *File) SetCreationDate(t time.Time) {
+	f.setHeader("POT-Creation-Date", t.Format(DateLayout))
+}
+
+// RevisionDate returns the time in the PO-Revision-Date header.
+func (f *File) RevisionDate() (time.Time, error) {
+	return f.headerDate("PO-Revision-Date")
+}
+
+// SetRevisionDate sets the PO-Revision-Date header.
+func (f *File) SetRevisionDate(t time.Time) {
+	f.setHeader("PO-Revision-Date", t.Format(DateLayout))
+}
+
+func (f *File) headerDate(key string) (time.Time, error) {
+	var v = strings.TrimSpace(f.Header.Get(key))
+	if v == "" {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrNoHeader, key)
+	}
+	var t, err = time.Parse(DateLayout, v)
+	if err != nil {
+		// Some tools include the seconds.
+		if t, err2 := time.Parse("2006-01-02 15:04:05-0700", v); err2 == nil {
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("%w: invalid %s %q", ErrBadHeader, key, v)
+	}
+	return t, nil
+}
+
+// SchemaHeader declares the version of the application a catalog was
+// generated for.
+const SchemaHeader = "X-Catalog-Schema"
+
+// SchemaVersion returns the version in the X-Catalog-Schema header.
+func (f *File) SchemaVersion() string {
+	return strings.TrimSpace(f.Header.Get(SchemaHeader))
+}
+
+// SetSchemaVersion sets the X-Catalog-Schema header.
+func (f *File) SetSchemaVersion(version string) {
+	f.setHeader(SchemaHeader, version)
+}
+
+// Header holds the standard fields of the header of a file, e.g. to fill
+// them in at once with SetHeaderFields. The fields of the zero value are
+// missing from the header.
+type Header struct {
+	ProjectIdVersion        string // e.g. "hello 1.0"
+	ReportMsgidBugsTo       string
+	POTCreationDate         time.Time
+	PORevisionDate          time.Time
+	LastTranslator          Contact
+	LanguageTeam            Contact
+	Language                string // e.g. "sk"
+	MIMEVersion             string // "1.0"
+	ContentType             string // e.g. "text/plain; charset=UTF-8"
+	ContentTransferEncoding string // "8bit"
+	PluralForms             string // e.g. "nplurals=2; plural=(n != 1);"
+}
+
+// HeaderFields returns the standard fields of the header. Dates which fail
+// to parse are zero.
+func (f *File) HeaderFields() Header {
+	var h = Header{
+		ProjectIdVersion:        f.Header.Get("Project-Id-Version"),
+		ReportMsgidBugsTo:       f.Header.Get("Report-Msgid-Bugs-To"),
+		LastTranslator:          f.LastTranslator(),
+		LanguageTeam:            f.LanguageTeam(),
+		Language:                f.Header.Get("Language"),
+		MIMEVersion:             f.Header.Get("MIME-Version"),
+		ContentType:             f.Header.Get("Content-Type"),
+		ContentTransferEncoding: f.Header.Get("Content-Transfer-Encoding"),
+		PluralForms:             f.Header.Get("Plural-Forms"),
+	}
+	h.POTCreationDate, _ = f.CreationDate()
+	h.PORevisionDate, _ = f.RevisionDate()
+	return h
+}
+
+// SetHeaderFields sets the standard fields of the header, removing those
+// which are zero in h, and updates Pluralize for the plural forms of the
+// file. Other fields are kept.
+func (f *File) SetHeaderFields(h Header) error {
+	var pluralize PluralSelector
+	if h.PluralForms != "" {
+		var err error
+		if pluralize, err = CompilePluralForms(h.PluralForms); err != nil {
+			return err
+		}
+	} else {
+		pluralize = PluralSelectorForLanguage(h.Language)
+	}
+	var date = func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(DateLayout)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"Project-Id-Version", h.ProjectIdVersion},
+		{"Report-Msgid-Bugs-To", h.ReportMsgidBugsTo},
+		{"POT-Creation-Date", date(h.POTCreationDate)},
+		{"PO-Revision-Date", date(h.PORevisionDate)},
+		{"Last-Translator", h.LastTranslator.String()},
+		{"Language-Team", h.LanguageTeam.String()},
+		{"Language", h.Language},
+		{"MIME-Version", h.MIMEVersion},
+		{"Content-Type", h.ContentType},
+		{"Content-Transfer-Encoding", h.ContentTransferEncoding},
+		{"Plural-Forms", h.PluralForms},
+	} {
+		if field.value == "" {
+			f.Header.Del(field.name)
+		} else {
+			f.setHeader(field.name, field.value)
+		}
+	}
+	f.Pluralize = pluralize
+	return nil
+}
+
+// Language returns the Language header.
+func (f *File) Language() string {
+	return strings.TrimSpace(f.Header.Get("Language"))
+}
+
+// SetLanguage sets the Language header, and Pluralize for the language
+// unless the file declares Plural-Forms.
+func (f *File) SetLanguage(lang string) {
+	f.setHeader("Language", lang)
+	if f.Header.Get("Plural-Forms") == "" {
+		f.Pluralize = PluralSelectorForLanguage(lang)
+	}
+}
+
+// PluralForms returns the Plural-Forms header.
+func (f *File) PluralForms() string {
+	return strings.TrimSpace(f.Header.Get("Plural-Forms"))
+}
+
+// SetPluralForms sets the Plural-Forms header, and Pluralize for it.
+func (f *File) SetPluralForms(pluralForms string) error {
+	var pluralize, err = CompilePluralForms(pluralForms)
+	if err != nil {
+		return err
+	}
+	f.setHeader("Plural-Forms", pluralForms)
+	f.Pluralize = pluralize
+	return nil
+}
+
+// NewFile returns an empty file for the language, e.g. "sk", with the
+// header of a new translation filled in as msginit does: UTF-8 content, the
+// revision date of now, and the plural forms of the language, if known. The
+// header fields keep the conventional order when written with HeaderOriginal
+// or HeaderGNU.
+func NewFile(lang string) *File {
+	var f = &File{headerOrder: append([]string(nil), gnuHeaderFields...)}
+	f.SetHeaderFields(Header{
+		ProjectIdVersion:        "PACKAGE VERSION",
+		PORevisionDate:          time.Now(),
+		Language:                lang,
+		MIMEVersion:             "1.0",
+		ContentType:             "text/plain; charset=UTF-8",
+		ContentTransferEncoding: "8bit",
+		PluralForms:             languagePluralForms(lang),
+	})
+	if f.Pluralize == nil {
+		f.Pluralize = pluralNeq1
+	}
+	return f
+}
+
+// HeaderOrder selects the order header fields are written in.
+type HeaderOrder int
+
+const (
+	// HeaderAlphabetical sorts the fields by name.
+	HeaderAlphabetical HeaderOrder = iota
+	// HeaderOriginal keeps the fields in the order and spelling they were
+	// parsed in, followed by the added ones, sorted.
+	HeaderOriginal
+	// HeaderGNU writes the standard fields in the order and spelling of the
+	// GNU tools, followed by the others, sorted, with X- fields last.
+	HeaderGNU
+)
+
+// gnuHeaderFields lists the standard header fields in the order of the GNU
+// tools.
+var gnuHeaderFields = []string{
+	"Project-Id-Version",
+	"Report-Msgid-Bugs-To",
+	"POT-Creation-Date",
+	"PO-Revision-Date",
+	"Last-Translator",
+	"Language-Team",
+	"Language",
+	"MIME-Version",
+	"Content-Type",
+	"Content-Transfer-Encoding",
+	"Plural-Forms",
+}
+
+// headerNames returns the names of the fields of header, spelled as they are
+// to be written, in the given order.
+func (f *File) headerNames(header textproto.MIMEHeader, order HeaderOrder) []string {
+	var names []string
+	var done = make(map[string]bool, len(header))
+	var add = func(name string) {
+		var k = textproto.CanonicalMIMEHeaderKey(name)
+		if _, found := header[k]; found && !done[k] {
+			names = append(names, name)
+			done[k] = true
+		}
+	}
+	switch order {
+	case HeaderOriginal:
+		for _, name := range f.headerOrder {
+			add(name)
+		}
+	case HeaderGNU:
+		for _, name := range gnuHeaderFields {
+			add(name)
+		}
+	}
+	var rest []string
+	for k := range header {
+		if !done[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		if order == HeaderGNU {
+			var xi, xj = strings.HasPrefix(rest[i], "X-"), strings.HasPrefix(rest[j], "X-")
+			if xi != xj {
+				return xj
+			}
+		}
+		return rest[i] < rest[j]
+	})
+	for _, k := range rest {
+		if order != HeaderAlphabetical {
+			k = f.spelling(k)
+		}
+		add(k)
+	}
+	return names
+}
+
+// spelling returns the name of the header field as it was parsed, or the
+// canonical key if it was not.
+func (f *File) spelling(k string) string {
+	for _, name := range f.headerOrder {
+		if textproto.CanonicalMIMEHeaderKey(name) == k {
+			return name
+		}
+	}
+	return k
+}
+
+// headerOrderOf returns the names of the fields of the header, as they
+// appear in it.
+func headerOrderOf(header string) []string {
+	var names []string
+	for _, line := range strings.Split(header, "\n") {
+		if i := strings.Index(line, ":"); i > 0 {
+			names = append(names, strings.TrimSpace(line[:i]))
+		}
+	}
+	return names
+}
@@ -0,0 +1,173 @@
+package po
+
+import (
+	"bytes"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseContact(t *testing.T) {
+	var tests = []struct {
+		in       string
+		expected Contact
+		out      string
+	}{
+		{"Marcel Telka <marcel@telka.sk>", Contact{"Marcel Telka", "marcel@telka.sk"}, ""},
+		{"Slovak <https://l10n.example.com/sk/>", Contact{"Slovak", "https://l10n.example.com/sk/"}, ""},
+		{"<marcel@telka.sk>", Contact{"", "marcel@telka.sk"}, ""},
+		{"marcel@telka.sk", Contact{"", "marcel@telka.sk"}, "<marcel@telka.sk>"},
+		{"Slovak", Contact{"Slovak", ""}, ""},
+		{" Marcel  <marcel@telka.sk> ", Contact{"Marcel", "marcel@telka.sk"}, "Marcel <marcel@telka.sk>"},
+	}
+	for _, test := range tests {
+		var actual = ParseContact(test.in)
+		if actual != test.expected {
+			t.Errorf("%q: expected %+v, got %+v", test.in, test.expected, actual)
+		}
+		var out = test.out
+		if out == "" {
+			out = test.in
+		}
+		if actual.String() != out {
+			t.Errorf("%q: expected %q, got %q", test.in, out, actual.String())
+		}
+	}
+}
+
+func TestLastTranslator(t *testing.T) {
+	var f File
+	f.SetLastTranslator(Contact{"Marcel Telka", "marcel@telka.sk"})
+	if v := f.Header.Get("Last-Translator"); v != "Marcel Telka <marcel@telka.sk>" {
+		t.Errorf("unexpected header %q", v)
+	}
+	if c := f.LastTranslator(); c.Name != "Marcel Telka" {
+		t.Errorf("unexpected contact %+v", c)
+	}
+}
+
+func TestHeaderDates(t *testing.T) {
+	var f File
+	var when = time.Date(2014, 5, 10, 18, 15, 0, 0, time.FixedZone("", 2*60*60))
+	f.SetRevisionDate(when)
+	if v := f.Header.Get("PO-Revision-Date"); v != "2014-05-10 18:15+0200" {
+		t.Errorf("unexpected header %q", v)
+	}
+	if actual, err := f.RevisionDate(); err != nil || !actual.Equal(when) {
+		t.Errorf("expected %v, got %v (%v)", when, actual, err)
+	}
+
+	f.Header.Set("POT-Creation-Date", "YEAR-MO-DA HO:MI+ZONE")
+	if _, err := f.CreationDate(); err == nil {
+		t.Error("expected an error for a template date")
+	}
+	f.Header.Set("POT-Creation-Date", "2014-05-10 18:15:30+0200")
+	if actual, err := f.CreationDate(); err != nil || !actual.Equal(when.Add(30*time.Second)) {
+		t.Errorf("expected %v, got %v (%v)", when, actual, err)
+	}
+}
+
+func TestHeaderOrder(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"X-Generator: Poedit 3.0\n"
+"Language: sk\n"
+"PO-Revision-Date: 2014-05-10 18:15+0200\n"
+"Project-Id-Version: hello 1.0\n"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Header.Set("Mime-Version", "1.0")
+	var tests = []struct {
+		order    HeaderOrder
+		expected []string
+	}{
+		{HeaderAlphabetical, []string{"Language", "Mime-Version", "Po-Revision-Date", "Project-Id-Version", "X-Generator"}},
+		{HeaderOriginal, []string{"X-Generator", "Language", "PO-Revision-Date", "Project-Id-Version", "Mime-Version"}},
+		{HeaderGNU, []string{"Project-Id-Version", "PO-Revision-Date", "Language", "MIME-Version", "X-Generator"}},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		NewEncoder(&buf, WriteOptions{HeaderOrder: test.order}).Encode(f)
+		var names []string
+		for _, line := range strings.Split(buf.String(), "\n")[2:] {
+			if line == "" {
+				break
+			}
+			names = append(names, line[1:strings.Index(line, ":")])
+		}
+		if !reflect.DeepEqual(test.expected, names) {
+			t.Errorf("%d: expected %v, got %v", test.order, test.expected, names)
+		}
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	var f = NewFile("sk")
+	var when = time.Date(2014, 5, 10, 18, 15, 0, 0, time.FixedZone("", 2*60*60))
+	f.SetRevisionDate(when)
+	f.SetLastTranslator(Contact{"Marcel Telka", "marcel@telka.sk"})
+	var buf bytes.Buffer
+	NewEncoder(&buf, WriteOptions{HeaderOrder: HeaderOriginal}).Encode(f)
+	var expected = `
+msgid ""
+msgstr ""
+"Project-Id-Version: PACKAGE VERSION\n"
+"PO-Revision-Date: 2014-05-10 18:15+0200\n"
+"Last-Translator: Marcel Telka <marcel@telka.sk>\n"
+"Language: sk\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+`[1:]
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, buf.String())
+	}
+	if actual := f.Pluralize(3); actual != 1 {
+		t.Errorf("expected plural form 1, got %d", actual)
+	}
+	if f.Language() != "sk" || f.PluralForms() == "" {
+		t.Errorf("unexpected language %q or plural forms %q", f.Language(), f.PluralForms())
+	}
+}
+
+func TestHeaderFields(t *testing.T) {
+	var f File
+	var when = time.Date(2014, 5, 10, 18, 15, 0, 0, time.FixedZone("", 2*60*60))
+	f.Header = textproto.MIMEHeader{"X-Generator": {"Poedit 3.0"}, "Language-Team": {"Slovak"}}
+	var h = Header{
+		ProjectIdVersion: "hello 1.0",
+		PORevisionDate:   when,
+		Language:         "ru",
+		ContentType:      "text/plain; charset=UTF-8",
+	}
+	if err := f.SetHeaderFields(h); err != nil {
+		t.Fatal(err)
+	}
+	if actual := f.HeaderFields(); !reflect.DeepEqual(h, actual) {
+		t.Errorf("expected %+v, got %+v", h, actual)
+	}
+	if f.Header.Get("X-Generator") == "" || f.Header.Get("Language-Team") != "" {
+		t.Errorf("unexpected header %v", f.Header)
+	}
+	if actual := f.Pluralize(5); actual != 2 {
+		t.Errorf("expected plural form 2, got %d", actual)
+	}
+	h.PluralForms = "nplurals=2; plural=n>"
+	if err := f.SetHeaderFields(h); err == nil {
+		t.Error("expected an error for invalid plural forms")
+	}
+	if err := f.SetPluralForms("nplurals=2; plural=(n > 1);"); err != nil || f.Pluralize(1) != 0 || f.Pluralize(2) != 1 {
+		t.Errorf("unexpected plural forms %q (%v)", f.PluralForms(), err)
+	}
+	f.SetLanguage("cs")
+	if actual := f.Pluralize(2); actual != 1 {
+		t.Errorf("expected the declared plural forms, got form %d", actual)
+	}
+}
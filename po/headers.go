@@ -0,0 +1,72 @@
+package po
+
+import (
+	"net/http"
+	"strings"
+)
+
+// LanguageHeaders sets the headers of localized HTTP responses, so that they
+// are cached correctly by CDNs and proxies.
+type LanguageHeaders struct {
+	// Cookie is the name of the cookie the locale is negotiated with, if any.
+	Cookie string
+	// ContentLanguage, if set, returns the Content-Language of a locale,
+	// instead of its language tag, e.g. "pt-BR" for "pt_BR.UTF-8", or
+	// "sr-Latn-RS" for "sr_RS@latin".
+	ContentLanguage func(locale string) string
+	// Vary lists the headers the locale is negotiated with, in addition to
+	// Accept-Language and Cookie.
+	Vary []string
+}
+
+// Set sets Content-Language to the locale, unless "", and appends the headers the
+// locale is negotiated with to Vary.
+func (h LanguageHeaders) Set(header http.Header, locale string) {
+	var lang = contentLanguage(locale)
+	if h.ContentLanguage != nil && locale != "" {
+		lang = h.ContentLanguage(locale)
+	}
+	if lang != "" {
+		header.Set("Content-Language", lang)
+	}
+	var vary = append([]string{"Accept-Language"}, h.Vary...)
+	if h.Cookie != "" {
+		vary = append(vary, "Cookie")
+	}
+	for _, name := range vary {
+		addVary(header, name)
+	}
+}
+
+// SetLanguageHeaders sets the headers of a response localized for the
+// locale, negotiated with the Accept-Language header.
+func SetLanguageHeaders(header http.Header, locale string) {
+	LanguageHeaders{}.Set(header, locale)
+}
+
+// contentLanguage returns the BCP 47 tag of a gettext locale: without its
+// charset, with hyphens, and with the script named by its modifier, if any.
+func contentLanguage(locale string) string {
+	var tag = strings.Replace(pluralLocale(locale), "_", "-", -1)
+	var _, modifier, _ = strings.Cut(locale, "@")
+	if script := modifierScripts[strings.ToLower(modifier)]; script != "" {
+		var lang, region, found = strings.Cut(tag, "-")
+		tag = lang + "-" + strings.ToUpper(script[:1]) + script[1:]
+		if found {
+			tag += "-" + region
+		}
+	}
+	return tag
+}
+
+// addVary appends name to the Vary header, unless already listed.
+func addVary(header http.Header, name string) {
+	for _, v := range header.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			if field = strings.TrimSpace(field); field == "*" || strings.EqualFold(field, name) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", name)
+}
@@ -0,0 +1,38 @@
+package po
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLanguageHeaders(t *testing.T) {
+	var tests = []struct {
+		headers  LanguageHeaders
+		vary     []string // present before
+		locale   string
+		lang     string
+		expected []string
+	}{
+		{LanguageHeaders{}, nil, "pt_BR", "pt-BR", []string{"Accept-Language"}},
+		{LanguageHeaders{Cookie: "lang"}, []string{"Accept-Encoding, accept-language"}, "de", "de",
+			[]string{"Accept-Encoding, accept-language", "Cookie"}},
+		{LanguageHeaders{Vary: []string{"X-Locale"}}, []string{"*"}, "sk", "sk", []string{"*"}},
+		{LanguageHeaders{ContentLanguage: strings.ToUpper}, nil, "sk", "SK", []string{"Accept-Language"}},
+		{LanguageHeaders{}, nil, "pt_BR.UTF-8", "pt-BR", []string{"Accept-Language"}},
+		{LanguageHeaders{}, nil, "sr_RS@latin", "sr-Latn-RS", []string{"Accept-Language"}},
+		{LanguageHeaders{}, nil, "sr@latin", "sr-Latn", []string{"Accept-Language"}},
+		{LanguageHeaders{}, nil, "de_DE@euro", "de-DE", []string{"Accept-Language"}},
+	}
+	for _, test := range tests {
+		var header = http.Header{}
+		for _, v := range test.vary {
+			header.Add("Vary", v)
+		}
+		test.headers.Set(header, test.locale)
+		if header.Get("Content-Language") != test.lang || !reflect.DeepEqual(test.expected, header.Values("Vary")) {
+			t.Errorf("%s: unexpected headers %v", test.locale, header)
+		}
+	}
+}
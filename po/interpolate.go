@@ -0,0 +1,51 @@
+package po
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// namedRe matches the named placeholders of Interpolate, "{name}" and
+// "${name}".
+var namedRe = regexp.MustCompile(`\$?\{([A-Za-z_][A-Za-z0-9_.]*)\}`)
+
+// Interpolate replaces the named placeholders "{name}" and "${name}" of str
+// with the values of data, formatted with fmt.Sprint. Unlike fmt verbs, named
+// placeholders may be reordered by translators. Placeholders missing from
+// data are left as they are.
+func Interpolate(str string, data map[string]interface{}) string {
+	return namedRe.ReplaceAllStringFunc(str, func(p string) string {
+		var name = namedRe.FindStringSubmatch(p)[1]
+		if v, found := data[name]; found {
+			return fmt.Sprint(v)
+		}
+		return p
+	})
+}
+
+// GetTextData is like GetText, with the named placeholders of the
+// translation, e.g. "{name}", replaced by Interpolate.
+func (f *File) GetTextData(id string, data map[string]interface{}) string {
+	return f.PGetTextData("", id, data)
+}
+
+// PGetTextData is like GetTextData, for the message in the given context.
+func (f *File) PGetTextData(ctxt, id string, data map[string]interface{}) string {
+	return Interpolate(f.getByIds(ctxt, id, 0).text(0, id), data)
+}
+
+// NGetTextData is like NGetText, with the named placeholders of the
+// translation replaced by Interpolate.
+func (f *File) NGetTextData(id, idPlural string, n int, data map[string]interface{}) string {
+	return f.NPGetTextData("", id, idPlural, n, data)
+}
+
+// NPGetTextData is like NGetTextData, for the message in the given context.
+func (f *File) NPGetTextData(ctxt, id, idPlural string, n int, data map[string]interface{}) string {
+	var str = id
+	if n != 1 {
+		str = idPlural
+	}
+	var i = f.pluralize(n)
+	return Interpolate(f.getByIds(ctxt, id, i).text(i, str), data)
+}
@@ -0,0 +1,69 @@
+package po
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	var data = map[string]interface{}{"name": "Eva", "count": 3, "user.city": "Brno"}
+	var tests = []struct {
+		str, expected string
+	}{
+		{"Hello, {name}!", "Hello, Eva!"},
+		{"${count} new messages for ${name}", "3 new messages for Eva"},
+		{"{name} from {user.city}", "Eva from Brno"},
+		{"{missing} and {name}", "{missing} and Eva"},
+		{"{ name } {} {1}", "{ name } {} {1}"},
+		{"100% {name}", "100% Eva"},
+	}
+	for _, test := range tests {
+		if actual := Interpolate(test.str, data); actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.str, test.expected, actual)
+		}
+	}
+}
+
+func TestGetTextData(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Language: sk\n"
+
+msgid "{user} shared {file}"
+msgstr "Súbor {file} zdieľal {user}"
+
+msgctxt "mail"
+msgid "{count} message"
+msgid_plural "{count} messages"
+msgstr[0] "{count} správa"
+msgstr[1] "{count} správy"
+msgstr[2] "${count} správ"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data = map[string]interface{}{"user": "Eva", "file": "a.txt"}
+	if actual, expected := f.GetTextData("{user} shared {file}", data), "Súbor a.txt zdieľal Eva"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if actual, expected := f.GetTextData("{user} left", data), "Eva left"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	var tests = []struct {
+		ctxt     string
+		n        int
+		expected string
+	}{
+		{"mail", 1, "1 správa"},
+		{"mail", 3, "3 správy"},
+		{"mail", 5, "5 správ"},
+		{"", 5, "5 messages"},
+	}
+	for _, test := range tests {
+		var data = map[string]interface{}{"count": test.n}
+		if actual := f.NPGetTextData(test.ctxt, "{count} message", "{count} messages", test.n, data); actual != test.expected {
+			t.Errorf("%q %d: expected %q, got %q", test.ctxt, test.n, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package po
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// jsonFile is the JSON representation of a file, for web frontends:
+//
+//	{
+//	  "header": {"Language": "sk", "Plural-Forms": "nplurals=3; plural=..."},
+//	  "nplurals": 3,
+//	  "plural": "(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2",
+//	  "messages": [
+//	    {"msgctxt": "menu", "msgid": "Open", "msgstr": ["Otvoriť"]},
+//	    {"msgid": "one egg", "msgid_plural": "%d eggs", "msgstr": ["...", "...", "..."]}
+//	  ]
+//	}
+//
+// plural is the C expression of the plural form index of n, from the
+// Plural-Forms header, or implied by the language, and is omitted if unknown.
+// Repeated header fields keep their first value only.
+type jsonFile struct {
+	Header   map[string]string `json:"header,omitempty"`
+	NPlurals int               `json:"nplurals,omitempty"`
+	Plural   string            `json:"plural,omitempty"`
+	Messages []jsonMessage     `json:"messages"`
+}
+
+// jsonMessage is the JSON representation of a message. Of the comments, only
+// the translator and extracted comments, references and flags are kept.
+type jsonMessage struct {
+	Comments   []string `json:"comments,omitempty"`
+	Extracted  []string `json:"extracted,omitempty"`
+	References []string `json:"references,omitempty"`
+	Flags      []string `json:"flags,omitempty"`
+	Ctxt       string   `json:"msgctxt,omitempty"`
+	Id         string   `json:"msgid"`
+	IdPlural   string   `json:"msgid_plural,omitempty"`
+	Str        []string `json:"msgstr"`
+	Obsolete   bool     `json:"obsolete,omitempty"`
+}
+
+// MarshalJSON encodes the file as a JSON object with its header, plural rule
+// and messages.
+func (f *File) MarshalJSON() ([]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var r = jsonFile{Messages: make([]jsonMessage, 0, len(f.Messages))}
+	if len(f.Header) > 0 {
+		r.Header = make(map[string]string, len(f.Header))
+		for k := range f.Header {
+			r.Header[f.spelling(k)] = f.Header.Get(k)
+		}
+	}
+	if nplurals, expr, err := splitPluralForms(f.pluralForms()); err == nil {
+		r.NPlurals, r.Plural = nplurals, strings.TrimSpace(expr)
+	}
+	for _, msg := range f.Messages {
+		var str = msg.Str
+		if str == nil {
+			str = []string{}
+		}
+		r.Messages = append(r.Messages, jsonMessage{
+			Comments:   msg.TranslatorComments,
+			Extracted:  msg.ExtractedComments,
+			References: referenceStrings(msg.References),
+			Flags:      msg.Flags,
+			Ctxt:       msg.Ctxt,
+			Id:         msg.Id,
+			IdPlural:   msg.IdPlural,
+			Str:        str,
+			Obsolete:   msg.Obsolete,
+		})
+	}
+	return json.Marshal(r)
+}
+
+// UnmarshalJSON decodes a file encoded by MarshalJSON, replacing the header
+// and messages. The plural rule is used when the header has no Plural-Forms.
+func (f *File) UnmarshalJSON(data []byte) error {
+	var r jsonFile
+	if err := json.Unmarshal(data, &r); err != nil {
+		return err
+	}
+	var header textproto.MIMEHeader
+	var headerOrder []string
+	if len(r.Header) > 0 {
+		header = make(textproto.MIMEHeader, len(r.Header))
+		for name, value := range r.Header {
+			header.Set(name, value)
+			headerOrder = append(headerOrder, name)
+		}
+		sort.Strings(headerOrder)
+	}
+	var pluralize, err = headerPluralSelector(header)
+	if err != nil {
+		return err
+	}
+	if header.Get("Plural-Forms") == "" && r.Plural != "" {
+		if pluralize, err = CompilePluralForms(fmt.Sprintf("nplurals=%d; plural=%s;", r.NPlurals, r.Plural)); err != nil {
+			return err
+		}
+	}
+	var msgs = make([]*Message, len(r.Messages))
+	for i, m := range r.Messages {
+		msgs[i] = &Message{
+			Comment: Comment{
+				TranslatorComments: m.Comments,
+				ExtractedComments:  m.Extracted,
+				References:         parseReferences(m.References),
+				Flags:              m.Flags,
+			},
+			Ctxt:     m.Ctxt,
+			Id:       m.Id,
+			IdPlural: m.IdPlural,
+			Str:      m.Str,
+			Obsolete: m.Obsolete,
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Header, f.Messages, f.Pluralize, f.headerOrder = header, msgs, pluralize, headerOrder
+	f.lines, f.comments = nil, nil
+	f.reindexLocked()
+	return nil
+}
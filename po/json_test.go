@@ -0,0 +1,58 @@
+package po
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	var f, err = Parse(strings.NewReader(po))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r struct {
+		Header   map[string]string
+		NPlurals int
+		Plural   string
+		Messages []map[string]interface{}
+	}
+	if err = json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Header["Language"] != "sk" || r.NPlurals != 3 || r.Plural != "(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2" {
+		t.Errorf("unexpected header or plural rule in %s", data)
+	}
+	if len(r.Messages) != len(f.Messages) || r.Messages[0]["msgid"] != f.Messages[0].Id {
+		t.Errorf("unexpected messages in %s", data)
+	}
+
+	var decoded File
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Header, f.Header) || len(decoded.Messages) != len(f.Messages) {
+		t.Fatalf("expected the file to round-trip, got %v %v", decoded.Header, decoded.Messages)
+	}
+	for i, msg := range f.Messages {
+		if !reflect.DeepEqual(decoded.Messages[i].Str, msg.Str) || decoded.Messages[i].Ctxt != msg.Ctxt {
+			t.Errorf("expected %q to round-trip, got %v", msg.Id, decoded.Messages[i])
+		}
+	}
+	var msg = f.Messages[len(f.Messages)-1]
+	if actual, expected := decoded.NPGetText(msg.Ctxt, msg.Id, msg.IdPlural, 3), f.NPGetText(msg.Ctxt, msg.Id, msg.IdPlural, 3); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	if err = json.Unmarshal([]byte(`{"nplurals":2,"plural":"n>1","messages":[{"msgid":"egg","msgid_plural":"eggs","msgstr":["vajce","vajcia"]}]}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if actual := decoded.NGetText("egg", "eggs", 1); actual != "vajce" {
+		t.Errorf("expected the plural rule to be used, got %q", actual)
+	}
+}
@@ -0,0 +1,19 @@
+package po
+
+// Key identifies a message, e.g. a variable generated by pomsg -keys, so that
+// lookups of removed or misspelled messages fail to compile rather than
+// falling back to the source string.
+type Key struct {
+	Ctxt, Id, IdPlural string
+}
+
+// GetTextKey translates the message of the key, like PGetText.
+func (f *File) GetTextKey(key Key, data ...interface{}) string {
+	return f.PGetText(key.Ctxt, key.Id, data...)
+}
+
+// NGetTextKey translates the message of the key in the plural form of n,
+// like NPGetText.
+func (f *File) NGetTextKey(key Key, n int, data ...interface{}) string {
+	return f.NPGetText(key.Ctxt, key.Id, key.IdPlural, n, data...)
+}
@@ -0,0 +1,45 @@
+package po
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetTextKey(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgctxt "menu"
+msgid "Open"
+msgstr "Otvoriť"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d súbor"
+msgstr[1] "%d súbory"
+msgstr[2] "%d súborov"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Pluralize = func(n int) int {
+		switch {
+		case n == 1:
+			return 0
+		case n >= 2 && n <= 4:
+			return 1
+		}
+		return 2
+	}
+	var (
+		open = Key{Ctxt: "menu", Id: "Open"}
+		file = Key{Id: "%d file", IdPlural: "%d files"}
+	)
+	if actual := f.GetTextKey(open); actual != "Otvoriť" {
+		t.Errorf("expected %q, got %q", "Otvoriť", actual)
+	}
+	if actual := f.NGetTextKey(file, 3, 3); actual != "3 súbory" {
+		t.Errorf("expected %q, got %q", "3 súbory", actual)
+	}
+	if actual := f.GetTextKey(Key{Id: "Open"}); actual != "Open" {
+		t.Errorf("expected %q, got %q", "Open", actual)
+	}
+}
@@ -0,0 +1,218 @@
+package po
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Severity indicates how serious a problem is.
+type Severity int
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Rule is a QA check run against every message of a file.
+type Rule interface {
+	ID() string         // unique identifier, e.g. "punctuation"
+	Severity() Severity // severity of the problems reported by the rule
+	Check(c *RuleContext)
+}
+
+// RuleContext is passed to a Rule for each message it checks.
+type RuleContext struct {
+	File *File
+	Msg  *Message
+	Lang string // value of the Language header
+
+	rule     Rule
+	problems []Problem
+}
+
+// Report records a problem with the current message.
+func (c *RuleContext) Report(format string, args ...interface{}) {
+	c.problems = append(c.problems, Problem{
+		Rule:     c.rule.ID(),
+		Severity: c.rule.Severity(),
+		Msg:      c.Msg,
+		Line:     c.File.Line(c.Msg),
+		Text:     fmt.Sprintf(format, args...),
+	})
+}
+
+// NewRule returns a Rule that calls fn for each message.
+func NewRule(id string, severity Severity, fn func(c *RuleContext)) Rule {
+	return funcRule{id, severity, fn}
+}
+
+type funcRule struct {
+	id       string
+	severity Severity
+	fn       func(c *RuleContext)
+}
+
+func (r funcRule) ID() string           { return r.id }
+func (r funcRule) Severity() Severity   { return r.severity }
+func (r funcRule) Check(c *RuleContext) { r.fn(c) }
+
+var (
+	rulesMu sync.RWMutex
+	rules   = make(map[string]Rule)
+)
+
+// RegisterRule makes a rule available to NewLinter under its ID.
+// It panics if a rule with the same ID is already registered.
+func RegisterRule(r Rule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	if _, dup := rules[r.ID()]; dup {
+		panic("po: RegisterRule called twice for rule " + r.ID())
+	}
+	rules[r.ID()] = r
+}
+
+// Rules returns the registered rules, sorted by ID.
+func Rules() []Rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	var r = make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		r = append(r, rule)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].ID() < r[j].ID() })
+	return r
+}
+
+// Linter runs a set of rules against files.
+type Linter struct {
+	Rules []Rule
+}
+
+// NewLinter returns a linter running the registered rules with the given IDs,
+// or all registered rules if none are given.
+func NewLinter(ids ...string) (*Linter, error) {
+	if len(ids) == 0 {
+		return &Linter{Rules()}, nil
+	}
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	var l = &Linter{}
+	for _, id := range ids {
+		rule, found := rules[id]
+		if !found {
+			return nil, fmt.Errorf("po: unknown rule %q", id)
+		}
+		l.Rules = append(l.Rules, rule)
+	}
+	return l, nil
+}
+
+// Lint runs the linter's rules against each message of the file and returns
+// the problems found, in message order.
+//
+// Rules may be suppressed for a message with a flag or a comment naming them,
+// e.g. "#, ignore-check: punctuation quotes". Suppressions of unknown rules,
+// and of rules that reported nothing, are reported as well.
+func (l *Linter) Lint(f *File) []Problem {
+	var c = RuleContext{File: f, Lang: f.Header.Get("Language")}
+	for _, msg := range f.Messages {
+		c.Msg = msg
+		var start = len(c.problems)
+		for _, rule := range l.Rules {
+			c.rule = rule
+			rule.Check(&c)
+		}
+		if ignored := msg.Ignored(); len(ignored) > 0 {
+			c.problems = append(c.problems[:start], l.suppress(&c, c.problems[start:], ignored)...)
+		}
+	}
+	return c.problems
+}
+
+// suppress removes the problems reported by ignored rules, and reports the
+// suppressions that are not valid.
+func (l *Linter) suppress(c *RuleContext, problems []Problem, ignored []string) []Problem {
+	var used = make(map[string]bool)
+	var kept []Problem
+	for _, p := range problems {
+		if contains(ignored, p.Rule) {
+			used[p.Rule] = true
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	var ran = make(map[string]bool)
+	for _, rule := range l.Rules {
+		ran[rule.ID()] = true
+	}
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	for _, id := range ignored {
+		var p = Problem{Rule: "ignore-check", Severity: Warning, Msg: c.Msg, Line: c.File.Line(c.Msg)}
+		switch {
+		case rules[id] == nil && !ran[id]:
+			p.Text = "suppression of unknown rule " + id
+		case ran[id] && !used[id]:
+			p.Severity, p.Text = Info, "unnecessary suppression of rule "+id
+		default:
+			continue
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// IgnoreCheck starts the flags and comments suppressing lint rules.
+const IgnoreCheck = "ignore-check:"
+
+// Ignored returns the IDs of the lint rules suppressed by the comment's flags,
+// translator comments and extracted comments.
+func (c Comment) Ignored() []string {
+	var ids []string
+	for _, lines := range [][]string{c.Flags, c.TranslatorComments, c.ExtractedComments} {
+		for _, line := range lines {
+			if strings.HasPrefix(line, IgnoreCheck) {
+				ids = append(ids, strings.Fields(line[len(IgnoreCheck):])...)
+			}
+		}
+	}
+	return ids
+}
+
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	RegisterRule(identicalRule)
+	RegisterRule(maxLengthRule)
+	RegisterRule(msgstrIndexRule)
+	RegisterRule(pluralFormsRule)
+	RegisterRule(flagsRule)
+	RegisterRule(formatRule)
+	RegisterRule(newlinesRule)
+	for _, id := range typographyRules {
+		RegisterRule(newTypographyRule(id))
+	}
+}
@@ -0,0 +1,272 @@
+package po
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoadDomain loads the catalogs of a domain from a directory, as
+// bindtextdomain does: <root>/<locale>/LC_MESSAGES/<domain>.mo or .po,
+// preferring the compiled one. Catalogs in the flat <root>/<locale>.po or
+// .mo layout of a single domain are loaded as well, for the locales missing
+// from the other.
+func LoadDomain(root, domain string) (*Bundle, error) {
+	var b, err = LoadDomainFSWithOptions(os.DirFS(root), domain, LoadOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", root, err)
+	}
+	return b, nil
+}
+
+// LoadDomainFS is like LoadDomain, for the root directory of fsys, e.g. an
+// embed.FS, or a subdirectory of it given by fs.Sub.
+func LoadDomainFS(fsys fs.FS, domain string) (*Bundle, error) {
+	return LoadDomainFSWithOptions(fsys, domain, LoadOptions{})
+}
+
+// LoadOptions controls how catalogs are loaded by LoadDomainFSWithOptions
+// and LoadDirFSWithOptions.
+type LoadOptions struct {
+	// Logger, if set, receives a record of each catalog parsed, as with
+	// ParseOptions.Logger, and is the logger of the bundles returned.
+	Logger *slog.Logger
+}
+
+// LoadDomainFSWithOptions is like LoadDomainFS, with the given options. The
+// catalogs of a directory are loaded with os.DirFS(root).
+func LoadDomainFSWithOptions(fsys fs.FS, domain string, opts LoadOptions) (*Bundle, error) {
+	return loadDomainFS(fsys, domain, true, opts)
+}
+
+// loadDomainFS loads the catalogs of the domain, and those of the flat
+// layout if flat is set.
+func loadDomainFS(fsys fs.FS, domain string, flat bool, opts LoadOptions) (*Bundle, error) {
+	var b = NewBundle()
+	b.SetLogger(opts.Logger)
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		var locale = entry.Name()
+		for _, ext := range []string{".mo", ".po"} {
+			var name = path.Join(locale, "LC_MESSAGES", domain+ext)
+			if f, err := loadFileFS(fsys, name, opts); err == nil {
+				b.Add(locale, f)
+				break
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+	for _, entry := range entries {
+		var ext = path.Ext(entry.Name())
+		var locale = strings.TrimSuffix(entry.Name(), ext)
+		if !flat || entry.IsDir() || (ext != ".po" && ext != ".mo") || b.File(locale) != nil {
+			continue
+		}
+		f, err := loadFileFS(fsys, entry.Name(), opts)
+		if err != nil {
+			return nil, err
+		}
+		b.Add(locale, f)
+	}
+	return b, nil
+}
+
+// LoadDir loads the catalogs of every domain of a directory in the
+// <root>/<locale>/LC_MESSAGES/<domain>.mo or .po layout, by domain. Catalogs
+// in the flat layout, which belong to no domain in particular, are ignored.
+func LoadDir(root string) (map[string]*Bundle, error) {
+	var r, err = LoadDirFSWithOptions(os.DirFS(root), LoadOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", root, err)
+	}
+	return r, nil
+}
+
+// LoadDirFS is like LoadDir, for the root directory of fsys.
+func LoadDirFS(fsys fs.FS) (map[string]*Bundle, error) {
+	return LoadDirFSWithOptions(fsys, LoadOptions{})
+}
+
+// LoadDirFSWithOptions is like LoadDirFS, with the given options.
+func LoadDirFSWithOptions(fsys fs.FS, opts LoadOptions) (map[string]*Bundle, error) {
+	names, err := fs.Glob(fsys, "*/LC_MESSAGES/*.[mp]o")
+	if err != nil {
+		return nil, err
+	}
+	var r = make(map[string]*Bundle)
+	for _, name := range names {
+		var domain = strings.TrimSuffix(path.Base(name), path.Ext(name))
+		if r[domain] != nil {
+			continue
+		}
+		if r[domain], err = loadDomainFS(fsys, domain, false, opts); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// LoadFile parses a PO or MO file, telling them apart by their contents.
+func LoadFile(name string) (*File, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return loadData(name, data, LoadOptions{})
+}
+
+// LoadFileFS is like LoadFile, for a file of fsys.
+func LoadFileFS(fsys fs.FS, name string) (*File, error) {
+	return loadFileFS(fsys, name, LoadOptions{})
+}
+
+func loadFileFS(fsys fs.FS, name string, opts LoadOptions) (*File, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return loadData(name, data, opts)
+}
+
+func loadData(name string, data []byte, opts LoadOptions) (*File, error) {
+	var f *File
+	var err error
+	if IsMO(data) {
+		var start = time.Now()
+		f, err = ParseMO(bytes.NewReader(data))
+		if opts.Logger != nil {
+			var attrs = []slog.Attr{
+				slog.String("file", name),
+				slog.Int("bytes", len(data)),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				opts.Logger.LogAttrs(context.Background(), slog.LevelError, "po: parse failed", append(attrs, slog.Any("error", err))...)
+			} else {
+				opts.Logger.LogAttrs(context.Background(), slog.LevelInfo, "po: parsed", append(attrs, slog.Int("messages", len(f.Messages)))...)
+			}
+		}
+	} else {
+		f, err = ParseWithOptions(bytes.NewReader(data), ParseOptions{Logger: opts.Logger, Name: name})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return f, nil
+}
+
+// ParseFS loads the catalogs of fsys matching the pattern, in the syntax of
+// fs.Glob, e.g. "locales/*.po" or "locales/*/LC_MESSAGES/app.[mp]o", as
+// template.ParseFS does. The locale of a catalog is the name of its file
+// without the extension, or that of the directory containing its
+// LC_MESSAGES directory. MO files are preferred to PO files of the same
+// locale.
+func ParseFS(fsys fs.FS, pattern string) (*Bundle, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("po: pattern matches no files: %#q", pattern)
+	}
+	var b = NewBundle()
+	for _, name := range names {
+		var locale = strings.TrimSuffix(path.Base(name), path.Ext(name))
+		if dir := path.Dir(name); path.Base(dir) == "LC_MESSAGES" {
+			locale = path.Base(path.Dir(dir))
+		}
+		if f := b.File(locale); f != nil && path.Ext(name) != ".mo" {
+			continue
+		}
+		f, err := LoadFileFS(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		b.Add(locale, f)
+	}
+	return b, nil
+}
+
+// ParseAll parses the catalogs read from the readers, by name, with up to
+// parallelism of them at once, or GOMAXPROCS if parallelism is not positive,
+// e.g. to load the locales of a server at startup. The errors of all the
+// catalogs failing are returned joined, prefixed with their names. Canceling
+// ctx stops the parsing, returning ctx.Err().
+func ParseAll(ctx context.Context, readers map[string]io.Reader, parallelism int) (map[string]*File, error) {
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	var names = make([]string, 0, len(readers))
+	for name := range readers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var files = make([]*File, len(names))
+	var errs = make([]error, len(names))
+	var sem = make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			var f, err = Parse(&contextReader{ctx, readers[name]})
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+			}
+			files[i] = f
+		}(i, name)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	var r = make(map[string]*File, len(names))
+	for i, name := range names {
+		r[name] = files[i]
+	}
+	return r, nil
+}
+
+// contextReader fails reading once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
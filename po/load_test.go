@@ -0,0 +1,176 @@
+package po
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadDir(t *testing.T) {
+	var root = t.TempDir()
+	var write = func(name string, data []byte) {
+		name = filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, data, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var catalog = func(str string) []byte {
+		return []byte("msgid \"Open\"\nmsgstr \"" + str + "\"\n")
+	}
+	write("sk/LC_MESSAGES/app.po", catalog("Otvoriť (po)"))
+	write("sk/LC_MESSAGES/app.mo", buildMO(binary.LittleEndian, "Open", "Otvoriť"))
+	write("de/LC_MESSAGES/app.po", catalog("Öffnen"))
+	write("de/LC_MESSAGES/billing.po", catalog("Öffnen (billing)"))
+	write("fr.po", catalog("Ouvrir"))
+	write("de.po", catalog("Öffnen (flat)"))
+
+	domains, err := LoadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 2 {
+		t.Fatalf("expected 2 domains, got %v", domains)
+	}
+	var app = domains["app"]
+	if locales := app.Locales(); !reflect.DeepEqual([]string{"de", "sk"}, locales) {
+		t.Errorf("unexpected locales %v", locales)
+	}
+	if locales := domains["billing"].Locales(); !reflect.DeepEqual([]string{"de"}, locales) {
+		t.Errorf("unexpected billing locales %v", locales)
+	}
+	if actual := domains["billing"].GetText("de", "Open"); actual != "Öffnen (billing)" {
+		t.Errorf("unexpected billing translation %q", actual)
+	}
+	if app, err = LoadDomain(root, "app"); err != nil {
+		t.Fatal(err)
+	}
+	for locale, expected := range map[string]string{"sk": "Otvoriť", "de": "Öffnen", "fr": "Ouvrir"} {
+		if actual := app.GetText(locale, "Open"); actual != expected {
+			t.Errorf("%s: expected %q, got %q", locale, expected, actual)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err = LoadDirFSWithOptions(os.DirFS(root), LoadOptions{Logger: slog.New(slog.NewTextHandler(&buf, nil))}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`msg="po: parsed" file=sk/LC_MESSAGES/app.mo`,
+		`msg="po: parsed" file=de/LC_MESSAGES/billing.po`,
+		`msg="po: catalog added" locale=de messages=1`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %s in:\n%s", expected, buf.String())
+		}
+	}
+
+	write("cs/LC_MESSAGES/app.po", []byte("msgid \"Open\nmsgstr \"\"\n"))
+	if _, err := LoadDomain(root, "app"); err == nil {
+		t.Error("expected an error for a malformed catalog")
+	}
+}
+
+func TestParseFS(t *testing.T) {
+	var catalog = func(str string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte("msgid \"Open\"\nmsgstr \"" + str + "\"\n")}
+	}
+	var fsys = fstest.MapFS{
+		"locales/sk/LC_MESSAGES/app.mo": &fstest.MapFile{Data: buildMO(binary.LittleEndian, "Open", "Otvoriť")},
+		"locales/sk/LC_MESSAGES/app.po": catalog("Otvoriť (po)"),
+		"locales/de/LC_MESSAGES/app.po": catalog("Öffnen"),
+		"locales/fr.po":                 catalog("Ouvrir"),
+	}
+
+	b, err := ParseFS(fsys, "locales/*/LC_MESSAGES/app.[mp]o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locales := b.Locales(); !reflect.DeepEqual([]string{"de", "sk"}, locales) {
+		t.Errorf("unexpected locales %v", locales)
+	}
+	if actual := b.GetText("sk", "Open"); actual != "Otvoriť" {
+		t.Errorf("unexpected translation %q", actual)
+	}
+	if b, err = ParseFS(fsys, "locales/*.po"); err != nil || b.GetText("fr", "Open") != "Ouvrir" {
+		t.Errorf("unexpected bundle %v, %v", b.Locales(), err)
+	}
+	if _, err = ParseFS(fsys, "missing/*.po"); err == nil {
+		t.Error("expected an error for no matches")
+	}
+
+	sub, err := fs.Sub(fsys, "locales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	domains, err := LoadDirFS(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locales := domains["app"].Locales(); !reflect.DeepEqual([]string{"de", "sk"}, locales) {
+		t.Errorf("unexpected locales %v", locales)
+	}
+	f, err := DomainLoaderFS(sub, "app")("de")
+	if err != nil || f.GetText("Open") != "Öffnen" {
+		t.Errorf("unexpected catalog %v, %v", f, err)
+	}
+	if _, err := DomainLoaderFS(sub, "app")("../de"); err == nil {
+		t.Error("expected an error for an invalid locale")
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	var readers = func() map[string]io.Reader {
+		var r = make(map[string]io.Reader)
+		for _, locale := range []string{"de", "fr", "sk", "pl"} {
+			r[locale] = strings.NewReader("msgid \"Open\"\nmsgstr \"" + locale + "\"\n")
+		}
+		return r
+	}
+	files, err := ParseAll(context.Background(), readers(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 4 || files["sk"].GetText("Open") != "sk" {
+		t.Errorf("unexpected catalogs %v", files)
+	}
+
+	var bad = readers()
+	bad["fr"] = strings.NewReader("msgid \"Open\"\nmsgstr \"unterminated\n")
+	bad["pl"] = strings.NewReader("msgid \"Open\"\nmsgstr[x] \"\"\n")
+	if _, err = ParseAll(context.Background(), bad, 0); err == nil {
+		t.Fatal("expected an error")
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !strings.HasPrefix(err.Error(), "fr: ") || !strings.Contains(err.Error(), "\npl: ") {
+		t.Errorf("expected the errors of fr and pl, got %v", err)
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	var canceling = readers()
+	canceling["de"] = io.MultiReader(readerFunc(func([]byte) (int, error) {
+		cancel()
+		return 0, nil
+	}), strings.NewReader("msgid \"Open\"\nmsgstr \"de\"\n"))
+	if _, err = ParseAll(ctx, canceling, 1); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (fn readerFunc) Read(p []byte) (int, error) {
+	return fn(p)
+}
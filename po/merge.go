@@ -0,0 +1,215 @@
+package po
+
+import (
+	"sort"
+)
+
+// MergeOrder selects the order of the messages of merged catalogs.
+type MergeOrder int
+
+const (
+	// TemplateOrder orders the messages as in the template, like msgmerge.
+	TemplateOrder MergeOrder = iota
+	// ExistingOrder keeps the order of the existing catalog, with the
+	// messages new in the template last.
+	ExistingOrder
+)
+
+// MergeOptions controls how catalogs are merged with their template.
+type MergeOptions struct {
+	Order MergeOrder
+	// FuzzyScore is the minimum similarity of the msgids of fuzzy matches,
+	// DefaultFuzzyScore if 0. Fuzzy matching is disabled by values above 1.
+	FuzzyScore float64
+}
+
+// DefaultFuzzyScore is the FuzzyScore used by default, close to msgmerge.
+const DefaultFuzzyScore = 0.6
+
+// Merge updates the catalog def against the template ref, like msgmerge, and
+// returns the result. Translations are carried over from the messages of def
+// with the same context and msgid. Messages of ref missing from def take the
+// translation of the most similar msgid, flagged fuzzy, with the previous
+// msgid recorded in "#|" comments, or are left untranslated. Messages of def
+// missing from ref are made obsolete. The comments are those of ref, except
+// the translator comments.
+func Merge(def, ref *File, opts MergeOptions) *File {
+	var r = &File{Header: cloneHeader(def.Header), HeaderComment: def.HeaderComment, Pluralize: def.Pluralize, headerOrder: def.headerOrder}
+	if created := ref.Header.Get("POT-Creation-Date"); created != "" && r.Header != nil {
+		r.Header.Set("POT-Creation-Date", created)
+	}
+	var minScore = opts.FuzzyScore
+	if minScore == 0 {
+		minScore = DefaultFuzzyScore
+	}
+	var nplurals = def.nplurals()
+	var existing = make(map[key]*Message, len(def.Messages))
+	for _, msg := range def.Messages {
+		if _, dup := existing[key{msg.Ctxt, msg.Id}]; !dup {
+			existing[key{msg.Ctxt, msg.Id}] = msg
+		}
+	}
+	var tm = NewTM(def)
+	var used = make(map[*Message]bool)
+	for _, t := range ref.Messages {
+		if t.Obsolete {
+			continue
+		}
+		var m = &Message{Comment: t.Comment, Ctxt: t.Ctxt, Id: t.Id, IdPlural: t.IdPlural}
+		m.TranslatorComments = nil
+		m.Flags = append([]string(nil), t.Flags...)
+		m.PrevCtxt, m.PrevId, m.PrevIdPlural = "", "", ""
+		var old = existing[key{t.Ctxt, t.Id}]
+		var fuzzy bool
+		if old == nil && minScore <= 1 {
+			for _, match := range tm.Lookup(t.Id, minScore) {
+				if !used[match.Message] {
+					old, fuzzy = match.Message, true
+					break
+				}
+			}
+		}
+		if old != nil {
+			used[old] = true
+			m.TranslatorComments = old.TranslatorComments
+			m.Str = append([]string(nil), old.Str...)
+			if fuzzy || old.HasFlag("fuzzy") || old.IdPlural != t.IdPlural {
+				fuzzy = true
+				if old.HasFlag("fuzzy") && old.PrevId != "" {
+					m.PrevCtxt, m.PrevId, m.PrevIdPlural = old.PrevCtxt, old.PrevId, old.PrevIdPlural
+				} else if old.Ctxt != t.Ctxt || old.Id != t.Id || old.IdPlural != t.IdPlural {
+					m.PrevCtxt, m.PrevId, m.PrevIdPlural = quoted(old.Ctxt), quoted(old.Id), quoted(old.IdPlural)
+				}
+			}
+		}
+		m.Str = resize(m.Str, t.IdPlural != "", nplurals)
+		if fuzzy && !m.HasFlag("fuzzy") && !m.isUntranslated() {
+			m.Flags = append([]string{"fuzzy"}, m.Flags...)
+		}
+		r.Messages = append(r.Messages, m)
+	}
+	for _, msg := range def.Messages {
+		if !used[msg] && existing[key{msg.Ctxt, msg.Id}] == msg {
+			var m = *msg
+			m.Obsolete = true
+			r.Messages = append(r.Messages, &m)
+		}
+	}
+	if opts.Order == ExistingOrder {
+		r.ReorderAs(def)
+	}
+	sort.SliceStable(r.Messages, func(i, j int) bool {
+		return !r.Messages[i].Obsolete && r.Messages[j].Obsolete
+	})
+	r.reindex()
+	return r
+}
+
+// Update sets the translations, translator comments and flags of the
+// messages of the file from those of src with the same context and msgid,
+// e.g. parsed from a spreadsheet, and returns the number of messages
+// changed. Changed messages are replaced by copies. Messages of src missing
+// from the file, or singular where it has a plural one or vice versa, are
+// ignored, as are the fields src was parsed without a column for.
+func (f *File) Update(src *File) int {
+	var byKey = make(map[key]*Message, len(src.Messages))
+	for _, msg := range src.Messages {
+		byKey[key{msg.Ctxt, msg.Id}] = msg
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var n int
+	for i, msg := range f.Messages {
+		var s = byKey[key{msg.Ctxt, msg.Id}]
+		if s == nil || msg.Obsolete || (s.IdPlural == "") != (msg.IdPlural == "") {
+			continue
+		}
+		var m = *msg
+		if src.absent&strField == 0 {
+			m.Str, m.StrIndices = s.Str, nil
+		}
+		if src.absent&commentsField == 0 {
+			m.TranslatorComments = s.TranslatorComments
+		}
+		if src.absent&flagsField == 0 {
+			m.Flags = s.Flags
+		}
+		if sameStrings(m.Str, msg.Str) && sameStrings(m.TranslatorComments, msg.TranslatorComments) && sameStrings(m.Flags, msg.Flags) {
+			continue
+		}
+		f.Messages[i] = &m
+		n++
+	}
+	if n > 0 {
+		f.reindexLocked()
+	}
+	return n
+}
+
+// fields selects the fields of the messages set by Update.
+type fields uint8
+
+const (
+	strField fields = 1 << iota
+	commentsField
+	flagsField
+)
+
+// sameStrings returns true if a and b hold the same strings, nil being empty.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// quoted returns the string quoted as in the previous fields of comments, or
+// "" if empty.
+func quoted(s string) string {
+	if s == "" {
+		return ""
+	}
+	return quote(s)
+}
+
+// resize returns the msgstrs resized for a plural message with nplurals
+// forms, or for a singular one.
+func resize(strs []string, plural bool, nplurals int) []string {
+	var n = 1
+	if plural {
+		n = nplurals
+	}
+	if len(strs) == n {
+		return strs
+	}
+	var r = make([]string, n)
+	copy(r, strs)
+	return r
+}
+
+// ReorderAs sorts the messages of the file in the order of the messages of
+// the template t. Messages missing from t are moved last, in their current
+// order.
+func (f *File) ReorderAs(t *File) {
+	var pos = make(map[key]int, len(t.Messages))
+	for i, msg := range t.Messages {
+		if _, dup := pos[key{msg.Ctxt, msg.Id}]; !dup {
+			pos[key{msg.Ctxt, msg.Id}] = i
+		}
+	}
+	var rank = func(msg *Message) int {
+		if i, found := pos[key{msg.Ctxt, msg.Id}]; found {
+			return i
+		}
+		return len(t.Messages)
+	}
+	sort.SliceStable(f.Messages, func(i, j int) bool {
+		return rank(f.Messages[i]) < rank(f.Messages[j])
+	})
+	f.reindex()
+}
@@ -0,0 +1,105 @@
+package po
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReorderAs(t *testing.T) {
+	var template = &File{Messages: []*Message{
+		{Id: "Open"},
+		{Ctxt: "menu", Id: "Quit"},
+		{Id: "Save"},
+	}}
+	var f = &File{Messages: []*Message{
+		{Id: "Obsolete"},
+		{Id: "Save", Str: []string{"Uložiť"}},
+		{Id: "Removed"},
+		{Ctxt: "menu", Id: "Quit", Str: []string{"Koniec"}},
+		{Id: "Open", Str: []string{"Otvoriť"}},
+	}}
+	f.ReorderAs(template)
+	var ids []string
+	for _, msg := range f.Messages {
+		ids = append(ids, msg.Id)
+	}
+	if expected := []string{"Open", "Quit", "Save", "Obsolete", "Removed"}; !reflect.DeepEqual(expected, ids) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+	if f.PGetText("menu", "Quit") != "Koniec" {
+		t.Error("expected the messages to be looked up after reordering")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var def, err = Parse(strings.NewReader(`msgid ""
+msgstr ""
+"Language: sk\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+# Keep it short.
+#: old.go:1
+msgid "Open"
+msgstr "Otvoriť"
+
+msgid "Save the file"
+msgstr "Uložiť súbor"
+
+msgid "Removed"
+msgstr "Odstránené"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Parse(strings.NewReader(`#: main.go:1
+msgid "Open"
+msgstr ""
+
+#: main.go:2
+msgid "Save the files"
+msgstr ""
+
+#: main.go:3
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	Merge(def, ref, MergeOptions{}).WriteTo(&buf)
+	var expected = `msgid ""
+msgstr ""
+"Language: sk\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+#  Keep it short.
+#: main.go:1
+msgid "Open"
+msgstr "Otvoriť"
+
+#: main.go:2
+#, fuzzy
+#| msgid "Save the file"
+msgid "Save the files"
+msgstr "Uložiť súbor"
+
+#: main.go:3
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+
+#~ msgid "Removed"
+#~ msgstr "Odstránené"
+
+`
+	if actual := buf.String(); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
@@ -0,0 +1,232 @@
+package po
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// moMagic starts MO files, in their byte order.
+const moMagic = 0x950412de
+
+// moHeaderSize is the size of the header of MO files, up to the hash table.
+const moHeaderSize = 28
+
+// ParseMO parses a compiled GNU gettext MO file. MO files hold neither
+// comments nor untranslated and fuzzy messages, and sort the messages by
+// msgid.
+func ParseMO(r io.Reader) (*File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < moHeaderSize {
+		return nil, fmt.Errorf("%w: too short", ErrBadMO)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if binary.BigEndian.Uint32(data) == moMagic {
+		order = binary.BigEndian
+	} else if order.Uint32(data) != moMagic {
+		return nil, fmt.Errorf("%w: bad magic number", ErrBadMO)
+	}
+	if major := order.Uint32(data[4:]) >> 16; major > 1 {
+		return nil, fmt.Errorf("%w: unsupported revision %d", ErrBadMO, major)
+	}
+	var n = order.Uint32(data[8:])
+	var ids, strs = order.Uint32(data[12:]), order.Uint32(data[16:])
+	// Each message takes 16 bytes of tables, so that n is bounded by the
+	// size of the file, before allocating for it.
+	if uint64(n) > uint64(len(data)-moHeaderSize)/16 {
+		return nil, fmt.Errorf("%w: %d messages in %d bytes", ErrBadMO, n, len(data))
+	}
+	for _, table := range []uint32{ids, strs} {
+		if uint64(table)+uint64(n)*8 > uint64(len(data)) {
+			return nil, fmt.Errorf("%w: string table out of bounds", ErrBadMO)
+		}
+	}
+
+	// str returns the i-th string of the table at the given offset.
+	var str = func(table, i uint32) (string, error) {
+		var at = uint64(table) + uint64(i)*8
+		if at+8 > uint64(len(data)) {
+			return "", fmt.Errorf("%w: string table out of bounds", ErrBadMO)
+		}
+		var length, offset = uint64(order.Uint32(data[at:])), uint64(order.Uint32(data[at+4:]))
+		if offset+length > uint64(len(data)) {
+			return "", fmt.Errorf("%w: string %d out of bounds", ErrBadMO, i)
+		}
+		return string(data[offset : offset+length]), nil
+	}
+
+	var msgs = make([]*Message, 0, n)
+	var header textproto.MIMEHeader
+	var headerOrder []string
+	for i := uint32(0); i < n; i++ {
+		id, err := str(ids, i)
+		if err != nil {
+			return nil, err
+		}
+		s, err := str(strs, i)
+		if err != nil {
+			return nil, err
+		}
+		if id == "" {
+			if header, headerOrder, err = parseHeader(s); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var msg = &Message{Str: strings.Split(s, "\x00")}
+		if j := strings.IndexByte(id, '\x04'); j != -1 {
+			msg.Ctxt, id = id[:j], id[j+1:]
+		}
+		if j := strings.IndexByte(id, '\x00'); j != -1 {
+			msg.IdPlural, id = id[j+1:], id[:j]
+		}
+		msg.Id = id
+		msgs = append(msgs, msg)
+	}
+
+	pluralize, err := headerPluralSelector(header)
+	if err != nil {
+		return nil, err
+	}
+	var f = &File{Header: header, Messages: msgs, Pluralize: pluralize, headerOrder: headerOrder}
+	f.reindex()
+	return f, nil
+}
+
+// IsMO returns true if data starts like an MO file, in either byte order.
+func IsMO(data []byte) bool {
+	return len(data) >= 4 && (binary.LittleEndian.Uint32(data) == moMagic || binary.BigEndian.Uint32(data) == moMagic)
+}
+
+// WriteMO compiles the file to a little-endian MO file, like msgfmt. Only the
+// header and the translated messages that are neither fuzzy nor obsolete are
+// compiled.
+func (f *File) WriteMO(w io.Writer) (int64, error) {
+	return f.WriteMOOrder(w, binary.LittleEndian)
+}
+
+// WriteMOOrder is like WriteMO, in the given byte order.
+func (f *File) WriteMOOrder(w io.Writer, order binary.ByteOrder) (int64, error) {
+	type entry struct{ id, str string }
+	var entries []entry
+	if len(f.Header) > 0 {
+		var header strings.Builder
+		for _, name := range f.headerNames(f.Header, HeaderOriginal) {
+			for _, v := range f.Header.Values(name) {
+				header.WriteString(name + ": " + v + "\n")
+			}
+		}
+		entries = append(entries, entry{"", header.String()})
+	}
+	var seen = make(map[string]bool)
+	for _, msg := range f.Messages {
+		if msg.Obsolete || msg.HasFlag("fuzzy") || len(msg.Str) == 0 || msg.Str[0] == "" {
+			continue
+		}
+		var id = msg.Id
+		if msg.Ctxt != "" {
+			id = msg.Ctxt + "\x04" + id
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		var str = msg.Str[0]
+		if msg.IdPlural != "" {
+			id += "\x00" + msg.IdPlural
+			str = strings.Join(msg.Str, "\x00")
+		}
+		entries = append(entries, entry{id, str})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].id < entries[j].id })
+
+	var n = uint32(len(entries))
+	var hashSize = moHashSize(n)
+	var idsAt = uint32(moHeaderSize)
+	var strsAt = idsAt + n*8
+	var hashAt = strsAt + n*8
+	var dataAt = hashAt + hashSize*4
+
+	var buf bytes.Buffer
+	var put = func(vals ...uint32) {
+		for _, v := range vals {
+			binary.Write(&buf, order, v)
+		}
+	}
+	put(moMagic, 0, n, idsAt, strsAt, hashSize, hashAt)
+	var offset = dataAt
+	for _, e := range entries {
+		put(uint32(len(e.id)), offset)
+		offset += uint32(len(e.id)) + 1
+	}
+	for _, e := range entries {
+		put(uint32(len(e.str)), offset)
+		offset += uint32(len(e.str)) + 1
+	}
+	var hash = make([]uint32, hashSize)
+	for i, e := range entries {
+		var id = e.id
+		if j := strings.IndexByte(id, '\x00'); j != -1 {
+			id = id[:j]
+		}
+		var h = moHash(id)
+		var idx, incr = h % hashSize, 1 + h%(hashSize-2)
+		for hash[idx] != 0 {
+			if idx >= hashSize-incr {
+				idx -= hashSize - incr
+			} else {
+				idx += incr
+			}
+		}
+		hash[idx] = uint32(i) + 1
+	}
+	put(hash...)
+	for _, e := range entries {
+		buf.WriteString(e.id + "\x00")
+	}
+	for _, e := range entries {
+		buf.WriteString(e.str + "\x00")
+	}
+	return buf.WriteTo(w)
+}
+
+// moHash is the hash function of the hash table of MO files.
+func moHash(s string) uint32 {
+	var h uint32
+	for i := 0; i < len(s); i++ {
+		h = h<<4 + uint32(s[i])
+		if g := h & 0xf0000000; g != 0 {
+			h ^= g >> 24
+			h ^= g
+		}
+	}
+	return h
+}
+
+// moHashSize returns the size of the hash table for n strings, as msgfmt:
+// the smallest odd prime above 4n/3, and at least 3.
+func moHashSize(n uint32) uint32 {
+	var size = n * 4 / 3
+	if size < 3 {
+		return 3
+	}
+	for size |= 1; !isPrime(size); size += 2 {
+	}
+	return size
+}
+
+func isPrime(n uint32) bool {
+	for d := uint32(3); d*d <= n; d += 2 {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package po
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// buildMO returns an MO file of the given msgids and msgstrs, sorted.
+func buildMO(order binary.ByteOrder, entries ...string) []byte {
+	var n = len(entries) / 2
+	var buf bytes.Buffer
+	for _, v := range []uint32{moMagic, 0, uint32(n), moHeaderSize, moHeaderSize + uint32(n)*8, 0, 0} {
+		binary.Write(&buf, order, v)
+	}
+	var offset = moHeaderSize + uint32(n)*16
+	var data bytes.Buffer
+	for table := 0; table < 2; table++ {
+		for i := 0; i < n; i++ {
+			var s = entries[i*2+table]
+			binary.Write(&buf, order, uint32(len(s)))
+			binary.Write(&buf, order, offset+uint32(data.Len()))
+			data.WriteString(s + "\x00")
+		}
+	}
+	buf.Write(data.Bytes())
+	return buf.Bytes()
+}
+
+func TestParseMO(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		var data = buildMO(order,
+			"", "Language: sk\nPlural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n",
+			"%d file\x00%d files", "%d súbor\x00%d súbory\x00%d súborov",
+			"Open", "Otvoriť",
+			"menu\x04Quit", "Koniec",
+		)
+		if !IsMO(data) {
+			t.Error("expected an MO file")
+		}
+		var f, err = ParseMO(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected = []*Message{
+			{Id: "%d file", IdPlural: "%d files", Str: []string{"%d súbor", "%d súbory", "%d súborov"}},
+			{Id: "Open", Str: []string{"Otvoriť"}},
+			{Ctxt: "menu", Id: "Quit", Str: []string{"Koniec"}},
+		}
+		if !reflect.DeepEqual(expected, f.Messages) {
+			t.Errorf("%v: expected %v, got %v", order, expected, f.Messages)
+		}
+		if f.Header.Get("Language") != "sk" || f.NGetText("%d file", "%d files", 3, 3) != "3 súbory" || f.PGetText("menu", "Quit") != "Koniec" {
+			t.Errorf("%v: unexpected header or lookups", order)
+		}
+	}
+}
+
+func TestParseMOErrors(t *testing.T) {
+	var valid = buildMO(binary.LittleEndian, "Open", "Otvoriť")
+	var tests = [][]byte{
+		valid[:10],
+		append([]byte{0, 0, 0, 0}, valid[4:]...),
+		valid[:len(valid)-10],
+		// A count of messages the file cannot hold.
+		append(append(append([]byte(nil), valid[:8]...), 0xf0, 0xff, 0xff, 0xff), valid[12:28]...),
+		// A table past the end of the file.
+		append(append(append([]byte(nil), valid[:12]...), 0xf0, 0xff, 0, 0), valid[16:]...),
+	}
+	for _, data := range tests {
+		if _, err := ParseMO(bytes.NewReader(data)); !errors.Is(err, ErrBadMO) {
+			t.Errorf("expected ErrBadMO, got %v", err)
+		}
+	}
+}
+
+func TestWriteMO(t *testing.T) {
+	var f, err = Parse(strings.NewReader(po + obsoletePo[strings.Index(obsoletePo, "#  "):] + `
+#, fuzzy
+msgid "Save"
+msgstr "Uložiť"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		var buf bytes.Buffer
+		if _, err := f.WriteMOOrder(&buf, order); err != nil {
+			t.Fatal(err)
+		}
+		mo, err := ParseMO(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(f.Header, mo.Header) {
+			t.Errorf("%v: expected header %v, got %v", order, f.Header, mo.Header)
+		}
+		var ids []string
+		for _, msg := range mo.Messages {
+			ids = append(ids, msg.Ctxt+"|"+msg.Id)
+		}
+		if expected := []string{"|ID Line 1\nID Line 2\nID Line 3", "The number of eggs you need.|You have one egg"}; !reflect.DeepEqual(expected, ids) {
+			t.Errorf("%v: expected %q, got %q", order, expected, ids)
+		}
+		for _, msg := range mo.Messages {
+			if err := moLookup(buf.Bytes(), order, msg); err != nil {
+				t.Errorf("%v: %q: %v", order, msg.Id, err)
+			}
+		}
+	}
+}
+
+// moLookup finds the message through the hash table, like libintl.
+func moLookup(data []byte, order binary.ByteOrder, msg *Message) error {
+	var id = msg.Id
+	if msg.Ctxt != "" {
+		id = msg.Ctxt + "\x04" + id
+	}
+	var size, at = order.Uint32(data[20:]), order.Uint32(data[24:])
+	var ids = order.Uint32(data[12:])
+	var h = moHash(id)
+	var idx, incr = h % size, 1 + h%(size-2)
+	for {
+		var i = order.Uint32(data[at+idx*4:])
+		if i == 0 {
+			return fmt.Errorf("not found in the hash table")
+		}
+		var length, offset = order.Uint32(data[ids+(i-1)*8:]), order.Uint32(data[ids+(i-1)*8+4:])
+		if s := string(data[offset : offset+length]); strings.SplitN(s, "\x00", 2)[0] == id {
+			return nil
+		}
+		if idx >= size-incr {
+			idx -= size - incr
+		} else {
+			idx += incr
+		}
+	}
+}
+
+func TestMOHash(t *testing.T) {
+	var tests = []struct {
+		n, size uint32
+	}{
+		{0, 3}, {2, 3}, {3, 5}, {10, 13}, {100, 137},
+	}
+	for _, test := range tests {
+		if actual := moHashSize(test.n); actual != test.size {
+			t.Errorf("%d: expected %d, got %d", test.n, test.size, actual)
+		}
+	}
+	if h := moHash("Open"); h != 0x566be {
+		t.Errorf("unexpected hash %x", h)
+	}
+}
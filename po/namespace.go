@@ -0,0 +1,68 @@
+package po
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ContextSeparator separates the levels of hierarchical contexts, e.g.
+// "menu|file|open".
+const ContextSeparator = "|"
+
+// JoinContext builds a hierarchical context from its levels.
+func JoinContext(levels ...string) string {
+	return strings.Join(levels, ContextSeparator)
+}
+
+// SplitContext returns the levels of a hierarchical context.
+func SplitContext(ctxt string) []string {
+	if ctxt == "" {
+		return nil
+	}
+	return strings.Split(ctxt, ContextSeparator)
+}
+
+// inNamespace returns true if ctxt is ns, or is nested under it.
+func inNamespace(ctxt, ns string) bool {
+	return ns == "" || ctxt == ns || strings.HasPrefix(ctxt, ns+ContextSeparator)
+}
+
+// Namespace returns the messages whose context is ns or nested under it,
+// e.g. "menu|file|open" and "menu|file" are both in the "menu" namespace.
+func (f *File) Namespace(ns string) []*Message {
+	var r []*Message
+	for _, msg := range f.Messages {
+		if inNamespace(msg.Ctxt, ns) {
+			r = append(r, msg)
+		}
+	}
+	return r
+}
+
+// RenameNamespace moves the messages in the namespace from to the namespace
+// to, keeping their nesting, and returns the number of messages renamed. No
+// message is renamed if that would collide with an existing message.
+func (f *File) RenameNamespace(from, to string) (int, error) {
+	if from == "" {
+		return 0, fmt.Errorf("po: cannot rename the root namespace")
+	}
+	type key struct{ ctxt, id, idPlural string }
+	var existing = make(map[key]bool)
+	for _, msg := range f.Messages {
+		if !inNamespace(msg.Ctxt, from) {
+			existing[key{msg.Ctxt, msg.Id, msg.IdPlural}] = true
+		}
+	}
+	var renamed = f.Namespace(from)
+	for _, msg := range renamed {
+		var ctxt = to + msg.Ctxt[len(from):]
+		if existing[key{ctxt, msg.Id, msg.IdPlural}] {
+			return 0, &DuplicateError{ctxt, msg.Id}
+		}
+	}
+	for _, msg := range renamed {
+		msg.Ctxt = to + msg.Ctxt[len(from):]
+	}
+	f.reindex()
+	return len(renamed), nil
+}
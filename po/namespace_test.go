@@ -0,0 +1,46 @@
+package po
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContextLevels(t *testing.T) {
+	var ctxt = JoinContext("menu", "file", "open")
+	if ctxt != "menu|file|open" {
+		t.Errorf("unexpected context %q", ctxt)
+	}
+	if levels := SplitContext(ctxt); !reflect.DeepEqual([]string{"menu", "file", "open"}, levels) {
+		t.Errorf("unexpected levels %v", levels)
+	}
+}
+
+func TestRenameNamespace(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Ctxt: "menu|file|open", Id: "Open"},
+		{Ctxt: "menu|file", Id: "File"},
+		{Ctxt: "menubar", Id: "Menu"},
+		{Ctxt: "toolbar|file", Id: "File"},
+	}}
+	if n := len(f.Namespace("menu")); n != 2 {
+		t.Errorf("expected 2 messages in namespace, got %d", n)
+	}
+	if _, err := f.RenameNamespace("menu", "toolbar"); err == nil {
+		t.Error("expected a collision error")
+	}
+	n, err := f.RenameNamespace("menu", "main|menu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 messages renamed, got %d", n)
+	}
+	var actual []string
+	for _, msg := range f.Messages {
+		actual = append(actual, msg.Ctxt)
+	}
+	var expected = []string{"main|menu|file|open", "main|menu|file", "menubar", "toolbar|file"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
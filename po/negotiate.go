@@ -0,0 +1,147 @@
+package po
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns the language tags of an HTTP Accept-Language
+// header, e.g. "fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5", by decreasing quality,
+// without those of quality 0 and the "*" wildcard.
+func ParseAcceptLanguage(header string) []string {
+	type accepted struct {
+		tag string
+		q   float64
+	}
+	var langs []accepted
+	for _, part := range strings.Split(header, ",") {
+		var tag, params, _ = strings.Cut(part, ";")
+		tag = strings.TrimSpace(tag)
+		var q = 1.0
+		for _, param := range strings.Split(params, ";") {
+			var name, value, _ = strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				var err error
+				if q, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		if tag != "" && tag != "*" && q > 0 {
+			langs = append(langs, accepted{tag, q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	var r = make([]string, len(langs))
+	for i, l := range langs {
+		r[i] = l.tag
+	}
+	return r
+}
+
+// localeParts are the subtags of a locale compared by negotiation, lower
+// case.
+type localeParts struct {
+	lang, script, region string
+}
+
+// modifierScripts maps the gettext modifiers naming a script, as in
+// "sr@latin", to the script subtag.
+var modifierScripts = map[string]string{
+	"latin":      "latn",
+	"cyrillic":   "cyrl",
+	"arabic":     "arab",
+	"devanagari": "deva",
+}
+
+// parseLocale splits a BCP 47 tag, e.g. "zh-Hant-TW", or a gettext locale,
+// e.g. "sr_RS.UTF-8@latin", into its subtags. The script of the languages
+// written in several ones is implied by their region if not given, as
+// "hant" for "zh_TW".
+func parseLocale(locale string) localeParts {
+	locale = strings.ToLower(locale)
+	var p localeParts
+	if i := strings.IndexByte(locale, '@'); i >= 0 {
+		p.script = modifierScripts[locale[i+1:]]
+		locale = locale[:i]
+	}
+	if i := strings.IndexByte(locale, '.'); i >= 0 {
+		locale = locale[:i]
+	}
+	for i, sub := range strings.FieldsFunc(locale, func(c rune) bool { return c == '_' || c == '-' }) {
+		switch {
+		case i == 0:
+			p.lang = sub
+		case len(sub) == 4 && p.script == "" && p.region == "":
+			p.script = sub
+		case (len(sub) == 2 || len(sub) == 3 && sub[0] >= '0' && sub[0] <= '9') && p.region == "":
+			p.region = sub
+		}
+	}
+	if p.script == "" {
+		switch p.lang {
+		case "zh":
+			p.script = "hans"
+			if p.region == "tw" || p.region == "hk" || p.region == "mo" {
+				p.script = "hant"
+			}
+		case "sr":
+			p.script = "cyrl"
+		}
+	}
+	return p
+}
+
+// MatchLocale returns the locale of the catalog that best serves an HTTP
+// Accept-Language header. The languages are tried by decreasing quality,
+// each matching, from the best, the catalog of its locale, of its language
+// and script without the region ("pt" for "pt-BR"), or of another region
+// ("pt_PT" for "pt-BR"). Scripts are implied by the region, as "zh_TW" for
+// "zh-Hant", and named by the modifier, as "sr@latin" for "sr-Latn". The
+// default locale is returned if no language matches, "" if unset.
+func (b *Bundle) MatchLocale(acceptLanguage string) string {
+	var locales = b.Locales()
+	var parts = make([]localeParts, len(locales))
+	for i, locale := range locales {
+		parts[i] = parseLocale(locale)
+	}
+	for _, tag := range ParseAcceptLanguage(acceptLanguage) {
+		var want = parseLocale(tag)
+		var best, bestScore = "", 0
+		for i, p := range parts {
+			if p.lang != want.lang || p.script != want.script {
+				continue
+			}
+			var score = 1
+			switch p.region {
+			case want.region:
+				score = 3
+			case "":
+				score = 2
+			}
+			if score > bestScore {
+				best, bestScore = locales[i], score
+			}
+		}
+		if best != "" {
+			return best
+		}
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.files[b.defaultLocale] == nil {
+		return ""
+	}
+	return b.defaultLocale
+}
+
+// Match returns the catalog that best serves an HTTP Accept-Language header,
+// as chosen by MatchLocale, or nil.
+func (b *Bundle) Match(acceptLanguage string) *File {
+	var locale = b.MatchLocale(acceptLanguage)
+	if locale == "" {
+		return nil
+	}
+	return b.File(locale)
+}
@@ -0,0 +1,56 @@
+package po
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	var actual = ParseAcceptLanguage("fr-CH, en;q=0.8, de;q=0.9 , *;q=0.5, es;q=0, it;q=0.8")
+	var expected = []string{"fr-CH", "de", "en", "it"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+	if actual := ParseAcceptLanguage(""); len(actual) != 0 {
+		t.Errorf("expected no languages, got %q", actual)
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	var b = NewBundle()
+	for _, locale := range []string{"de", "de_AT", "en_GB", "en_US", "pt_PT", "sr", "sr@latin", "zh_CN", "zh_TW"} {
+		b.Add(locale, &File{})
+	}
+	for header, expected := range map[string]string{
+		"de-AT":                   "de_AT",
+		"de-CH":                   "de",
+		"en-US,en;q=0.9":          "en_US",
+		"en-AU":                   "en_GB",
+		"pt-BR":                   "pt_PT",
+		"fr;q=0.9, de;q=0.5":      "de",
+		"fr, ja":                  "",
+		"sr-Latn-RS":              "sr@latin",
+		"sr-RS":                   "sr",
+		"zh-Hant":                 "zh_TW",
+		"zh-HK":                   "zh_TW",
+		"zh":                      "zh_CN",
+		"de;q=0.5, en-GB;q=0.8":   "en_GB",
+		"de;q=0, en-GB;q=invalid": "",
+	} {
+		if actual := b.MatchLocale(header); actual != expected {
+			t.Errorf("%s: expected %q, got %q", header, expected, actual)
+		}
+	}
+
+	b.SetDefault("en_US")
+	if actual := b.MatchLocale("fr, ja"); actual != "en_US" {
+		t.Errorf("expected the default locale, got %q", actual)
+	}
+	if f := b.Match("de-DE"); f != b.File("de") {
+		t.Errorf("unexpected catalog %p", f)
+	}
+	b.SetDefault("fr")
+	if f := b.Match("ja"); f != nil {
+		t.Errorf("expected no catalog, got %p", f)
+	}
+}
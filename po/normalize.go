@@ -0,0 +1,65 @@
+package po
+
+import (
+	"log"
+	"strings"
+	"unicode"
+)
+
+// Normalizer configures the fail-safe lookup mode of a File, in which misses
+// are retried with the msgids normalized: trimmed of trailing whitespace, with
+// CRLF line endings turned into LF, as catalogs edited on Windows often have.
+// It catches invisible differences between the msgids in the source code and
+// in the catalog.
+type Normalizer struct {
+	// NFC normalizes strings to Unicode Normalization Form C, e.g.
+	// norm.NFC.String from golang.org/x/text/unicode/norm. Optional.
+	NFC func(string) string
+	// IgnoreCase also matches msgids differing in case only.
+	IgnoreCase bool
+	// Logf logs the lookups that only succeeded after normalization.
+	// It defaults to log.Printf.
+	Logf func(format string, args ...interface{})
+}
+
+// normalize returns s in NFC, with LF line endings, trimmed of trailing
+// whitespace, and lowercased with IgnoreCase.
+func (n *Normalizer) normalize(s string) string {
+	if n.NFC != nil {
+		s = n.NFC(s)
+	}
+	s = strings.TrimRightFunc(strings.ReplaceAll(s, "\r\n", "\n"), unicode.IsSpace)
+	if n.IgnoreCase {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+func (n *Normalizer) logf(format string, args ...interface{}) {
+	if n.Logf != nil {
+		n.Logf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
+// SetNormalizer enables the fail-safe lookup mode, or disables it if n is nil.
+// It must not be called concurrently with lookups.
+func (f *File) SetNormalizer(n *Normalizer) {
+	f.normalizer = n
+	f.reindex()
+}
+
+// normalizedKey returns the index key of the msgid, normalized.
+func (n *Normalizer) normalizedKey(ctxt, id string) key {
+	return key{ctxt, n.normalize(id)}
+}
+
+// getNormalized looks up the message after normalizing the msgid.
+func (f *File) getNormalized(ctxt, id string) *entry {
+	var e = f.byNormId[f.normalizer.normalizedKey(ctxt, id)]
+	if e != nil {
+		f.normalizer.logf("po: msgid %q only matched %q after normalization", id, e.Id)
+	}
+	return e
+}
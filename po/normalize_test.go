@@ -0,0 +1,61 @@
+package po
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNormalizedLookup(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "Café", Str: []string{"Kaffeehaus"}},
+		{Id: "Name: ", Str: []string{"Name:"}},
+	}}
+	f.reindex()
+	if actual := f.GetText("Cafe\u0301"); actual != "Cafe\u0301" {
+		t.Errorf("expected a miss before normalization, got %q", actual)
+	}
+
+	var logged []string
+	f.SetNormalizer(&Normalizer{
+		NFC: strings.NewReplacer("e\u0301", "é").Replace,
+		Logf: func(format string, args ...interface{}) {
+			logged = append(logged, fmt.Sprintf(format, args...))
+		},
+	})
+	var tests = []struct {
+		id, expected string
+	}{
+		{"Cafe\u0301", "Kaffeehaus"},
+		{"Name:", "Name:"},
+		{"Name:\t", "Name:"},
+		{"Nope", "Nope"},
+	}
+	for _, test := range tests {
+		if actual := f.GetText(test.id); actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.id, test.expected, actual)
+		}
+	}
+	if len(logged) != 3 {
+		t.Errorf("expected 3 mismatches logged, got %v", logged)
+	}
+}
+
+func TestNormalizedLookupLineEndingsAndCase(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "Dear user,\nwelcome!", Str: []string{"Milý používateľ,\nvitajte!"}},
+	}}
+	var n = &Normalizer{Logf: func(string, ...interface{}) {}}
+	f.SetNormalizer(n)
+	if actual := f.GetText("Dear user,\r\nwelcome!\r\n"); actual != "Milý používateľ,\nvitajte!" {
+		t.Errorf("expected a match with CRLF line endings, got %q", actual)
+	}
+	if actual := f.GetText("DEAR USER,\nWELCOME!"); actual != "DEAR USER,\nWELCOME!" {
+		t.Errorf("expected a miss differing in case, got %q", actual)
+	}
+	n.IgnoreCase = true
+	f.SetNormalizer(n)
+	if actual := f.GetText("DEAR USER,\nWELCOME!"); actual != "Milý používateľ,\nvitajte!" {
+		t.Errorf("expected a match ignoring case, got %q", actual)
+	}
+}
@@ -0,0 +1,55 @@
+package po
+
+import (
+	"strings"
+	"time"
+)
+
+// ObsoleteSincePrefix starts the translator comment recording when a message
+// became obsolete, e.g. "# obsolete-since: 2016-01-02 15:04+0000".
+const ObsoleteSincePrefix = "obsolete-since:"
+
+// ObsoleteSince returns when the message became obsolete, if recorded.
+func (c Comment) ObsoleteSince() (time.Time, bool) {
+	for _, line := range c.TranslatorComments {
+		if strings.HasPrefix(line, ObsoleteSincePrefix) {
+			if t, err := time.Parse(DateLayout, strings.TrimSpace(line[len(ObsoleteSincePrefix):])); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// PurgeObsolete removes the messages that have been obsolete for longer than
+// olderThan, and returns how many were removed. Messages without an
+// ObsoleteSince comment are dated by the PO-Revision-Date of the file, and
+// kept if neither is known, unless olderThan is 0.
+func (f *File) PurgeObsolete(olderThan time.Duration) int {
+	return f.purgeObsolete(olderThan, time.Now())
+}
+
+func (f *File) purgeObsolete(olderThan time.Duration, now time.Time) int {
+	var revised, err = f.RevisionDate()
+	var cutoff = now.Add(-olderThan)
+	var kept = f.Messages[:0]
+	for _, msg := range f.Messages {
+		if msg.Obsolete {
+			var since, dated = msg.ObsoleteSince()
+			if !dated && err == nil {
+				since, dated = revised, true
+			}
+			if olderThan <= 0 || dated && since.Before(cutoff) {
+				continue
+			}
+		}
+		kept = append(kept, msg)
+	}
+	var n = len(f.Messages) - len(kept)
+	for i := len(kept); i < len(f.Messages); i++ {
+		f.Messages[i] = nil
+	}
+	f.Messages = kept
+	f.reindex()
+	return n
+}
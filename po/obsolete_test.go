@@ -0,0 +1,81 @@
+package po
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var obsoletePo = `
+msgid ""
+msgstr ""
+"Po-Revision-Date: 2016-03-01 12:00+0000\n"
+
+msgid "Open"
+msgstr "Otvoriť"
+
+#  obsolete-since: 2016-01-01 12:00+0000
+#~ msgid "Close"
+#~ msgstr "Zavrieť"
+
+#, fuzzy
+#~| msgid "Exit"
+#~ msgctxt "menu"
+#~ msgid "Quit"
+#~ msgstr ""
+#~ "Ukončiť\n"
+#~ "aplikáciu"
+
+`[1:]
+
+func TestParseObsolete(t *testing.T) {
+	var f, err = Parse(strings.NewReader(obsoletePo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []*Message{
+		{Id: "Open", Str: []string{"Otvoriť"}},
+		{Comment: Comment{TranslatorComments: []string{"obsolete-since: 2016-01-01 12:00+0000"}}, Id: "Close", Str: []string{"Zavrieť"}, Obsolete: true},
+		{Comment: Comment{Flags: []string{"fuzzy"}, PrevId: `"Exit"`}, Ctxt: "menu", Id: "Quit", Str: []string{"Ukončiť\naplikáciu"}, Obsolete: true},
+	}
+	if !reflect.DeepEqual(expected, f.Messages) {
+		t.Errorf("expected %v, got %v", expected, f.Messages)
+	}
+	if f.PGetText("menu", "Quit") != "Quit" {
+		t.Error("expected obsolete messages not to be looked up")
+	}
+	var buf bytes.Buffer
+	f.WriteTo(&buf)
+	if buf.String() != obsoletePo {
+		t.Errorf("expected:\n%s\ngot:\n%s", obsoletePo, buf.String())
+	}
+}
+
+func TestPurgeObsolete(t *testing.T) {
+	var now = time.Date(2016, 4, 1, 12, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		olderThan time.Duration
+		revision  string
+		expected  []string
+	}{
+		{0, "", []string{"Open"}},
+		{24 * time.Hour, "", []string{"Open", "Quit"}},
+		{24 * time.Hour, "2016-03-01 12:00+0000", []string{"Open"}},
+		{45 * 24 * time.Hour, "2016-03-01 12:00+0000", []string{"Open", "Quit"}},
+		{100 * 24 * time.Hour, "2016-03-01 12:00+0000", []string{"Open", "Close", "Quit"}},
+	}
+	for _, test := range tests {
+		var f, _ = Parse(strings.NewReader(obsoletePo))
+		f.Header.Set("PO-Revision-Date", test.revision)
+		var n = f.purgeObsolete(test.olderThan, now)
+		var ids []string
+		for _, msg := range f.Messages {
+			ids = append(ids, msg.Id)
+		}
+		if !reflect.DeepEqual(test.expected, ids) || n != 3-len(ids) {
+			t.Errorf("%v, %q: expected %v, got %v (%d removed)", test.olderThan, test.revision, test.expected, ids, n)
+		}
+	}
+}
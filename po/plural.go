@@ -1,6 +1,11 @@
 package po
 
-import "strings"
+//go:generate go run gen_plurals.go
+
+import (
+	"fmt"
+	"strings"
+)
 
 // PluralSelector returns the appropriate plural case to use, given a quantity.
 type PluralSelector func(n int) int
@@ -47,7 +52,8 @@ var langNames = map[string]string{
 	"sl":    "Slovenian",
 }
 
-// TODO: Fall back to these if Plural-Forms is not specified.
+// pluralExprs are the Plural-Forms of GNU gettext, preferred to those of
+// cldrPluralForms.
 var pluralExprs = map[string]string{
 	"ja":    "nplurals=1; plural=0;",
 	"vi":    "nplurals=1; plural=0;",
@@ -114,27 +120,88 @@ func stripSpace(m map[string]PluralSelector) map[string]PluralSelector {
 	return r
 }
 
-// lookupPluralSelectors looks up the given plural form from the set of known ones.
-// nil is returned if the plural form was not recognized.
+// lookupPluralSelectors looks up the given plural form from the set of known
+// ones, which are implemented natively, or compiles it. nil is returned if
+// the plural form is not valid.
 func lookupPluralSelector(pluralForms string) PluralSelector {
-	return pluralSelectors[strings.Replace(pluralForms, " ", "", -1)]
+	if selector := pluralSelectors[strings.Replace(pluralForms, " ", "", -1)]; selector != nil {
+		return selector
+	}
+	var selector, err = CompilePluralForms(pluralForms)
+	if err != nil {
+		return nil
+	}
+	return selector
 }
 
 // PluralSelectorForLanguage returns the appropriate plural selector for the
-// provided languge code. The code can be either the too letter code ("en") or
-// the 5 character variant ("en_GB")
+// provided languge code, or nil if unknown. The code can be either the two
+// letter code ("en"), or have a region or script ("en_GB", "zh-Hant-TW"),
+// falling back to the language if the variant has no rules of its own. The
+// rules of languages unknown to GNU gettext come from CLDR.
 func PluralSelectorForLanguage(lang string) PluralSelector {
-	lang = strings.Replace(lang, "-", "_", -1)
-	if pluralForms, found := pluralExprs[lang]; found {
+	if pluralForms := languagePluralForms(lang); pluralForms != "" {
 		return lookupPluralSelector(pluralForms)
 	}
-	if len(lang) > 2 && lang[2] == '_' {
-		// Naively trim the input
-		if pluralForms, found := pluralExprs[lang[:2]]; found {
-			return lookupPluralSelector(pluralForms)
+	return nil
+}
+
+// languagePluralForms returns the Plural-Forms of the language, or "" if
+// unknown.
+func languagePluralForms(lang string) string {
+	lang = pluralLocale(lang)
+	for _, exprs := range []map[string]string{pluralExprs, cldrPluralForms} {
+		for prefix := lang; prefix != ""; {
+			if pluralForms, found := exprs[prefix]; found {
+				return pluralForms
+			}
+			var i = strings.LastIndexByte(prefix, '_')
+			if i < 0 {
+				break
+			}
+			prefix = prefix[:i]
 		}
 	}
-	return nil
+	return ""
+}
+
+// pluralize returns the plural form of n, selected by the Pluralize of the
+// file, or as in English if it has none.
+func (f *File) pluralize(n int) int {
+	if f.Pluralize == nil {
+		return pluralNeq1(n)
+	}
+	return f.Pluralize(n)
+}
+
+// pluralLocale returns the locale lang with underscores, without the charset
+// and modifier irrelevant to plural rules, e.g. "sr_RS" for "sr-RS.UTF-8" or
+// "sr_RS@latin".
+func pluralLocale(lang string) string {
+	lang = strings.Replace(lang, "-", "_", -1)
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// nplurals returns the number of plural forms of the file, as declared by its
+// Plural-Forms header or implied by its language. It defaults to 2.
+func (f *File) nplurals() int {
+	var n int
+	if _, err := fmt.Sscanf(strings.Replace(f.pluralForms(), " ", "", -1), "nplurals=%d;", &n); err != nil || n < 1 {
+		return 2
+	}
+	return n
+}
+
+// pluralForms returns the Plural-Forms header of the file, or the one
+// implied by its language, if known.
+func (f *File) pluralForms() string {
+	if pluralForms := f.Header.Get("Plural-Forms"); pluralForms != "" {
+		return pluralForms
+	}
+	return languagePluralForms(f.Header.Get("Language"))
 }
 
 func plural0(n int) int {
@@ -1,7 +1,12 @@
 package po
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -29,3 +34,166 @@ func TestPluralSelectorForLanguage(t *testing.T) {
 		}
 	}
 }
+
+func TestPluralSelectorForLanguageCLDR(t *testing.T) {
+	var tests = []struct {
+		lang     string
+		expected []int // for n from 0
+	}{
+		{"ar", []int{0, 1, 2, 3, 3, 3, 3, 3, 3, 3, 3, 4}},
+		{"cy", []int{0, 1, 2, 3, 5, 5, 4, 5}},
+		{"ga_IE", []int{2, 0, 1, 2}},
+		{"is", []int{1, 0, 1}},
+		{"hi-IN", []int{0, 0, 1}},
+		{"zh-Hant-TW", []int{0, 0, 0}},
+		{"sr@latin", []int{2, 0, 1, 1, 1, 2}},
+		{"de_DE.UTF-8", []int{1, 0, 1}},
+		{"pt_PT", []int{1, 0, 1}},
+		{"ca_ES", []int{1, 0, 1}},
+		{"fr_CA", []int{0, 0, 1}},
+		{"ast", []int{1, 0, 1}},
+	}
+	for _, test := range tests {
+		var pluralize = PluralSelectorForLanguage(test.lang)
+		if pluralize == nil {
+			t.Errorf("%s: no plural selector", test.lang)
+			continue
+		}
+		for n, expected := range test.expected {
+			if actual := pluralize(n); actual != expected {
+				t.Errorf("%s: %d: expected %d, got %d", test.lang, n, expected, actual)
+			}
+		}
+	}
+}
+
+func TestNewFilePluralForms(t *testing.T) {
+	var f = NewFile("pt_PT")
+	if f.PluralForms() != "nplurals=2; plural=(n != 1);" {
+		t.Errorf("unexpected Plural-Forms %q", f.PluralForms())
+	}
+	f.Messages = append(f.Messages, &Message{Id: "%d file", IdPlural: "%d files", Str: []string{"%d ficheiro", "%d ficheiros"}})
+	for n, expected := range map[int]string{0: "%d ficheiros", 1: "%d ficheiro", 1000000: "%d ficheiros"} {
+		if actual := f.NGetText("%d file", "%d files", n); actual != expected {
+			t.Errorf("%d: expected %q, got %q", n, expected, actual)
+		}
+	}
+}
+
+func TestCLDRPluralForms(t *testing.T) {
+	for lang, pluralForms := range cldrPluralForms {
+		if _, err := CompilePluralForms(pluralForms); err != nil {
+			t.Errorf("%s: %v", lang, err)
+		}
+	}
+}
+
+func TestCompilePluralForms(t *testing.T) {
+	for lang, pluralForms := range pluralExprs {
+		var native = lookupPluralSelector(pluralForms)
+		var compiled, err = CompilePluralForms(pluralForms)
+		if err != nil {
+			t.Errorf("%s: %v", lang, err)
+			continue
+		}
+		for n := 0; n < 1000; n++ {
+			if native(n) != compiled(n) {
+				t.Errorf("%s: %d: expected %d, got %d", lang, n, native(n), compiled(n))
+				break
+			}
+		}
+	}
+}
+
+func TestCompilePluralFormsCustom(t *testing.T) {
+	var tests = []struct {
+		pluralForms string
+		expected    []int // for n from 0
+	}{
+		{"nplurals=2;plural=n!=1", []int{1, 0, 1}},
+		{" nplurals = 3 ;\tplural = n==0 ? 0 : n==1 ? 1 : 2 ; ", []int{0, 1, 2, 2}},
+		{"nplurals=6; plural=(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5);", []int{0, 1, 2, 3, 3}},
+		{"nplurals=2; plural=!(n/2*2 == n);", []int{0, 1, 0, 1}},
+		{"nplurals=2; plural=n+5;", []int{0, 0, 0}},
+		{"nplurals=2; plural=n%0;", []int{0, 0}},
+	}
+	for _, test := range tests {
+		var selector, err = CompilePluralForms(test.pluralForms)
+		if err != nil {
+			t.Errorf("%q: %v", test.pluralForms, err)
+			continue
+		}
+		for n, expected := range test.expected {
+			if actual := selector(n); actual != expected {
+				t.Errorf("%q: %d: expected %d, got %d", test.pluralForms, n, expected, actual)
+			}
+		}
+	}
+
+	for _, invalid := range []string{
+		"plural=n!=1;",
+		"nplurals=2;",
+		"nplurals=x; plural=0;",
+		"nplurals=2; plural=(n!=1;",
+		"nplurals=2; plural=n ? 1;",
+		"nplurals=2; plural=n !! 1;",
+		"nplurals=2; plural=m;",
+		"nplurals=2; plural=n; extra=1;",
+	} {
+		if _, err := CompilePluralForms(invalid); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
+
+func TestPluralFormsSource(t *testing.T) {
+	for _, test := range []struct{ pluralForms, expected string }{
+		{"nplurals=1; plural=0;", "i := func() int {\nreturn 0\n}()\nif i < 0 || i >= 1 {"},
+		{"nplurals=2; plural=(n != 1);", "b2i := func(b bool) int {\nif b {\nreturn 1\n}\nreturn 0\n}\ni := func() int {\nreturn b2i(n != 1)\n}()"},
+		{
+			"nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;",
+			"if n == 1 {\nreturn 0\n}\nif (n >= 2) && (n <= 4) {\nreturn 1\n}\nreturn 2\n",
+		},
+		{
+			"nplurals=3; plural=n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2;",
+			"if ((n % 10) == 1) && ((n % 100) != 11) {\nreturn 0\n}\nif n != 0 {\nreturn 1\n}\nreturn 2\n",
+		},
+		{"nplurals=2; plural=n/(n-1)+!n;", "return div(n, n - 1) + b2i(!(n != 0))\n"},
+		{"nplurals=3; plural=1 + (n > 2 ? 1 : 0);", "return 1 + func() int {\nif n > 2 {\nreturn 1\n}\nreturn 0\n}()\n"},
+	} {
+		var src, err = PluralFormsSource(test.pluralForms)
+		if err != nil {
+			t.Errorf("%s: %v", test.pluralForms, err)
+			continue
+		}
+		if !strings.Contains(src, test.expected) {
+			t.Errorf("%s: expected %q in:\n%s", test.pluralForms, test.expected, src)
+		}
+	}
+	var all = make(map[string]string)
+	for lang, pluralForms := range pluralExprs {
+		all[lang] = pluralForms
+	}
+	for lang, pluralForms := range cldrPluralForms {
+		all[lang] = pluralForms
+	}
+	all["div"] = "nplurals=3; plural=n/(n-1) % (n+1) + n/2;"
+	for lang, pluralForms := range all {
+		var src, err = PluralFormsSource(pluralForms)
+		if err != nil {
+			t.Errorf("%s: %v", lang, err)
+			continue
+		}
+		var fset = token.NewFileSet()
+		file, err := parser.ParseFile(fset, lang+".go", "package plural\n\nvar plural func(n int) int = "+src+"\n", 0)
+		if err == nil {
+			_, err = new(types.Config).Check("plural", fset, []*ast.File{file}, nil)
+		}
+		if err != nil {
+			t.Errorf("%s: %v in:\n%s", lang, err, src)
+		}
+	}
+	if _, err := PluralFormsSource("nplurals=2; plural=(n;"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,362 @@
+package po
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompilePluralForms compiles a Plural-Forms header value, e.g.
+// "nplurals=2; plural=(n != 1);", into a selector. Plural forms outside of
+// 0 to nplurals-1 are mapped to 0, and divisions by zero evaluate to 0.
+func CompilePluralForms(pluralForms string) (PluralSelector, error) {
+	var nplurals, e, err = parsePluralForms(pluralForms)
+	if err != nil {
+		return nil, err
+	}
+	var eval = e.compile()
+	return func(n int) int {
+		if i := eval(n); i >= 0 && i < nplurals {
+			return i
+		}
+		return 0
+	}, nil
+}
+
+// splitPluralForms returns the nplurals and plural expression of a
+// Plural-Forms header value.
+func splitPluralForms(pluralForms string) (int, string, error) {
+	var nplurals = -1
+	var expr string
+	for _, field := range strings.Split(pluralForms, ";") {
+		var name, value, found = strings.Cut(field, "=")
+		switch name = strings.TrimSpace(name); {
+		case name == "" && !found:
+			continue
+		case name == "nplurals":
+			var n, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 1 || n > maxPlurals {
+				return 0, "", fmt.Errorf("po: invalid nplurals %q", strings.TrimSpace(value))
+			}
+			nplurals = n
+		case name == "plural":
+			expr = value
+		default:
+			return 0, "", fmt.Errorf("po: unexpected %q in plural forms", strings.TrimSpace(field))
+		}
+	}
+	if nplurals == -1 || strings.TrimSpace(expr) == "" {
+		return 0, "", fmt.Errorf("po: plural forms %q lack nplurals or plural", pluralForms)
+	}
+	return nplurals, expr, nil
+}
+
+// pluralExpr is a node of the syntax tree of a plural expression.
+type pluralExpr struct {
+	op      string      // "n", "num", "?:", or the C operator
+	val     int         // of numbers
+	x, y, c *pluralExpr // operands, y of unary operators, c the condition of "?:"
+}
+
+// pluralParser parses the C expressions of plural forms into syntax trees,
+// by recursive descent.
+type pluralParser struct {
+	s   string
+	pos int
+	err error
+}
+
+type pluralFunc func(n int) int
+
+// parsePluralForms returns the nplurals and the syntax tree of the plural
+// expression of a Plural-Forms header value.
+func parsePluralForms(pluralForms string) (int, *pluralExpr, error) {
+	var nplurals, expr, err = splitPluralForms(pluralForms)
+	if err != nil {
+		return 0, nil, err
+	}
+	var p = pluralParser{s: expr}
+	var e = p.ternary()
+	if p.skipSpace(); p.err == nil && p.pos < len(p.s) {
+		p.fail("unexpected %q", p.s[p.pos:])
+	}
+	return nplurals, e, p.err
+}
+
+func (p *pluralParser) fail(format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf("po: plural expression %q: %s", strings.TrimSpace(p.s), fmt.Sprintf(format, args...))
+	}
+}
+
+func (p *pluralParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) != -1 {
+		p.pos++
+	}
+}
+
+// accept consumes the operator op, if next.
+func (p *pluralParser) accept(op string) bool {
+	p.skipSpace()
+	if p.err != nil || !strings.HasPrefix(p.s[p.pos:], op) {
+		return false
+	}
+	// Do not take "<" for "<=", nor "!" for "!=".
+	if len(op) == 1 && strings.Contains("<>!=", op) && strings.HasPrefix(p.s[p.pos+1:], "=") {
+		return false
+	}
+	p.pos += len(op)
+	return true
+}
+
+func (p *pluralParser) ternary() *pluralExpr {
+	var cond = p.binary(0)
+	if !p.accept("?") {
+		return cond
+	}
+	var then = p.ternary()
+	if !p.accept(":") {
+		p.fail("missing :")
+		return cond
+	}
+	return &pluralExpr{op: "?:", c: cond, x: then, y: p.ternary()}
+}
+
+// pluralLevels lists the binary operators by increasing precedence.
+var pluralLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<=", ">=", "<", ">"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *pluralParser) binary(level int) *pluralExpr {
+	if level == len(pluralLevels) {
+		return p.unary()
+	}
+	var l = p.binary(level + 1)
+	for {
+		var op string
+		for _, o := range pluralLevels[level] {
+			if p.accept(o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return l
+		}
+		l = &pluralExpr{op: op, x: l, y: p.binary(level + 1)}
+	}
+}
+
+func (p *pluralParser) unary() *pluralExpr {
+	if p.accept("!") {
+		return &pluralExpr{op: "!", y: p.unary()}
+	}
+	return p.primary()
+}
+
+func (p *pluralParser) primary() *pluralExpr {
+	p.skipSpace()
+	switch {
+	case p.err != nil:
+	case p.accept("("):
+		var e = p.ternary()
+		if !p.accept(")") {
+			p.fail("missing )")
+		}
+		return e
+	case p.accept("n"):
+		return &pluralExpr{op: "n"}
+	case p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9':
+		var start = p.pos
+		for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+		var v, err = strconv.Atoi(p.s[start:p.pos])
+		if err != nil {
+			p.fail("invalid number %q", p.s[start:p.pos])
+		}
+		return &pluralExpr{op: "num", val: v}
+	case p.pos < len(p.s):
+		p.fail("unexpected %q", p.s[p.pos:])
+	default:
+		p.fail("unexpected end")
+	}
+	return &pluralExpr{op: "num"}
+}
+
+// compile returns the function of n evaluating the expression, with
+// booleans as 1 or 0.
+func (e *pluralExpr) compile() pluralFunc {
+	switch e.op {
+	case "n":
+		return func(n int) int { return n }
+	case "num":
+		var v = e.val
+		return func(int) int { return v }
+	case "?:":
+		var cond, then, els = e.c.compile(), e.x.compile(), e.y.compile()
+		return func(n int) int {
+			if cond(n) != 0 {
+				return then(n)
+			}
+			return els(n)
+		}
+	case "!":
+		var a = e.y.compile()
+		return func(n int) int { return bool2int(a(n) == 0) }
+	}
+	var a, b = e.x.compile(), e.y.compile()
+	switch e.op {
+	case "||":
+		return func(n int) int { return bool2int(a(n) != 0 || b(n) != 0) }
+	case "&&":
+		return func(n int) int { return bool2int(a(n) != 0 && b(n) != 0) }
+	case "==":
+		return func(n int) int { return bool2int(a(n) == b(n)) }
+	case "!=":
+		return func(n int) int { return bool2int(a(n) != b(n)) }
+	case "<=":
+		return func(n int) int { return bool2int(a(n) <= b(n)) }
+	case ">=":
+		return func(n int) int { return bool2int(a(n) >= b(n)) }
+	case "<":
+		return func(n int) int { return bool2int(a(n) < b(n)) }
+	case ">":
+		return func(n int) int { return bool2int(a(n) > b(n)) }
+	case "+":
+		return func(n int) int { return a(n) + b(n) }
+	case "-":
+		return func(n int) int { return a(n) - b(n) }
+	case "*":
+		return func(n int) int { return a(n) * b(n) }
+	case "/":
+		return func(n int) int {
+			if d := b(n); d != 0 {
+				return a(n) / d
+			}
+			return 0
+		}
+	}
+	return func(n int) int {
+		if d := b(n); d != 0 {
+			return a(n) % d
+		}
+		return 0
+	}
+}
+
+func bool2int(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// PluralFormsSource returns the Go source of a function literal of type
+// func(n int) int selecting the plural forms of a Plural-Forms header value,
+// like the selector of CompilePluralForms, e.g. for generated code. The
+// literal depends on no package.
+func PluralFormsSource(pluralForms string) (string, error) {
+	var nplurals, e, err = parsePluralForms(pluralForms)
+	if err != nil {
+		return "", err
+	}
+	var g pluralSource
+	var body = g.body(e)
+	var b strings.Builder
+	b.WriteString("func(n int) int {\n")
+	if g.b2i {
+		b.WriteString("b2i := func(b bool) int {\nif b {\nreturn 1\n}\nreturn 0\n}\n")
+	}
+	for _, op := range []string{"/", "%"} {
+		if g.div[op] {
+			fmt.Fprintf(&b, "%s := func(x, y int) int {\nif y == 0 {\nreturn 0\n}\nreturn x %s y\n}\n", pluralHelpers[op], op)
+		}
+	}
+	fmt.Fprintf(&b, "i := func() int {\n%s}()\nif i < 0 || i >= %d {\nreturn 0\n}\nreturn i\n}", body, nplurals)
+	return b.String(), nil
+}
+
+// pluralHelpers names the functions dividing by expressions that may be 0.
+var pluralHelpers = map[string]string{"/": "div", "%": "mod"}
+
+// pluralSource writes the Go source of plural expressions, recording the
+// helper functions it uses.
+type pluralSource struct {
+	b2i bool
+	div map[string]bool // by operator
+}
+
+// isBool returns true if the expression is of type bool in Go.
+func (e *pluralExpr) isBool() bool {
+	switch e.op {
+	case "!", "<", "<=", ">", ">=", "==", "!=", "&&", "||":
+		return true
+	}
+	return false
+}
+
+// checked returns true if the expression divides by an expression that may
+// be 0, with a helper function.
+func (e *pluralExpr) checked() bool {
+	return (e.op == "/" || e.op == "%") && !(e.y.op == "num" && e.y.val != 0)
+}
+
+// int returns the Go expression of type int of e.
+func (g *pluralSource) int(e *pluralExpr) string {
+	switch {
+	case e.op == "n":
+		return "n"
+	case e.op == "num":
+		return strconv.Itoa(e.val)
+	case e.op == "?:":
+		return fmt.Sprintf("func() int {\nif %s {\nreturn %s\n}\nreturn %s\n}()", g.bool(e.c), g.int(e.x), g.int(e.y))
+	case e.isBool():
+		g.b2i = true
+		return "b2i(" + g.bool(e) + ")"
+	case e.checked():
+		if g.div == nil {
+			g.div = make(map[string]bool)
+		}
+		g.div[e.op] = true
+		return fmt.Sprintf("%s(%s, %s)", pluralHelpers[e.op], g.int(e.x), g.int(e.y))
+	}
+	return g.operand(e.x) + " " + e.op + " " + g.operand(e.y)
+}
+
+// operand returns the Go expression of type int of e, parenthesized unless
+// it is a primary expression.
+func (g *pluralSource) operand(e *pluralExpr) string {
+	var s = g.int(e)
+	if e.op == "n" || e.op == "num" || e.op == "?:" || e.isBool() || e.checked() {
+		return s
+	}
+	return "(" + s + ")"
+}
+
+// bool returns the Go expression of type bool of e.
+func (g *pluralSource) bool(e *pluralExpr) string {
+	switch e.op {
+	case "!":
+		return "!(" + g.bool(e.y) + ")"
+	case "&&", "||":
+		return "(" + g.bool(e.x) + ") " + e.op + " (" + g.bool(e.y) + ")"
+	case "<", "<=", ">", ">=", "==", "!=":
+		return g.operand(e.x) + " " + e.op + " " + g.operand(e.y)
+	}
+	return g.operand(e) + " != 0"
+}
+
+// body returns the statements returning the value of e, with the ternaries
+// of the top level as if statements.
+func (g *pluralSource) body(e *pluralExpr) string {
+	if e.op == "?:" {
+		return fmt.Sprintf("if %s {\n%s}\n%s", g.bool(e.c), g.body(e.x), g.body(e.y))
+	}
+	return "return " + g.int(e) + "\n"
+}
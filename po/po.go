@@ -2,21 +2,74 @@ package po
 
 import (
 	"bufio"
-	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/textproto"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // File represents a PO file.
+//
+// The lookups, Lookup, AddMessage, RemoveMessage, Set and writing the file are
+// safe for concurrent use. Other changes to the fields or messages, once the
+// file is in use, must not run concurrently with them. Messages appended to
+// Messages are indexed by the next lookup; other changes to it require a call
+// to Reindex.
 type File struct {
 	Header    textproto.MIMEHeader
 	Messages  []*Message
 	Pluralize PluralSelector
 
-	byId map[string]*Message
+	// HeaderComment holds the comment lines of the header entry, and of the
+	// free-standing comment blocks before it, as written, with empty strings
+	// for the blank lines in between. Lines without the "#" prefix are
+	// written as translator comments.
+	HeaderComment []string
+
+	// ContextFallback makes lookups of a message in a context fall back to
+	// the message without a context, when missing, and vice versa. This helps
+	// migrations introducing contexts incrementally.
+	ContextFallback bool
+
+	// UseFuzzy makes lookups use the translations of fuzzy messages, which
+	// are otherwise treated as missing, like msgfmt does.
+	UseFuzzy bool
+
+	// OnMiss, if set, is called by the lookups falling back to the source
+	// string: those of messages missing, fuzzy, or without a translation of
+	// the plural form looked up.
+	OnMiss func(ctxt, id string)
+
+	mu     sync.RWMutex      // guards Messages and the lookup index
+	byId   map[key]*entry    // by context and msgid
+	nindex int               // number of messages indexed
+	anyCtx map[string]*entry // by msgid, first message in any context
+
+	normalizer *Normalizer
+	byNormId   map[key]*entry // by context and normalized msgid
+
+	lines       map[*Message]int      // line numbers of the parsed messages
+	headerOrder []string              // names of the parsed header fields, in order
+	comments    map[*Message][]string // free-standing comment lines before the parsed messages, or at the end for nil
+	charset     string                // charset the file was decoded from, if other than UTF-8
+
+	missed atomic.Pointer[func(ctxt, id string)] // records the misses for the Bundle tracking them
+	absent fields                                // fields without a column in the parsed spreadsheet, left alone by Update
+
+	// Warnings holds the non-fatal problems found when parsing the file,
+	// such as unknown flags, suspicious escape sequences, or repeated header
+	// fields.
+	Warnings []Problem
+
+	// Errors holds the malformed entries skipped when parsing the file in
+	// lenient mode.
+	Errors []ParseError
 }
 
 // Message stores a gettext message.
@@ -26,102 +79,342 @@ type Message struct {
 	Id       string   // msgid: untranslated singular string
 	IdPlural string   // msgid_plural: untranslated plural string
 	Str      []string // msgstr or msgstr[n]: translated strings
+
+	// StrIndices holds the n of each msgstr[n] in the order they appeared in
+	// the file, if that was out of order or had gaps. Missing forms are
+	// empty in Str.
+	StrIndices []int
+
+	// Obsolete marks a message removed from the template, whose fields are
+	// commented out with "#~" and kept for reuse.
+	Obsolete bool
 }
 
 // Comment stores meta-data from a gettext message.
 type Comment struct {
 	TranslatorComments []string
 	ExtractedComments  []string
-	References         []string
+	Extensions         []Extension
+	References         []Reference
 	Flags              []string
 	PrevCtxt           string
 	PrevId             string
 	PrevIdPlural       string
 }
 
+// ParseOptions controls how PO files are parsed.
+type ParseOptions struct {
+	// NFC, if set, normalizes the contexts, msgids and msgstrs to Unicode
+	// Normalization Form C, e.g. norm.NFC.String from
+	// golang.org/x/text/unicode/norm. This prevents lookup misses caused by
+	// editors saving decomposed characters.
+	NFC func(string) string
+	// ValidateUTF8 rejects files that are not valid UTF-8.
+	ValidateUTF8 bool
+	// Lenient skips the malformed entries instead of failing, recording
+	// their errors in File.Errors.
+	Lenient bool
+	// CharsetReader, if set, returns a reader decoding input from the
+	// charset declared by the Content-Type header, if other than UTF-8, to
+	// UTF-8, like that of xml.Decoder. ISO-8859-1 is decoded without it.
+	// The files decoded are encoded back to their charset when written.
+	CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+	// Duplicates selects how the messages sharing their context and msgid
+	// with a previous one are resolved. They are kept by default.
+	Duplicates DuplicatePolicy
+	// Progress, if set, is called periodically while parsing, and once done.
+	Progress func(Progress)
+	// Logger, if set, receives a record of each file parsed, with its size,
+	// duration and number of messages, or its error.
+	Logger *slog.Logger
+	// Name names the file parsed in the records of Logger, e.g. its path.
+	Name string
+}
+
+// Progress reports the advancement of a long operation.
+type Progress struct {
+	Messages int   // messages processed so far
+	Bytes    int64 // bytes read or written so far
+}
+
+// progressInterval is the number of messages between progress reports.
+const progressInterval = 256
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	var n, err = c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 // Parse reads the content of a PO file and returns the list of messages.
 func Parse(r io.Reader) (*File, error) {
+	return ParseWithOptions(r, ParseOptions{})
+}
+
+// parseHeader parses the header fields from the msgstr of the header entry,
+// returning them with their names in order.
+func parseHeader(str string) (textproto.MIMEHeader, []string, error) {
+	var header, err = textproto.NewReader(bufio.NewReader(strings.NewReader(str))).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, nil, fmt.Errorf("%w: %v", ErrBadHeader, err)
+	}
+	return header, headerOrderOf(str), nil
+}
+
+// headerPluralSelector returns the plural selector of the Plural-Forms
+// header, or of the Language if undeclared.
+func headerPluralSelector(header textproto.MIMEHeader) (PluralSelector, error) {
+	if pluralForms := header.Get("Plural-Forms"); pluralForms != "" {
+		var pluralize = lookupPluralSelector(pluralForms)
+		if pluralize == nil {
+			return nil, &PluralFormsError{pluralForms}
+		}
+		return pluralize, nil
+	}
+	return PluralSelectorForLanguage(header.Get("Language")), nil
+}
+
+// slabSize is the number of messages allocated at once while parsing.
+const slabSize = 64
+
+// messageSlab allocates messages in batches, so that parsing large files
+// does not allocate each message separately. A batch stays in memory while
+// any of its messages is used.
+type messageSlab struct {
+	buf []Message
+}
+
+func (s *messageSlab) alloc() *Message {
+	if len(s.buf) == 0 {
+		s.buf = make([]Message, slabSize)
+	}
+	var m = &s.buf[0]
+	s.buf = s.buf[1:]
+	return m
+}
+
+// ParseWithOptions is like Parse, with the given options.
+func ParseWithOptions(r io.Reader, opts ParseOptions) (*File, error) {
+	if opts.Logger == nil {
+		return parse(r, opts)
+	}
+	var start = time.Now()
+	var last Progress
+	var progress = opts.Progress
+	opts.Progress = func(p Progress) {
+		last = p
+		if progress != nil {
+			progress(p)
+		}
+	}
+	var f, err = parse(r, opts)
+	var attrs = []slog.Attr{
+		slog.String("file", opts.Name),
+		slog.Int64("bytes", last.Bytes),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		opts.Logger.LogAttrs(context.Background(), slog.LevelError, "po: parse failed", append(attrs, slog.Any("error", err))...)
+		return nil, err
+	}
+	opts.Logger.LogAttrs(context.Background(), slog.LevelInfo, "po: parsed", append(attrs,
+		slog.Int("messages", len(f.Messages)), slog.Int("warnings", len(f.Warnings)), slog.Int("errors", len(f.Errors)))...)
+	return f, nil
+}
+
+// ParseFunc reads the content of a PO file, calling fn with each message in
+// turn, starting with the header entry, if any, whose msgid is empty. The
+// messages are not kept, so that large files can be filtered or indexed
+// without holding all of them in memory. Parsing stops at the first error
+// returned by fn, which is returned.
+func ParseFunc(r io.Reader, fn func(*Message) error) error {
+	var in, _, err = decodeCharset(r, ParseOptions{})
+	if err != nil {
+		return err
+	}
+	var scan = newScanner(in)
+	for scan.nextmsg() {
+		var msg = new(Message)
+		scan.message(msg)
+		scan.warnings = scan.warnings[:0]
+		scan.resetRaw()
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+	return scan.Err()
+}
+
+func parse(r io.Reader, opts ParseOptions) (*File, error) {
 	var msgs []*Message
-	var byId = make(map[string]*Message)
-	var scan = newScanner(r)
+	var lines = make(map[*Message]int)
+	var warnings []Problem
+	var errs []ParseError
+	var comments = make(map[*Message][]string)
+	var loose []string // free-standing comment lines before the next message
+	var counter = &countingReader{r: r}
+	var in, charset, err = decodeCharset(counter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var scan = newScanner(in)
+	scan.validateUTF8 = opts.ValidateUTF8
+	var slab messageSlab
 	for scan.nextmsg() {
-		// NOTE: the source code order of these fields is important.
-		var msg = &Message{
-			Comment: Comment{
-				TranslatorComments: scan.mul("# "),
-				ExtractedComments:  scan.mul("#."),
-				References:         scan.spc("#:"),
-				Flags:              scan.spc("#,"),
-				PrevCtxt:           scan.one("#| msgctxt"),
-				PrevId:             scan.one("#| msgid"),
-				PrevIdPlural:       scan.one("#| msgid_plural"),
-			},
-			Ctxt:     scan.quo("msgctxt"),
-			Id:       scan.quo("msgid"),
-			IdPlural: scan.quo("msgid_plural"),
-			Str:      scan.msgstr(),
+		var line = scan.line
+		var msg = slab.alloc()
+		scan.message(msg)
+		if opts.Lenient && scan.err != nil {
+			errs = append(errs, *scan.skipmsg())
+			scan.warnings = scan.warnings[:0]
+			scan.resetRaw()
+			continue
+		}
+		if msg.isComment() {
+			loose = append(loose, scan.rawLines(len(scan.rawEnds))...)
+			scan.resetRaw()
+			continue
+		}
+		if len(msgs) == 0 && msg.Id == "" && len(msg.Str) == 1 {
+			// the comments of the header entry are kept as written.
+			loose = append(loose, scan.rawLines(scan.nraw)...)
+		}
+		if loose = trimBlank(loose); len(loose) > 0 {
+			comments[msg] = loose
+			loose = nil
+		}
+		scan.resetRaw()
+		if opts.NFC != nil {
+			msg.normalize(opts.NFC)
 		}
 		msgs = append(msgs, msg)
-		byId[compoundId(msg.Id, msg.IdPlural)] = msg
+		lines[msg] = line
+		for _, w := range scan.warnings {
+			w.Msg = msg
+			warnings = append(warnings, w)
+		}
+		scan.warnings = scan.warnings[:0]
+		if opts.Progress != nil && len(msgs)%progressInterval == 0 {
+			opts.Progress(Progress{len(msgs), counter.n})
+		}
+	}
+	if opts.Progress != nil {
+		opts.Progress(Progress{len(msgs), counter.n})
 	}
 	if scan.Err() != nil {
 		return nil, scan.Err()
 	}
+	if loose = trimBlank(loose); len(loose) > 0 {
+		comments[nil] = loose
+	}
 
 	var header textproto.MIMEHeader
-	if msgs[0].Id == "" && len(msgs[0].Str) == 1 {
-		var err error
-		header, err = textproto.NewReader(bufio.NewReader(strings.NewReader(msgs[0].Str[0]))).
-			ReadMIMEHeader()
-		if err != nil && err != io.EOF {
+	var headerOrder []string
+	var headerComment []string
+	if len(msgs) > 0 && msgs[0].Id == "" && len(msgs[0].Str) == 1 {
+		if header, headerOrder, err = parseHeader(msgs[0].Str[0]); err != nil {
 			return nil, err
 		}
+		headerComment = comments[msgs[0]]
+		delete(comments, msgs[0])
+		for i := range warnings {
+			if warnings[i].Msg == msgs[0] {
+				warnings[i].Msg = nil
+			}
+		}
 		msgs = msgs[1:]
 	}
 
-	var pluralize PluralSelector
-	if pluralForms := header.Get("Plural-Forms"); pluralForms != "" {
-		pluralize = lookupPluralSelector(pluralForms)
-		if pluralize == nil {
-			return nil, fmt.Errorf("unrecognized plural form selector: %v", pluralForms)
-		}
+	var dupErrs []ParseError
+	if msgs, dupErrs, err = resolveDuplicates(msgs, lines, opts.Duplicates, opts.Lenient); err != nil {
+		return nil, err
 	}
-	if pluralize == nil {
-		pluralize = PluralSelectorForLanguage(header.Get("Language"))
+	errs = append(errs, dupErrs...)
+
+	pluralize, err := headerPluralSelector(header)
+	if err != nil {
+		return nil, err
 	}
 
-	return &File{header, msgs, pluralize, byId}, nil
+	var f = &File{Header: header, HeaderComment: headerComment, Messages: msgs, Pluralize: pluralize, lines: lines, headerOrder: headerOrder, comments: comments, charset: charset, Errors: errs}
+	f.reindex()
+	f.Warnings = append(warnings, f.headerWarnings()...)
+	f.Warnings = append(f.Warnings, (&Linter{[]Rule{flagsRule}}).Lint(f)...)
+	return f, nil
 }
 
-// Write the PO file to a destination writer.
-func (f File) WriteTo(w io.Writer) (n int64, err error) {
-	var wr = newWriter()
-	// TODO: Probably better to make a type for the header and implement WriterTo
-	if len(f.Header) > 0 {
-		wr.quo("msgid ", "")
-		var keys []string
-		for k := range f.Header {
+// headerWarnings reports the header fields that are repeated.
+func (f *File) headerWarnings() []Problem {
+	var keys []string
+	for k, v := range f.Header {
+		if len(v) > 1 {
 			keys = append(keys, k)
 		}
-		sort.Strings(keys)
-		var buf bytes.Buffer
-		for _, k := range keys {
-			buf.WriteString(k + ": " + f.Header.Get(k) + "\n")
-		}
-		wr.quo("msgstr ", buf.String())
-		wr.newline()
 	}
-	for _, msg := range f.Messages {
-		wr.from(msg)
-		wr.newline()
+	sort.Strings(keys)
+	var r []Problem
+	for _, k := range keys {
+		r = append(r, Problem{Rule: "header", Severity: Warning, Text: "repeated header field " + k})
 	}
-	return wr.to(w)
+	return r
+}
+
+// isComment returns true for a free-standing comment, parsed as a message
+// without fields.
+func (m *Message) isComment() bool {
+	return m.Ctxt == "" && m.Id == "" && m.IdPlural == "" && len(m.Str) == 0 && !m.Obsolete
+}
+
+// trimBlank returns the lines without the leading and trailing blank ones.
+func trimBlank(lines []string) []string {
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// normalize applies fn to the context, ids and translations of the message.
+func (m *Message) normalize(fn func(string) string) {
+	m.Ctxt, m.Id, m.IdPlural = fn(m.Ctxt), fn(m.Id), fn(m.IdPlural)
+	for i, str := range m.Str {
+		m.Str[i] = fn(str)
+	}
+}
+
+// Write the PO file to a destination writer.
+func (f *File) WriteTo(w io.Writer) (n int64, err error) {
+	return f.write(w, WriteOptions{})
 }
 
 // Write the PO Message to a destination writer.
 func (m Message) WriteTo(w io.Writer) (n int64, err error) {
 	var wr = newWriter()
-	wr.from(m.Comment)
+	wr.message(&m)
+	return wr.to(w)
+}
+
+// message writes the fields of the message.
+func (wr *writer) message(m *Message) {
+	if m.Obsolete {
+		var c = m.Comment
+		c.PrevCtxt, c.PrevId, c.PrevIdPlural = "", "", ""
+		wr.from(c)
+		wr.one(obsoletePrevPrefix+" msgctxt ", m.PrevCtxt)
+		wr.one(obsoletePrevPrefix+" msgid ", m.PrevId)
+		wr.one(obsoletePrevPrefix+" msgid_plural ", m.PrevIdPlural)
+		wr.lead = obsoletePrefix
+	} else {
+		wr.from(m.Comment)
+	}
 	wr.opt("msgctxt ", m.Ctxt)
 	wr.quo("msgid ", m.Id)
 	wr.opt("msgid_plural ", m.IdPlural)
@@ -130,8 +423,7 @@ func (m Message) WriteTo(w io.Writer) (n int64, err error) {
 	} else {
 		wr.plural(m.Str)
 	}
-
-	return wr.to(w)
+	wr.lead = ""
 }
 
 // Write the comment to the given writer.
@@ -139,47 +431,372 @@ func (c Comment) WriteTo(w io.Writer) (n int64, err error) {
 	var wr = newWriter()
 	wr.mul("#  ", c.TranslatorComments)
 	wr.mul("#. ", c.ExtractedComments)
-	wr.spc("#: ", c.References)
-	wr.spc("#, ", c.Flags)
+	wr.ext("#% ", c.Extensions)
+	wr.spc("#: ", referenceStrings(c.References))
+	wr.csv("#, ", c.Flags)
 	wr.one("#| msgctxt ", c.PrevCtxt)
 	wr.one("#| msgid ", c.PrevId)
 	wr.one("#| msgid_plural ", c.PrevIdPlural)
 	return wr.to(w)
 }
 
-// GetText.
-func (f *File) GetText(id string, data ...interface{}) string {
-	str := id
-	msg := f.getByIds(id)
+// HasFlag returns true if the comment carries the given flag, e.g. "fuzzy".
+func (c Comment) HasFlag(flag string) bool {
+	return contains(c.Flags, flag)
+}
 
-	if msg != nil && len(msg.Str) != 0 && msg.Str[0] != "" {
-		str = msg.Str[0]
-	}
+// GetText returns the translation of id, or id if missing, formatted with
+// data as by fmt.Sprintf. Without data, it is returned as it is, so that
+// translations with a literal "%", e.g. "50% off", are not mangled.
+func (f *File) GetText(id string, data ...interface{}) string {
+	return f.getByIds("", id, 0).format(0, id, data)
+}
 
-	return fmt.Sprintf(str, data...)
+// GetTextf is like GetText, but formats the translation even without data,
+// e.g. turning "%%" into "%".
+func (f *File) GetTextf(id string, data ...interface{}) string {
+	return f.getByIds("", id, 0).formatf(0, id, data)
 }
 
 // NGetText.
 func (f *File) NGetText(id, idPlural string, lenght int, data ...interface{}) string {
-	msg := f.getByIds(id, idPlural)
-	index := f.Pluralize(lenght)
+	return f.NPGetText("", id, idPlural, lenght, data...)
+}
+
+// PGetText is like GetText, for the message in the given context.
+func (f *File) PGetText(ctxt, id string, data ...interface{}) string {
+	return f.getByIds(ctxt, id, 0).format(0, id, data)
+}
+
+// PGetTextf is like PGetText, but formats the translation even without
+// data.
+func (f *File) PGetTextf(ctxt, id string, data ...interface{}) string {
+	return f.getByIds(ctxt, id, 0).formatf(0, id, data)
+}
+
+// NPGetText is like NGetText, for the message in the given context.
+func (f *File) NPGetText(ctxt, id, idPlural string, n int, data ...interface{}) string {
+	e, index, str := f.plural(ctxt, id, idPlural, n)
+	return e.format(index, str, data)
+}
+
+// NGetTextf is like NGetText, but formats the translation even without
+// data.
+func (f *File) NGetTextf(id, idPlural string, n int, data ...interface{}) string {
+	return f.NPGetTextf("", id, idPlural, n, data...)
+}
+
+// NPGetTextf is like NPGetText, but formats the translation even without
+// data.
+func (f *File) NPGetTextf(ctxt, id, idPlural string, n int, data ...interface{}) string {
+	e, index, str := f.plural(ctxt, id, idPlural, n)
+	return e.formatf(index, str, data)
+}
+
+// plural returns the message, the index of its plural form for n, and the
+// string used if it is not translated.
+func (f *File) plural(ctxt, id, idPlural string, n int) (*entry, int, string) {
+	index := f.pluralize(n)
 	str := id
-	if index == 1 {
+	if n != 1 {
+		// Untranslated messages are formatted as in English, like GNU gettext.
 		str = idPlural
 	}
+	return f.getByIds(ctxt, id, index), index, str
+}
+
+// NGetTextFloat is like NGetText, for a fractional quantity, e.g. 1.5 km.
+// Its plural form is that of its CLDR category in the language of the file,
+// or that of its integer part, as in C, if the rules of the language are
+// unknown.
+func (f *File) NGetTextFloat(id, idPlural string, n float64, data ...interface{}) string {
+	return f.NPGetTextFloat("", id, idPlural, n, data...)
+}
+
+// NPGetTextFloat is like NGetTextFloat, for the message in the given
+// context.
+func (f *File) NPGetTextFloat(ctxt, id, idPlural string, n float64, data ...interface{}) string {
+	var index int
+	if rule := f.pluralRule(); rule != nil {
+		index = rule.Index(n)
+	} else {
+		index = f.pluralize(int(n))
+	}
+	str := id
+	if n != 1 {
+		str = idPlural
+	}
+	return f.getByIds(ctxt, id, index).format(index, str, data)
+}
+
+// Line returns the line the message started on in the parsed file, or 0 if
+// the message was not parsed from it.
+func (f *File) Line(m *Message) int {
+	return f.lines[m]
+}
+
+// entry is a message of the lookup index, with precomputed facts about its
+// translations.
+type entry struct {
+	*Message
+	strs  []string // msgstrs the facts were computed for
+	verbs []bool   // whether each msgstr contains format verbs
+	fuzzy bool     // whether the message is flagged fuzzy
+}
+
+func newEntry(m *Message) *entry {
+	var e = new(entry)
+	e.init(m, make([]string, len(m.Str)), make([]bool, len(m.Str)))
+	return e
+}
+
+// init sets e to the entry of m, computing its facts into strs and verbs, of
+// the length of m.Str.
+func (e *entry) init(m *Message, strs []string, verbs []bool) {
+	copy(strs, m.Str)
+	*e = entry{m, strs, verbs, m.HasFlag("fuzzy")}
+	for i, str := range m.Str {
+		verbs[i] = hasVerbs(str)
+	}
+}
 
-	if msg != nil && len(msg.Str) > index && msg.Str[index] != "" {
-		str = msg.Str[index]
+// format returns msgstr[i] formatted with data, or fallback if the message
+// is missing or not translated. It is returned as it is without data.
+func (e *entry) format(i int, fallback string, data []interface{}) string {
+	if len(data) == 0 {
+		return e.text(i, fallback)
 	}
+	return e.formatf(i, fallback, data)
+}
 
+// formatf is like format, but formats the string even without data. Strings
+// without format verbs are returned as they are, sparing fmt.Sprintf.
+func (e *entry) formatf(i int, fallback string, data []interface{}) string {
+	var str, verbs = fallback, false
+	if e.translated(i) {
+		str = e.Str[i]
+		if i < len(e.strs) && e.strs[i] == str {
+			verbs = e.verbs[i]
+		} else {
+			// The message was modified after indexing.
+			verbs = hasVerbs(str)
+		}
+	} else {
+		verbs = hasVerbs(str)
+	}
+	if !verbs {
+		return str
+	}
 	return fmt.Sprintf(str, data...)
 }
 
-func (f *File) getByIds(ids ...string) *Message {
-	msg := f.byId[compoundId(ids...)]
-	return msg
+// text returns msgstr[i], or fallback if the message is missing or not
+// translated.
+func (e *entry) text(i int, fallback string) string {
+	if e.translated(i) {
+		return e.Str[i]
+	}
+	return fallback
+}
+
+// translated returns true if msgstr[i] of the message is filled in.
+func (e *entry) translated(i int) bool {
+	return e != nil && i < len(e.Str) && e.Str[i] != ""
+}
+
+// hasVerbs returns true if str contains fmt verbs, including "%%".
+func hasVerbs(str string) bool {
+	return strings.IndexByte(str, '%') != -1
+}
+
+// key identifies a message in the lookup index. Messages are identified by
+// their msgid alone, as in MO files.
+type key struct {
+	ctxt, id string
+}
+
+// Reindex rebuilds the lookup index after changes to Messages.
+func (f *File) Reindex() {
+	f.reindex()
 }
 
-func compoundId(ids ...string) string {
-	return strings.Trim(strings.Join(ids, "|"), "|")
+// reindex rebuilds the lookup index from the messages.
+func (f *File) reindex() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reindexLocked()
+}
+
+func (f *File) reindexLocked() {
+	f.byId = make(map[key]*entry, len(f.Messages))
+	f.anyCtx = make(map[string]*entry)
+	f.byNormId = nil
+	if f.normalizer != nil {
+		f.byNormId = make(map[key]*entry, len(f.Messages))
+	}
+	// The entries are allocated at once, rather than by message.
+	var n = 0
+	for _, msg := range f.Messages {
+		n += len(msg.Str)
+	}
+	var entries = make([]entry, len(f.Messages))
+	var strs, verbs = make([]string, n), make([]bool, n)
+	for i, msg := range f.Messages {
+		if msg.Obsolete {
+			continue
+		}
+		var n = len(msg.Str)
+		entries[i].init(msg, strs[:n:n], verbs[:n:n])
+		strs, verbs = strs[n:], verbs[n:]
+		f.indexEntry(&entries[i])
+	}
+	f.nindex = len(f.Messages)
+}
+
+// stale returns true if messages were added to Messages since indexing.
+func (f *File) stale() bool {
+	return f.byId == nil || f.nindex != len(f.Messages)
+}
+
+// index adds the message to the lookup index, replacing any message with the
+// same context and msgid. Obsolete messages are not looked up.
+func (f *File) index(msg *Message) {
+	if msg.Obsolete {
+		return
+	}
+	f.indexEntry(newEntry(msg))
+}
+
+// indexEntry adds the entry of a message to the lookup index.
+func (f *File) indexEntry(e *entry) {
+	var msg = e.Message
+	f.byId[key{msg.Ctxt, msg.Id}] = e
+	if msg.Ctxt != "" && f.anyCtx[msg.Id] == nil {
+		f.anyCtx[msg.Id] = e
+	}
+	if f.normalizer != nil {
+		f.byNormId[f.normalizer.normalizedKey(msg.Ctxt, msg.Id)] = e
+	}
+}
+
+// AddMessage adds the message to the file, replacing the message with the
+// same context and msgid, if any.
+func (f *File) AddMessage(m *Message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.addMessageLocked(m)
+}
+
+func (f *File) addMessageLocked(m *Message) {
+	if f.stale() {
+		f.reindexLocked()
+	}
+	if old := f.byId[key{m.Ctxt, m.Id}]; old != nil {
+		for i, msg := range f.Messages {
+			if msg == old.Message {
+				f.Messages[i] = m
+			}
+		}
+		f.reindexLocked()
+		return
+	}
+	f.Messages = append(f.Messages, m)
+	f.index(m)
+	f.nindex = len(f.Messages)
+}
+
+// Set sets the translations of the message with the given context and msgid,
+// adding the message if missing, and returns it. An existing message is
+// replaced by a copy with the new translations, so that concurrent lookups
+// never see a partial change.
+func (f *File) Set(ctxt, id string, str ...string) *Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stale() {
+		f.reindexLocked()
+	}
+	var m = &Message{Ctxt: ctxt, Id: id}
+	if old := f.byId[key{ctxt, id}]; old != nil {
+		*m = *old.Message
+		m.StrIndices = nil
+	}
+	m.Str = str
+	f.addMessageLocked(m)
+	return m
+}
+
+// RemoveMessage removes the message with the given context and msgid from the
+// file. It returns false if there is no such message.
+func (f *File) RemoveMessage(ctxt, id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var removed bool
+	var kept = f.Messages[:0]
+	for _, msg := range f.Messages {
+		if msg.Ctxt == ctxt && msg.Id == id {
+			removed = true
+			continue
+		}
+		kept = append(kept, msg)
+	}
+	if removed {
+		f.Messages = kept
+		f.reindexLocked()
+	}
+	return removed
+}
+
+// Lookup returns the message used by the lookups of the msgid in the given
+// context, or nil if missing. Unlike them, it does not call OnMiss.
+func (f *File) Lookup(ctxt, id string) *Message {
+	if e := f.lookup(ctxt, id); e != nil {
+		return e.Message
+	}
+	return nil
+}
+
+func (f *File) lookup(ctxt, id string) *entry {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.stale() {
+		f.mu.RUnlock()
+		f.reindex()
+		f.mu.RLock()
+	}
+	e := f.usable(f.byId[key{ctxt, id}])
+	if e == nil && f.ContextFallback {
+		if ctxt != "" {
+			e = f.usable(f.byId[key{"", id}])
+		} else {
+			e = f.usable(f.anyCtx[id])
+		}
+	}
+	if e == nil && f.normalizer != nil {
+		e = f.usable(f.getNormalized(ctxt, id))
+	}
+	return e
+}
+
+// usable returns the entry, or nil if it is fuzzy and fuzzy messages are not
+// used.
+func (f *File) usable(e *entry) *entry {
+	if e != nil && e.fuzzy && !f.UseFuzzy {
+		return nil
+	}
+	return e
+}
+
+// getByIds returns the message used by the lookups of the msgid in the given
+// context, calling OnMiss unless its i-th plural form is translated.
+func (f *File) getByIds(ctxt, id string, i int) *entry {
+	e := f.lookup(ctxt, id)
+	if !e.translated(i) {
+		if f.OnMiss != nil {
+			f.OnMiss(ctxt, id)
+		}
+		if missed := f.missed.Load(); missed != nil {
+			(*missed)(ctxt, id)
+		}
+	}
+	return e
 }
@@ -2,9 +2,13 @@ package po
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/textproto"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -60,11 +64,11 @@ var file = File{
 		"Project-Id-Version":        {"GNU hello-java 0.19-rc1"},
 		"Report-Msgid-Bugs-To":      {"bug-gnu-gettext@gnu.org"},
 	},
-	Messages: []Message{
+	Messages: []*Message{
 		{
 			Comment: Comment{
 				ExtractedComments: []string{"Example: The set of prime numbers is {2, 3, 5, 7, 11, 13, ...}."},
-				References:        []string{"id=135956960462609535"},
+				References:        []Reference{{File: "id=135956960462609535"}},
 			},
 			Id:  "The set of {$SET_NAME} is {{$XXX}, ...}.",
 			Str: []string{""},
@@ -73,7 +77,7 @@ var file = File{
 		{
 			Comment: Comment{
 				ExtractedComments: nil,
-				References:        []string{"id=176798647517908084", "pluralVar=EGGS_1"},
+				References:        []Reference{{File: "id=176798647517908084"}, {File: "pluralVar=EGGS_1"}},
 			},
 			Ctxt:     "The number of eggs you need.",
 			Id:       "You have one egg",
@@ -87,7 +91,7 @@ var file = File{
 
 		{
 			Comment: Comment{
-				References: []string{"id=123"},
+				References: []Reference{{File: "id=123"}},
 			},
 			Id:  "ID Line 1\nID Line 2\nID Line 3",
 			Str: []string{"STR Line 1\nSTR Line 2\nSTR Line 3"},
@@ -131,3 +135,751 @@ func TestWrite(t *testing.T) {
 		}
 	}
 }
+
+func TestParseFlags(t *testing.T) {
+	var src = "#, fuzzy, c-format\nmsgid \"%d file\"\nmsgstr \"%d Datei\"\n\n"
+	var f, err = Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"fuzzy", "c-format"}; !reflect.DeepEqual(expected, f.Messages[0].Flags) {
+		t.Errorf("expected flags %v, got %v", expected, f.Messages[0].Flags)
+	}
+	var buf bytes.Buffer
+	f.WriteTo(&buf)
+	if buf.String() != src {
+		t.Errorf("expected:\n%v\ngot:\n%v", src, buf.String())
+	}
+}
+
+func TestPGetText(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid "Open"
+msgstr "Otvoriť"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Otvoriť ponuku"
+
+msgctxt "menu"
+msgid "Close"
+msgstr "Zavrieť"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		fallback bool
+		ctxt, id string
+		expected string
+	}{
+		{false, "", "Open", "Otvoriť"},
+		{false, "menu", "Open", "Otvoriť ponuku"},
+		{false, "toolbar", "Open", "Open"},
+		{false, "", "Close", "Close"},
+		{true, "toolbar", "Open", "Otvoriť"},
+		{true, "", "Close", "Zavrieť"},
+	}
+	for _, test := range tests {
+		f.ContextFallback = test.fallback
+		if actual := f.PGetText(test.ctxt, test.id); actual != test.expected {
+			t.Errorf("%v %q %q: expected %q, got %q", test.fallback, test.ctxt, test.id, test.expected, actual)
+		}
+	}
+}
+
+func TestNPGetText(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Language: lv\n"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d fails"
+msgstr[1] "%d faili"
+msgstr[2] "%d failu"
+
+msgctxt "folder"
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d datne"
+msgstr[1] "%d datnes"
+msgstr[2] ""
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		ctxt     string
+		n        int
+		expected string
+	}{
+		{"", 1, "1 fails"},
+		{"", 0, "0 failu"},
+		{"folder", 1, "1 datne"},
+		{"folder", 3, "3 datnes"},
+		{"folder", 0, "0 files"},
+		{"link", 1, "1 file"},
+	}
+	for _, test := range tests {
+		if actual := f.NPGetText(test.ctxt, "%d file", "%d files", test.n, test.n); actual != test.expected {
+			t.Errorf("%q %d: expected %q, got %q", test.ctxt, test.n, test.expected, actual)
+		}
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	var src = "msgid \"Cafe\\u0301\"\nmsgstr \"Cafe\\u0301\"\n"
+	var f, err = ParseWithOptions(strings.NewReader(src), ParseOptions{
+		NFC: strings.NewReplacer("é", "é").Replace,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := f.Messages[0]; msg.Id != "Caf\u00e9" || msg.Str[0] != "Caf\u00e9" {
+		t.Errorf("expected normalized strings, got %q %q", msg.Id, msg.Str)
+	}
+
+	src = "msgid \"Caf\xe9\"\nmsgstr \"\"\n"
+	if _, err = ParseWithOptions(strings.NewReader(src), ParseOptions{ValidateUTF8: true}); err == nil {
+		t.Error("expected an error for invalid UTF-8")
+	}
+	if _, err = Parse(strings.NewReader(src)); err != nil {
+		t.Errorf("expected invalid UTF-8 to be accepted by default, got %v", err)
+	}
+}
+
+func TestEncodePadPlurals(t *testing.T) {
+	var f = &File{
+		Header: textproto.MIMEHeader{"Language": {"sk"}},
+		Messages: []*Message{
+			{Id: "one egg", IdPlural: "%d eggs", Str: []string{"jedno vajce"}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, WriteOptions{PadPlurals: true}).Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	var expected = `
+msgid ""
+msgstr ""
+"Language: sk\n"
+
+msgid "one egg"
+msgid_plural "%d eggs"
+msgstr[0] "jedno vajce"
+msgstr[1] ""
+msgstr[2] ""
+
+`[1:]
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, buf.String())
+	}
+}
+
+func TestEncodeWidth(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "The quick brown fox jumps over the lazy dog.", Str: []string{"Hello\nworld\n"}},
+		{Id: "Quit\n", Str: []string{"Koniec\n"}},
+	}}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, WriteOptions{Width: 40}).Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	var expected = `
+msgid ""
+"The quick brown fox jumps over the "
+"lazy dog."
+msgstr ""
+"Hello\n"
+"world\n"
+
+msgid "Quit\n"
+msgstr "Koniec\n"
+
+`[1:]
+	if buf.String() != expected {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, buf.String())
+	}
+	buf.Reset()
+	if err := NewEncoder(&buf, WriteOptions{Width: 40, NoWrap: true}).Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), `msgid "The quick brown fox jumps over the lazy dog."`+"\n") {
+		t.Errorf("expected the msgid not to be wrapped, got:\n%v", buf.String())
+	}
+}
+
+func TestEncodeSortBy(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+#: b.go:2
+msgid "Save"
+msgstr "Uložiť"
+
+#~ msgid "Close"
+#~ msgstr "Zavrieť"
+
+#: b.go:10 a.go:7
+msgctxt "menu"
+msgid "Open"
+msgstr "Otvoriť"
+
+msgid "Quit"
+msgstr "Koniec"
+
+#: b.go:1
+msgid "Open"
+msgstr "Otvoriť"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order = func(by SortBy) string {
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf, WriteOptions{SortBy: by}).Encode(f); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if strings.HasPrefix(line, "#: ") || strings.HasPrefix(line, "msgctxt ") || strings.Contains(line, "msgid ") {
+				ids = append(ids, line)
+			}
+		}
+		return strings.Join(ids, "|")
+	}
+	for by, expected := range map[SortBy]string{
+		SortByOriginalOrder: `#: b.go:2|msgid "Save"|#~ msgid "Close"|#: b.go:10 a.go:7|msgctxt "menu"|msgid "Open"|msgid "Quit"|#: b.go:1|msgid "Open"`,
+		SortByID:            `#: b.go:1|msgid "Open"|#: b.go:10 a.go:7|msgctxt "menu"|msgid "Open"|msgid "Quit"|#: b.go:2|msgid "Save"|#~ msgid "Close"`,
+		SortByReference:     `msgid "Quit"|#: a.go:7 b.go:10|msgctxt "menu"|msgid "Open"|#: b.go:1|msgid "Open"|#: b.go:2|msgid "Save"|#~ msgid "Close"`,
+	} {
+		if actual := order(by); actual != expected {
+			t.Errorf("%d: expected\n%s\ngot\n%s", by, expected, actual)
+		}
+	}
+	if actual := f.Messages[2].References[0].File; actual != "b.go" {
+		t.Errorf("expected the references of the file left as they are, got %q first", actual)
+	}
+}
+
+func TestParseSparsePlurals(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid "one egg"
+msgid_plural "%d eggs"
+msgstr[2] "%d vajec"
+msgstr[0] "jedno vajce"
+
+msgid "one file"
+msgid_plural "%d files"
+msgstr[0] "jeden súbor"
+msgstr[1] "%d súbory"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var eggs = f.Messages[0]
+	if expected := []string{"jedno vajce", "", "%d vajec"}; !reflect.DeepEqual(expected, eggs.Str) {
+		t.Errorf("expected %q, got %q", expected, eggs.Str)
+	}
+	if expected := []int{2, 0}; !reflect.DeepEqual(expected, eggs.StrIndices) {
+		t.Errorf("expected indices %v, got %v", expected, eggs.StrIndices)
+	}
+	if f.Messages[1].StrIndices != nil {
+		t.Errorf("expected no indices for regular plurals, got %v", f.Messages[1].StrIndices)
+	}
+
+	l, _ := NewLinter("msgstr-index")
+	var problems []string
+	for _, p := range l.Lint(f) {
+		problems = append(problems, p.Text)
+	}
+	if expected := []string{"msgstr[1] is missing", "msgstr indices are out of order: [2 0]"}; !reflect.DeepEqual(expected, problems) {
+		t.Errorf("expected %v, got %v", expected, problems)
+	}
+
+	if _, err = Parse(strings.NewReader("msgid \"a\"\nmsgid_plural \"b\"\nmsgstr[0] \"x\"\nmsgstr[0] \"y\"\n")); err == nil {
+		t.Error("expected an error for a duplicate index")
+	}
+}
+
+func TestEncodeUTF8Charset(t *testing.T) {
+	var f = &File{Header: textproto.MIMEHeader{"Content-Type": {"text/plain; charset=ISO-8859-2"}}}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, WriteOptions{UTF8Charset: true}).Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `"Content-Type: text/plain; charset=UTF-8\n"`; !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected %v in:\n%v", expected, buf.String())
+	}
+	if f.Header.Get("Content-Type") != "text/plain; charset=ISO-8859-2" {
+		t.Error("expected the file header to be left untouched")
+	}
+}
+
+func TestDuplicateHeaderFields(t *testing.T) {
+	var src = `
+msgid ""
+msgstr ""
+"Language: sk\n"
+"X-Generator: Poedit 3.0\n"
+"X-Generator: Weblate 4.0\n"
+
+`[1:]
+	var f, err = Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"Poedit 3.0", "Weblate 4.0"}; !reflect.DeepEqual(expected, f.Header.Values("X-Generator")) {
+		t.Errorf("expected %v, got %v", expected, f.Header.Values("X-Generator"))
+	}
+	var buf bytes.Buffer
+	f.WriteTo(&buf)
+	if buf.String() != src {
+		t.Errorf("expected:\n%v\ngot:\n%v", src, buf.String())
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var src strings.Builder
+	for i := 0; i < 600; i++ {
+		fmt.Fprintf(&src, "msgid \"%d\"\nmsgstr \"\"\n\n", i)
+	}
+	var reports []Progress
+	var f, err = ParseWithOptions(strings.NewReader(src.String()), ParseOptions{
+		Progress: func(p Progress) { reports = append(reports, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 3 || reports[0].Messages != 256 || reports[1].Messages != 512 {
+		t.Errorf("unexpected parse progress %v", reports)
+	}
+	if last := reports[len(reports)-1]; last.Messages != 600 || last.Bytes != int64(src.Len()) {
+		t.Errorf("unexpected final parse progress %v", last)
+	}
+
+	reports = nil
+	var buf bytes.Buffer
+	NewEncoder(&buf, WriteOptions{Progress: func(p Progress) { reports = append(reports, p) }}).Encode(f)
+	if last := reports[len(reports)-1]; len(reports) != 3 || last.Messages != 600 || last.Bytes != int64(buf.Len()) {
+		t.Errorf("unexpected write progress %v", reports)
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Parse(strings.NewReader(po))
+	}
+}
+
+// largePO returns a catalog of n messages like those of applications, with
+// comments, flags, references, plurals and wrapped strings.
+func largePO(n int) string {
+	var b strings.Builder
+	b.WriteString("msgid \"\"\nmsgstr \"\"\n\"Language: sk\\n\"\n\"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\\n\"\n\n")
+	for i := 0; i < n; i++ {
+		switch i % 4 {
+		case 0:
+			fmt.Fprintf(&b, "# Translator note %d\n#. TRANSLATORS: button label\n#: internal/ui/dialog.go:%d internal/ui/menu.go:%d\n#, c-format\nmsgid \"Open file %%s (%d)\"\nmsgstr \"Otvoriť súbor %%s (%d)\"\n\n", i, i, i+1, i, i)
+		case 1:
+			fmt.Fprintf(&b, "#: internal/store/file.go:%d\n#, fuzzy, c-format\nmsgctxt \"menu\"\nmsgid \"%%d file %d\"\nmsgid_plural \"%%d files %d\"\nmsgstr[0] \"%%d súbor %d\"\nmsgstr[1] \"%%d súbory %d\"\nmsgstr[2] \"%%d súborov %d\"\n\n", i, i, i, i, i, i)
+		case 2:
+			fmt.Fprintf(&b, "#: web/templates/help.html:%d\nmsgid \"\"\n\"The quick brown fox jumps over the lazy dog, \"\n\"number %d.\\n\"\nmsgstr \"\"\n\"Rýchla hnedá líška skáče cez lenivého psa, \"\n\"číslo %d.\\n\"\n\n", i, i, i)
+		default:
+			fmt.Fprintf(&b, "#: cmd/app/main.go:%d\nmsgid \"Quit %d\"\nmsgstr \"Koniec %d\"\n\n", i, i, i)
+		}
+	}
+	return b.String()
+}
+
+func TestParseSlabs(t *testing.T) {
+	var f, err = Parse(strings.NewReader(largePO(8)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The slices of the messages share backing arrays, and must not
+	// overwrite each other when appended to.
+	var a, b = f.Messages[0], f.Messages[4]
+	a.Flags = append(a.Flags, "no-wrap")
+	a.Str = append(a.Str, "extra")
+	a.References = append(a.References, Reference{"extra.go", 1})
+	a.TranslatorComments = append(a.TranslatorComments, "extra")
+	if !reflect.DeepEqual(b.Flags, []string{"c-format"}) || len(b.Str) != 1 || b.Str[0] != "Otvoriť súbor %s (4)" ||
+		!reflect.DeepEqual(b.References, []Reference{{"internal/ui/dialog.go", 4}, {"internal/ui/menu.go", 5}}) ||
+		!reflect.DeepEqual(b.TranslatorComments, []string{"Translator note 4"}) {
+		t.Errorf("message overwritten: %+v", b)
+	}
+	if actual := f.Messages[1].Flags; !reflect.DeepEqual(actual, []string{"fuzzy", "c-format"}) {
+		t.Errorf("unexpected flags %q", actual)
+	}
+}
+
+func BenchmarkParseLarge(b *testing.B) {
+	var src = largePO(50000)
+	b.SetBytes(int64(len(src)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(strings.NewReader(src)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWrite(b *testing.B) {
+	var f, _ = Parse(strings.NewReader(po))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.WriteTo(io.Discard)
+	}
+}
+
+func TestGetTextFormat(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "Hello, %s!", Str: []string{"Ahoj, %s!"}},
+		{Id: "Bye", Str: []string{"Zbohom"}},
+	}}
+	f.reindex()
+	if actual := f.GetText("Hello, %s!", "Marcel"); actual != "Ahoj, Marcel!" {
+		t.Errorf("unexpected %q", actual)
+	}
+	if actual := f.GetText("Bye", "ignored"); actual != "Zbohom" {
+		t.Errorf("unexpected %q", actual)
+	}
+	f.Messages[1].Str[0] = "Zbohom, %s"
+	if actual := f.GetText("Bye", "Marcel"); actual != "Zbohom, Marcel" {
+		t.Errorf("expected modified translations to be formatted, got %q", actual)
+	}
+}
+
+func TestGetTextLiteralPercent(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "50% off", Str: []string{"50% zľava"}},
+		{Id: "100%% sure", Str: []string{"100%% istý"}},
+		{Id: "%d item", IdPlural: "%d items", Str: []string{"%d%% položka", "%d%% položky"}},
+	}, Pluralize: PluralSelectorForLanguage("en")}
+	f.reindex()
+	for _, c := range []struct{ actual, expected string }{
+		{f.GetText("50% off"), "50% zľava"},
+		{f.GetText("Missing 5% off"), "Missing 5% off"},
+		{f.PGetText("", "50% off"), "50% zľava"},
+		{f.NGetText("%d item", "%d items", 2), "%d%% položky"},
+		{f.GetTextf("100%% sure"), "100% istý"},
+		{f.PGetTextf("", "100%% sure"), "100% istý"},
+		{f.NGetTextf("%d item", "%d items", 2, 2), "2% položky"},
+		{f.NPGetTextf("", "%d item", "%d items", 1, 1), "1% položka"},
+	} {
+		if c.actual != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, c.actual)
+		}
+	}
+}
+
+func TestOnMiss(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "Open", Str: []string{"Otvoriť"}},
+		{Id: "Save", Str: []string{""}},
+		{Comment: Comment{Flags: []string{"fuzzy"}}, Id: "Close", Str: []string{"Zatvoriť"}},
+		{Ctxt: "menu", Id: "%d file", IdPlural: "%d files", Str: []string{"%d súbor", ""}},
+	}, Pluralize: PluralSelectorForLanguage("en")}
+	f.reindex()
+	var misses []string
+	f.OnMiss = func(ctxt, id string) {
+		misses = append(misses, ctxt+"|"+id)
+	}
+	f.GetText("Open")
+	f.GetText("Save")
+	f.GetText("Close")
+	f.GetText("Quit")
+	f.NPGetText("menu", "%d file", "%d files", 1)
+	f.NPGetText("menu", "%d file", "%d files", 2)
+	f.GetTextData("Save", nil)
+	if f.Lookup("", "Quit") != nil || f.Lookup("", "Save") == nil {
+		t.Error("unexpected Lookup")
+	}
+	var expected = []string{"|Save", "|Close", "|Quit", "menu|%d file", "|Save"}
+	if !reflect.DeepEqual(expected, misses) {
+		t.Errorf("expected %q, got %q", expected, misses)
+	}
+}
+
+func TestGetTextAllocs(t *testing.T) {
+	var f, _ = Parse(strings.NewReader(po))
+	f.ContextFallback = true
+	for name, lookup := range map[string]func(){
+		"GetText":    func() { f.GetText("The set of {$SET_NAME} is {{$XXX}, ...}.") },
+		"PGetText":   func() { f.PGetText("ctxt", "Missing") },
+		"NGetText":   func() { f.NGetText("%d egg", "%d eggs", 2) },
+		"GetTextKey": func() { f.GetTextKey(Key{Ctxt: "ctxt", Id: "Missing"}) },
+	} {
+		if allocs := testing.AllocsPerRun(100, lookup); allocs != 0 {
+			t.Errorf("%s: expected no allocations, got %v", name, allocs)
+		}
+	}
+}
+
+func BenchmarkPGetText(b *testing.B) {
+	var f, _ = Parse(strings.NewReader(po))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.PGetText("ctxt", "The set of {$SET_NAME} is {{$XXX}, ...}.")
+	}
+}
+
+func BenchmarkGetText(b *testing.B) {
+	var f, _ = Parse(strings.NewReader(po))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f.GetText("The set of {$SET_NAME} is {{$XXX}, ...}.")
+	}
+}
+
+func TestGetTextPipeInId(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "a|b", Str: []string{"pipe"}},
+		{Id: "a", IdPlural: "b", Str: []string{"singular", "plural"}},
+	}, Pluralize: PluralSelectorForLanguage("en")}
+	f.reindex()
+	if actual := f.GetText("a|b"); actual != "pipe" {
+		t.Errorf("unexpected %q", actual)
+	}
+	if actual := f.NGetText("a", "b", 1); actual != "singular" {
+		t.Errorf("unexpected %q", actual)
+	}
+}
+
+func TestWriteMinify(t *testing.T) {
+	var f, err = Parse(strings.NewReader(po + obsoletePo[strings.Index(obsoletePo, "#  "):]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	NewEncoder(&buf, WriteOptions{Minify: true}).Encode(f)
+	minified, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(minified.Messages) != 2 || len(minified.Header) != len(f.Header) {
+		t.Fatalf("expected the header and 2 messages, got:\n%s", buf.String())
+	}
+	for _, msg := range minified.Messages {
+		if !reflect.DeepEqual(msg.Comment, Comment{}) {
+			t.Errorf("expected the comments of %q to be stripped, got %v", msg.Id, msg.Comment)
+		}
+		if msg.Str[0] != f.PGetText(msg.Ctxt, msg.Id) {
+			t.Errorf("expected %q to be kept", msg.Id)
+		}
+	}
+}
+
+func TestWriteMinifyFuzzy(t *testing.T) {
+	var f, err = Parse(strings.NewReader("#, fuzzy\nmsgid \"a\"\nmsgstr \"b\"\n\nmsgid \"c\"\nmsgstr \"d\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, useFuzzy := range []bool{false, true} {
+		f.UseFuzzy = useFuzzy
+		var buf bytes.Buffer
+		NewEncoder(&buf, WriteOptions{Minify: true}).Encode(f)
+		minified, err := Parse(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := minified.GetText("a"); actual != f.GetText("a") {
+			t.Errorf("UseFuzzy %v: expected %q, got %q in:\n%s", useFuzzy, f.GetText("a"), actual, buf.String())
+		}
+		if actual := minified.GetText("c"); actual != "d" {
+			t.Errorf("UseFuzzy %v: unexpected %q", useFuzzy, actual)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	var f, _ = Parse(strings.NewReader(po))
+	var c = f.Compile()
+	if c.Len() != 2 {
+		t.Errorf("expected 2 compiled messages, got %d", c.Len())
+	}
+	if actual := c.NPGetText("The number of eggs you need.", "You have one egg", "You have {$EGGS_2} eggs", 3); actual != "zYou zhave zfew zeggs" {
+		t.Errorf("unexpected %q", actual)
+	}
+}
+
+func TestParseLogger(t *testing.T) {
+	var buf bytes.Buffer
+	var logger = slog.New(slog.NewTextHandler(&buf, nil))
+	if _, err := ParseWithOptions(strings.NewReader(po), ParseOptions{Logger: logger, Name: "sk.po"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`msg="po: parsed"`, "file=sk.po", "messages=3", "warnings=0", "duration="} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %s in %q", expected, buf.String())
+		}
+	}
+	buf.Reset()
+	ParseWithOptions(strings.NewReader("msgid \"x\nmsgstr \"\"\n"), ParseOptions{Logger: logger})
+	if !strings.Contains(buf.String(), "level=ERROR") {
+		t.Errorf("expected an error record, got %q", buf.String())
+	}
+}
+
+func TestAddRemoveMessage(t *testing.T) {
+	var f = &File{Pluralize: PluralSelectorForLanguage("en")}
+	f.AddMessage(&Message{Id: "Open", Str: []string{"Otvori"}})
+	f.AddMessage(&Message{Id: "Open", Str: []string{"Otvoriti"}})
+	if len(f.Messages) != 1 || f.GetText("Open") != "Otvoriti" {
+		t.Errorf("expected the message to be replaced, got %v", f.Messages)
+	}
+	if m := f.Lookup("", "Open"); m == nil || m.Str[0] != "Otvoriti" {
+		t.Errorf("expected to look up the message, got %v", m)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			var id = fmt.Sprint("Close ", i)
+			f.AddMessage(&Message{Id: id, Str: []string{"Zatvori"}})
+			f.RemoveMessage("", id)
+		}(i)
+		go func() {
+			defer wg.Done()
+			f.GetText("Open")
+			f.WriteTo(io.Discard)
+		}()
+	}
+	wg.Wait()
+
+	if !f.RemoveMessage("", "Open") || f.RemoveMessage("", "Open") || len(f.Messages) != 0 {
+		t.Errorf("expected the message to be removed once, got %v", f.Messages)
+	}
+	if f.GetText("Open") != "Open" {
+		t.Error("expected the removed message to be missing")
+	}
+}
+
+func TestSet(t *testing.T) {
+	var f = &File{Pluralize: PluralSelectorForLanguage("en")}
+	f.Messages = append(f.Messages, &Message{Id: "Open", Str: []string{"Otvori"}, Comment: Comment{Flags: []string{"no-wrap"}}})
+	if actual := f.GetText("Open"); actual != "Otvori" {
+		t.Errorf("expected appended messages to be indexed, got %q", actual)
+	}
+	var m = f.Set("", "Open", "Otvoriti")
+	if actual := f.GetText("Open"); actual != "Otvoriti" || !m.HasFlag("no-wrap") || len(f.Messages) != 1 {
+		t.Errorf("expected the translation to be set, got %q, %v", actual, f.Messages)
+	}
+	f.Set("menu", "Close", "Zatvori")
+	if actual := f.PGetText("menu", "Close"); actual != "Zatvori" || len(f.Messages) != 2 {
+		t.Errorf("expected the message to be added, got %q, %v", actual, f.Messages)
+	}
+	// Fuzzy messages, and messages only found with ContextFallback, are not
+	// looked up, but are still replaced by a copy.
+	f.Messages = append(f.Messages, &Message{Id: "Quit", Str: []string{"Izlaz"}, Comment: Comment{Flags: []string{"fuzzy"}, References: []Reference{{"main.go", 1}}}})
+	f.ContextFallback = true
+	if m = f.Set("", "Quit", "Zatvori"); !m.HasFlag("fuzzy") || len(m.References) != 1 || len(f.Messages) != 3 {
+		t.Errorf("expected the fuzzy message to be copied, got %v, %v", m, f.Messages)
+	}
+	if m = f.Set("", "Close", "Zatvori"); m.Ctxt != "" || len(f.Messages) != 4 {
+		t.Errorf("expected a message without context to be added, got %v, %v", m, f.Messages)
+	}
+	f.Messages[0] = &Message{Id: "Save", Str: []string{"Spremi"}}
+	f.Reindex()
+	if actual := f.GetText("Save"); actual != "Spremi" {
+		t.Errorf("expected replaced messages to be indexed, got %q", actual)
+	}
+}
+
+func TestUseFuzzy(t *testing.T) {
+	var f, err = Parse(strings.NewReader("#, fuzzy\nmsgid \"Open\"\nmsgstr \"Otvori\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := f.GetText("Open"); actual != "Open" {
+		t.Errorf("expected fuzzy messages to be missing, got %q", actual)
+	}
+	if f.Compile().Len() != 0 {
+		t.Error("expected fuzzy messages not to be compiled")
+	}
+	f.UseFuzzy = true
+	if actual := f.GetText("Open"); actual != "Otvori" {
+		t.Errorf("expected fuzzy messages to be used, got %q", actual)
+	}
+}
+
+func TestParseFunc(t *testing.T) {
+	var f, _ = Parse(strings.NewReader(po))
+	var ids []string
+	var err = ParseFunc(strings.NewReader(po), func(msg *Message) error {
+		ids = append(ids, msg.Id)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != len(f.Messages)+1 || ids[0] != "" || ids[1] != f.Messages[0].Id {
+		t.Errorf("expected the header and %d messages, got %q", len(f.Messages), ids)
+	}
+	var stop = fmt.Errorf("stop")
+	var n int
+	err = ParseFunc(strings.NewReader(po), func(*Message) error {
+		n++
+		return stop
+	})
+	if err != stop || n != 1 {
+		t.Errorf("expected parsing to stop at the first error, got %v after %d messages", err, n)
+	}
+}
+
+func TestHeaderComment(t *testing.T) {
+	var input = `
+# Slovak translation of the menus.
+# Copyright (C) 2016 Example
+#
+# Translators: Jan
+
+# Do not edit by hand.
+#, fuzzy
+msgid ""
+msgstr ""
+"Language: sk\n"
+
+msgid "Open"
+msgstr "Otvori"
+
+#-----------------------------
+# Dialogs
+
+msgid "Close"
+msgstr "Zavri"
+
+# The end.
+`[1:]
+	var f, err = Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []string{
+		"# Slovak translation of the menus.",
+		"# Copyright (C) 2016 Example",
+		"#",
+		"# Translators: Jan",
+		"",
+		"# Do not edit by hand.",
+		"#, fuzzy",
+	}
+	if !reflect.DeepEqual(f.HeaderComment, expected) {
+		t.Errorf("expected the header comment %q, got %q", expected, f.HeaderComment)
+	}
+	if f.Header.Get("Language") != "sk" || len(f.Messages) != 2 {
+		t.Fatalf("expected the header and 2 messages, got %v %v", f.Header, f.Messages)
+	}
+	var buf bytes.Buffer
+	if _, err = f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != input {
+		t.Errorf("expected:\n%v\ngot:\n%v", input, buf.String())
+	}
+}
@@ -0,0 +1,111 @@
+package po
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// PseudoOptions configure the pseudo-localization of catalogs.
+type PseudoOptions struct {
+	// Prefix and Suffix mark the start and end of each translation, so that
+	// strings left in the source language, or truncated, stand out.
+	Prefix, Suffix string
+	// Expansion lengthens the translations by the given fraction of their
+	// length, padding them with tildes, to reveal layouts that cannot hold
+	// languages longer than the source, e.g. 0.3 for 30%.
+	Expansion float64
+	// Accents replaces the ASCII letters with accented ones, e.g. "Ŏƥéñ" for
+	// "Open", to reveal text not rendered in Unicode.
+	Accents bool
+	// Translations pseudo-localizes the translations of the catalog rather
+	// than its source strings, leaving the untranslated messages as they
+	// are.
+	Translations bool
+}
+
+// DefaultPseudoOptions are the usual pseudo-localization options.
+var DefaultPseudoOptions = PseudoOptions{Prefix: "[", Suffix: "]", Expansion: 0.3, Accents: true}
+
+// pseudoKeepRe matches the parts of strings left as they are: the
+// placeholders, HTML tags and entities.
+var pseudoKeepRe = regexp.MustCompile(placeholderRe.String() + `|<[^<>]*>|&#?\w+;`)
+
+// pseudoAccents maps the ASCII letters to accented ones.
+var pseudoAccents = map[rune]rune{
+	'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Đ', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ŏ', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Š', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+	'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'đ', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ṁ', 'n': 'ñ', 'o': 'ö', 'p': 'ƥ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'š', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+}
+
+// Pseudolocalize fills in the translations of the messages with
+// pseudo-translations of their source strings, or of their translations if
+// opts.Translations is set, so that QA can spot hardcoded strings and layout
+// issues without real translations. Placeholders, HTML tags and entities are
+// left as they are. Obsolete messages and the header are left out, and the
+// fuzzy flags removed.
+func (f *File) Pseudolocalize(opts PseudoOptions) {
+	var nplurals = f.nplurals()
+	for _, m := range f.Messages {
+		if m.Obsolete || m.Id == "" && m.Ctxt == "" {
+			continue
+		}
+		if opts.Translations {
+			for i, str := range m.Str {
+				if str != "" {
+					m.Str[i] = pseudolocalize(str, opts)
+				}
+			}
+			continue
+		}
+		if m.IdPlural == "" {
+			m.Str = []string{pseudolocalize(m.Id, opts)}
+		} else {
+			m.Str = make([]string, nplurals)
+			for i := range m.Str {
+				var src = m.IdPlural
+				if i == 0 {
+					src = m.Id
+				}
+				m.Str[i] = pseudolocalize(src, opts)
+			}
+		}
+		m.Flags = removeFlag(m.Flags, "fuzzy")
+	}
+	f.reindex()
+}
+
+// pseudolocalize returns the pseudo-translation of s.
+func pseudolocalize(s string, opts PseudoOptions) string {
+	var b strings.Builder
+	b.WriteString(opts.Prefix)
+	var last = 0
+	var accent = func(text string) {
+		if !opts.Accents {
+			b.WriteString(text)
+			return
+		}
+		for _, r := range text {
+			if a, found := pseudoAccents[r]; found {
+				r = a
+			}
+			b.WriteRune(r)
+		}
+	}
+	for _, loc := range pseudoKeepRe.FindAllStringIndex(s, -1) {
+		accent(s[last:loc[0]])
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	accent(s[last:])
+	if opts.Expansion > 0 {
+		var n = utf8.RuneCountInString(pseudoKeepRe.ReplaceAllString(s, ""))
+		b.WriteString(strings.Repeat("~", int(math.Ceil(float64(n)*opts.Expansion))))
+	}
+	b.WriteString(opts.Suffix)
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package po
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPseudolocalize(t *testing.T) {
+	f, err := Parse(strings.NewReader(`msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+msgid "Open"
+msgstr ""
+
+#, fuzzy, c-format
+msgid "Hello, %s! See <a href=\"/x\">{name}</a> &amp; more."
+msgstr "Ahoj"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+
+#~ msgid "Old"
+#~ msgstr ""
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Pseudolocalize(DefaultPseudoOptions)
+	for _, c := range []struct {
+		id       string
+		expected []string
+		flags    []string
+	}{
+		{"Open", []string{"[Ŏƥéñ~~]"}, nil},
+		{"Hello, %s! See <a href=\"/x\">{name}</a> &amp; more.", []string{"[Ĥéļļö, %s! Šéé <a href=\"/x\">{name}</a> &amp; ṁöŕé.~~~~~~]"}, []string{"c-format"}},
+		{"%d file", []string{"[%d ƒîļé~~]", "[%d ƒîļéš~~]", "[%d ƒîļéš~~]"}, nil},
+	} {
+		var m = f.Lookup("", c.id)
+		if m == nil {
+			t.Fatalf("%s: missing", c.id)
+		}
+		if !reflect.DeepEqual(c.expected, m.Str) || !reflect.DeepEqual(c.flags, m.Flags) {
+			t.Errorf("%s: expected %q %q, got %q %q", c.id, c.expected, c.flags, m.Str, m.Flags)
+		}
+	}
+	if actual := f.GetText("Open"); actual != "[Ŏƥéñ~~]" {
+		t.Errorf("unexpected lookup %q", actual)
+	}
+	if old := f.Messages[len(f.Messages)-1]; !old.Obsolete || old.Str[0] != "" {
+		t.Errorf("unexpected obsolete message %+v", old)
+	}
+
+	f.Messages[0].Str = []string{""}
+	f.Messages[1].Str = []string{"Ahoj, %s!"}
+	f.Pseudolocalize(PseudoOptions{Prefix: "⟦", Suffix: "⟧", Translations: true})
+	if !reflect.DeepEqual([]string{""}, f.Messages[0].Str) || !reflect.DeepEqual([]string{"⟦Ahoj, %s!⟧"}, f.Messages[1].Str) {
+		t.Errorf("unexpected translations %q, %q", f.Messages[0].Str, f.Messages[1].Str)
+	}
+}
@@ -0,0 +1,194 @@
+package po
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Problem describes an issue found in a file by a check.
+type Problem struct {
+	Rule     string   // identifier of the check that reported the problem
+	Severity Severity // how serious the problem is
+	Msg      *Message // message the problem was found in, nil for the header
+	Line     int      // line of the message in the parsed file, 0 if unknown
+	Text     string   // human readable description
+}
+
+func (p Problem) String() string {
+	var where = "header"
+	if p.Msg != nil {
+		where = strconv.Quote(p.Msg.Id)
+	}
+	if p.Line > 0 {
+		where = fmt.Sprintf("line %d: %s", p.Line, where)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", where, p.Severity, p.Rule, p.Text)
+}
+
+// SpellChecker is implemented by spelling backends, e.g. hunspell or aspell
+// bindings, or a web service.
+type SpellChecker interface {
+	// Misspellings returns the misspelled words in text, which is written in
+	// the language identified by lang (e.g. "pt_BR").
+	Misspellings(lang, text string) ([]string, error)
+}
+
+// SpellCheck passes every translated string in the file through sc, with
+// placeholders stripped, and reports a problem for each misspelled word. It
+// stops at the first error of sc, returned with the problems found so far.
+func (f *File) SpellCheck(sc SpellChecker) ([]Problem, error) {
+	var err error
+	var rule = NewRule("spelling", Warning, func(c *RuleContext) {
+		if err == nil {
+			err = spellCheck(c, sc)
+		}
+	})
+	var problems = (&Linter{[]Rule{rule}}).Lint(f)
+	return problems, err
+}
+
+// SpellRule returns a rule checking the spelling of the translations as
+// SpellCheck does, reporting the errors of sc as problems. It is not
+// registered by default, as it needs a backend.
+func SpellRule(sc SpellChecker) Rule {
+	return NewRule("spelling", Warning, func(c *RuleContext) {
+		if err := spellCheck(c, sc); err != nil {
+			c.Report("spell checker failed: %v", err)
+		}
+	})
+}
+
+// spellCheck reports the misspelled words of the translations of the
+// message, or returns the error of sc.
+func spellCheck(c *RuleContext, sc SpellChecker) error {
+	for _, str := range c.Msg.Str {
+		if str == "" {
+			continue
+		}
+		words, err := sc.Misspellings(c.Lang, StripPlaceholders(str))
+		if err != nil {
+			return err
+		}
+		for _, word := range words {
+			c.Report("misspelled word %s", word)
+		}
+	}
+	return nil
+}
+
+// placeholderRe matches printf verbs (e.g. "%s", "%[1]d", "%5.2f") and
+// brace-delimited placeholders (e.g. "{name}", "{$EGGS_2}").
+var placeholderRe = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*(\d+|\*)?(\.(\d+|\*)?)?(\[\d+\])?[a-zA-Z%]|\{[^{}]*\}`)
+
+// StripPlaceholders replaces the placeholders in s with spaces, leaving only
+// the natural language text.
+func StripPlaceholders(s string) string {
+	return placeholderRe.ReplaceAllString(s, " ")
+}
+
+// FlagNoTranslate marks a message whose translation is intentionally
+// identical to its msgid, e.g. a product name.
+const FlagNoTranslate = "no-translate"
+
+// CheckIdentical reports non-trivial translations that are byte-identical to
+// their msgid, which usually means the source text was copied through by
+// accident. Files in English, and messages flagged FlagNoTranslate, are not
+// checked.
+func (f *File) CheckIdentical() []Problem {
+	return (&Linter{[]Rule{identicalRule}}).Lint(f)
+}
+
+var identicalRule = NewRule("identical", Warning, func(c *RuleContext) {
+	if c.Lang == "" || c.Lang == "en" || strings.HasPrefix(c.Lang, "en_") || strings.HasPrefix(c.Lang, "en-") {
+		return
+	}
+	if c.Msg.HasFlag(FlagNoTranslate) {
+		return
+	}
+	for i, str := range c.Msg.Str {
+		if str == c.Msg.source(i) && isNonTrivial(str) {
+			c.Report("translation is identical to the source text")
+			return
+		}
+	}
+})
+
+// isNonTrivial returns true if s, without placeholders, has enough text to
+// require a translation: at least four letters, not all of them upper case.
+func isNonTrivial(s string) bool {
+	var letters, lower int
+	for _, r := range StripPlaceholders(s) {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+		if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	return letters >= 4 && lower > 0
+}
+
+// MaxLengthPrefix starts the flag or extracted comment that limits the length
+// of a message's translations, e.g. "#, max-length:20" or "#. max-length: 20".
+// The limit may also be given by an extension comment, "#% max-length=20".
+const MaxLengthPrefix = "max-length:"
+
+// MaxLength returns the maximum number of characters allowed in the
+// message's translations, if limited.
+func (c Comment) MaxLength() (int, bool) {
+	for _, lines := range [][]string{c.Flags, c.ExtractedComments} {
+		for _, line := range lines {
+			if strings.HasPrefix(line, MaxLengthPrefix) {
+				if n, err := strconv.Atoi(strings.TrimSpace(line[len(MaxLengthPrefix):])); err == nil && n >= 0 {
+					return n, true
+				}
+			}
+		}
+	}
+	if v, found := c.Extension("max-length"); found {
+		if n, ok := v.(int); ok {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+var maxLengthRule = NewRule("max-length", Error, func(c *RuleContext) {
+	var max, limited = c.Msg.MaxLength()
+	if !limited {
+		return
+	}
+	for i, str := range c.Msg.Str {
+		if n := utf8.RuneCountInString(str); n > max {
+			c.Report("msgstr[%d] is %d characters long, the limit is %d", i, n, max)
+		}
+	}
+})
+
+var msgstrIndexRule = NewRule("msgstr-index", Warning, func(c *RuleContext) {
+	if c.Msg.StrIndices == nil {
+		return
+	}
+	for i := range c.Msg.Str {
+		if !containsInt(c.Msg.StrIndices, i) {
+			c.Report("msgstr[%d] is missing", i)
+		}
+	}
+	if !sort.IntsAreSorted(c.Msg.StrIndices) {
+		c.Report("msgstr indices are out of order: %v", c.Msg.StrIndices)
+	}
+})
+
+func containsInt(vals []int, val int) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package po
+
+import (
+	"errors"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStripPlaceholders(t *testing.T) {
+	var tests = []struct {
+		in, expected string
+	}{
+		{"Hello, %s!", "Hello,  !"},
+		{"%[2]d of %5.2f", "  of  "},
+		{"You have {$EGGS_2} eggs", "You have   eggs"},
+		{"50%% off", "50  off"},
+		{"No placeholders", "No placeholders"},
+	}
+	for _, test := range tests {
+		if actual := StripPlaceholders(test.in); actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.in, test.expected, actual)
+		}
+	}
+}
+
+type dictChecker map[string]bool
+
+func (d dictChecker) Misspellings(lang, text string) ([]string, error) {
+	var r []string
+	for _, word := range strings.Fields(text) {
+		if !d[word] {
+			r = append(r, word)
+		}
+	}
+	return r, nil
+}
+
+func TestSpellCheck(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "%d apples", Str: []string{"%d jablká"}},
+		{Id: "Hello", Str: []string{"Ahojj"}},
+		{Id: "Untranslated", Str: []string{""}},
+	}}
+	var problems = (&Linter{[]Rule{SpellRule(dictChecker{"jablká": true})}}).Lint(f)
+	var words []string
+	for _, p := range problems {
+		words = append(words, p.Text)
+	}
+	if expected := []string{"misspelled word Ahojj"}; !reflect.DeepEqual(expected, words) {
+		t.Errorf("expected %v, got %v", expected, words)
+	}
+}
+
+type failingChecker struct{}
+
+func (failingChecker) Misspellings(lang, text string) ([]string, error) {
+	return nil, errors.New("no dictionary for " + lang)
+}
+
+func TestFileSpellCheck(t *testing.T) {
+	var f = &File{Header: textproto.MIMEHeader{"Language": {"sk"}}, Messages: []*Message{
+		{Id: "Hello", Str: []string{"Ahojj"}},
+	}}
+	problems, err := f.SpellCheck(dictChecker{})
+	if err != nil || len(problems) != 1 || problems[0].Text != "misspelled word Ahojj" {
+		t.Errorf("unexpected problems %v, %v", problems, err)
+	}
+	if _, err = f.SpellCheck(failingChecker{}); err == nil || err.Error() != "no dictionary for sk" {
+		t.Errorf("expected the error of the backend, got %v", err)
+	}
+}
+
+func TestCheckTypography(t *testing.T) {
+	var tests = []struct {
+		lang     string
+		id, str  string
+		expected []string
+	}{
+		{"de", "Save.", "Speichern.", nil},
+		{"de", "Save", "Speichern  jetzt", []string{"double-space"}},
+		{"de", "Save", "Speichern ", []string{"trailing-space"}},
+		{"de", "Save", "Speichern.", []string{"punctuation"}},
+		{"de", "Quit?", "Beenden", []string{"punctuation"}},
+		{"ja", "Quit?", "終了しますか？", nil},
+		{"el", "Quit?", "Έξοδος;", nil},
+		{"de", `Say "hi"`, `Sag „hi"`, []string{"quotes"}},
+		{"de", "Open file", "Datei datei öffnen", []string{"repeated-word"}},
+	}
+	for _, test := range tests {
+		var f = &File{Messages: []*Message{{Id: test.id, Str: []string{test.str}}}}
+		var rules []string
+		for _, p := range f.CheckTypography(TypographyForLanguage(test.lang)) {
+			rules = append(rules, p.Rule)
+		}
+		if !reflect.DeepEqual(test.expected, rules) {
+			t.Errorf("%s %q: expected %v, got %v", test.lang, test.str, test.expected, rules)
+		}
+	}
+}
+
+func TestCheckIdentical(t *testing.T) {
+	var f = &File{
+		Header: textproto.MIMEHeader{"Language": {"de"}},
+		Messages: []*Message{
+			{Id: "Open file", Str: []string{"Open file"}},
+			{Id: "Firefox", Str: []string{"Firefox"}, Comment: Comment{Flags: []string{FlagNoTranslate}}},
+			{Id: "OK", Str: []string{"OK"}},
+			{Id: "%s: %d", Str: []string{"%s: %d"}},
+			{Id: "Save", Str: []string{"Speichern"}},
+		},
+	}
+	var problems = f.CheckIdentical()
+	if len(problems) != 1 || problems[0].Msg != f.Messages[0] {
+		t.Errorf("expected only %q to be reported, got %v", f.Messages[0].Id, problems)
+	}
+
+	f.Header.Set("Language", "en_GB")
+	if problems = f.CheckIdentical(); len(problems) != 0 {
+		t.Errorf("expected no problems for English, got %v", problems)
+	}
+}
+
+func TestLinter(t *testing.T) {
+	if _, err := NewLinter("punctuation", "no-such-rule"); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+
+	var f = &File{
+		Header: textproto.MIMEHeader{"Language": {"de"}},
+		Messages: []*Message{
+			{Id: "Open file", Str: []string{"Open file."}},
+			{Id: "Save", Str: []string{"SPEICHERN"}},
+		},
+	}
+	var shouting = NewRule("shouting", Error, func(c *RuleContext) {
+		for _, str := range c.Msg.Str {
+			if str != "" && str == strings.ToUpper(str) {
+				c.Report("translation is all caps")
+			}
+		}
+	})
+	l, err := NewLinter("identical", "punctuation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Rules = append(l.Rules, shouting)
+
+	var actual []string
+	for _, p := range l.Lint(f) {
+		actual = append(actual, p.Severity.String()+" "+p.Rule)
+	}
+	var expected = []string{"warning punctuation", "error shouting"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestLintSuppression(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Language: de\n"
+
+#, ignore-check: punctuation
+msgid "Open file"
+msgstr "Datei öffnen."
+
+#. ignore-check: quotes identical
+msgid "Save"
+msgstr "Speichern."
+
+#, ignore-check: spelling
+msgid "Close"
+msgstr "Schließen"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, _ := NewLinter()
+	var actual []string
+	for _, p := range l.Lint(f) {
+		actual = append(actual, p.Msg.Id+": "+p.Text)
+	}
+	var expected = []string{
+		"Save: translation should not end with .",
+		"Save: unnecessary suppression of rule quotes",
+		"Save: unnecessary suppression of rule identical",
+		"Close: suppression of unknown rule spelling",
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestMaxLength(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "Buy", Str: []string{"Kaufen"}, Comment: Comment{Flags: []string{"max-length:5"}}},
+		{Id: "Buy now", Str: []string{"Jetzt"}, Comment: Comment{ExtractedComments: []string{"max-length: 5"}}},
+		{Id: "Sell", Str: []string{"Verkaufen"}},
+	}}
+	l, _ := NewLinter("max-length")
+	var problems = l.Lint(f)
+	if len(problems) != 1 || problems[0].Msg != f.Messages[0] || problems[0].Severity != Error {
+		t.Errorf("expected an error for %q, got %v", f.Messages[0].Id, problems)
+	}
+	if f, err := Parse(strings.NewReader("#% max-length=5\nmsgid \"Buy\"\nmsgstr \"Kaufen\"\n")); err != nil {
+		t.Fatal(err)
+	} else if problems = l.Lint(f); len(problems) != 1 || problems[0].Msg != f.Messages[0] {
+		t.Errorf("expected an error for the max-length extension, got %v", problems)
+	}
+}
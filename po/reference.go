@@ -0,0 +1,136 @@
+package po
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Reference is a source location of a message, as in "#: src/main.go:12".
+type Reference struct {
+	File string
+	Line int // 0 if the reference has no line
+}
+
+// ParseReference parses a reference, e.g. "src/main.go:12". The line follows
+// the last colon, so that the colons of Windows paths, e.g.
+// "C:\src\main.go:12", belong to the file. References without a line, or
+// with something else than one after the last colon, e.g. "id=42", are kept
+// whole as the file.
+func ParseReference(s string) Reference {
+	if i, line := referenceLine([]byte(s)); i != -1 {
+		return Reference{s[:i], line}
+	}
+	return Reference{File: s}
+}
+
+// maxReferenceLine bounds the lines of references, whose larger numbers are
+// taken as part of the file.
+const maxReferenceLine = 1 << 30
+
+// referenceLine returns the index of the colon before the line of the
+// reference, and the line, or -1 if it has no line.
+func referenceLine(ref []byte) (int, int) {
+	var i = bytes.LastIndexByte(ref, ':')
+	if i <= 0 || i == len(ref)-1 {
+		return -1, 0
+	}
+	var line = 0
+	for _, c := range ref[i+1:] {
+		if c < '0' || c > '9' || line > maxReferenceLine/10 {
+			return -1, 0
+		}
+		line = line*10 + int(c-'0')
+	}
+	if line == 0 {
+		return -1, 0
+	}
+	return i, line
+}
+
+// String returns the reference as written in PO files.
+func (r Reference) String() string {
+	if r.Line <= 0 {
+		return r.File
+	}
+	return r.File + ":" + strconv.Itoa(r.Line)
+}
+
+// parseReferences parses the references of a "#:" comment.
+func parseReferences(refs []string) []Reference {
+	if refs == nil {
+		return nil
+	}
+	var r = make([]Reference, len(refs))
+	for i, ref := range refs {
+		r[i] = ParseReference(ref)
+	}
+	return r
+}
+
+// referenceStrings returns the references as written in PO files.
+func referenceStrings(refs []Reference) []string {
+	if refs == nil {
+		return nil
+	}
+	var r = make([]string, len(refs))
+	for i, ref := range refs {
+		r[i] = ref.String()
+	}
+	return r
+}
+
+// appendMissingReferences appends the references of add missing from refs.
+func appendMissingReferences(refs, add []Reference) []Reference {
+	for _, ref := range add {
+		if !containsReference(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// sortReferences returns a copy of the references, sorted by file, then
+// line.
+func sortReferences(refs []Reference) []Reference {
+	var r = append([]Reference(nil), refs...)
+	sort.SliceStable(r, func(i, j int) bool { return compareReferences(r[i], r[j]) < 0 })
+	return r
+}
+
+// firstReference returns the least of the references, if any.
+func firstReference(refs []Reference) (Reference, bool) {
+	if len(refs) == 0 {
+		return Reference{}, false
+	}
+	var r = refs[0]
+	for _, ref := range refs[1:] {
+		if compareReferences(ref, r) < 0 {
+			r = ref
+		}
+	}
+	return r, true
+}
+
+// compareReferences compares references by file, then line.
+func compareReferences(a, b Reference) int {
+	switch {
+	case a.File != b.File:
+		return strings.Compare(a.File, b.File)
+	case a.Line < b.Line:
+		return -1
+	case a.Line > b.Line:
+		return 1
+	}
+	return 0
+}
+
+func containsReference(refs []Reference, ref Reference) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package po
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	for s, expected := range map[string]Reference{
+		"src/main.go:12":    {"src/main.go", 12},
+		"src/main.go":       {File: "src/main.go"},
+		`C:\src\main.go:12`: {`C:\src\main.go`, 12},
+		`C:\src\main.go`:    {File: `C:\src\main.go`},
+		"id=42":             {File: "id=42"},
+		"a:b":               {File: "a:b"},
+		"a:0":               {File: "a:0"},
+		"a:+1":              {File: "a:+1"},
+		":12":               {File: ":12"},
+	} {
+		var actual = ParseReference(s)
+		if actual != expected {
+			t.Errorf("%q: expected %#v, got %#v", s, expected, actual)
+		}
+		if actual.String() != s {
+			t.Errorf("%q: formatted as %q", s, actual.String())
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`#: main.go:3 C:\src\app.go:12 id=42
+msgid "Open"
+msgstr "Otvoriť"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = []Reference{{"main.go", 3}, {`C:\src\app.go`, 12}, {File: "id=42"}}
+	if actual := f.Messages[0].References; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+	f.Messages[0].References = append(f.Messages[0].References, Reference{"ui/menu.go", 7})
+	var buf bytes.Buffer
+	if _, err := f.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`#: main.go:3 C:\src\app.go:12 id=42 ui/menu.go:7`)) {
+		t.Errorf("references not written:\n%s", buf.String())
+	}
+}
@@ -3,21 +3,158 @@ package po
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"io"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // scanner scans the message fields of a po file.
 // it is a mirror of the writer.
 type scanner struct {
 	*bufio.Scanner
-	hasNext bool
-	err     error
+	hasNext      bool
+	err          error
+	line         int  // number of the current line
+	validateUTF8 bool // report lines that are not valid UTF-8
+	irregular    []int
+	warnings     []Problem
+	text         []byte // current line, without the obsolete marker
+	obsolete     bool   // the current line is marked obsolete
+	raw          []byte // comment and blank lines read, as written
+	rawEnds      []int  // offsets of the ends of the lines in raw
+	nraw         int    // number of raw lines at the end of the comments of the last message
+	str          string // current line, once converted to a string
+	hasStr       bool   // str is that of the current line
+
+	// The buffers below are reused from message to message, so that parsing
+	// allocates little more than the strings of the messages.
+	buf      []byte            // quoted string being unquoted
+	tmp      []string          // list being read
+	tmpRefs  []Reference       // references being read
+	order    []int             // msgstr indices being read
+	interned map[string]string // contexts, flags and file names read
+	strSlab  []string          // backing array of the lists of the messages
+	refSlab  []Reference       // backing array of the references of the messages
 }
 
 func newScanner(r io.Reader) *scanner {
-	return &scanner{bufio.NewScanner(r), true, nil}
+	return &scanner{Scanner: bufio.NewScanner(r), hasNext: true, interned: make(map[string]string)}
+}
+
+// Scan advances to the next line.
+func (s *scanner) Scan() bool {
+	if !s.Scanner.Scan() {
+		s.text, s.obsolete, s.hasStr = nil, false, false
+		return false
+	}
+	s.line++
+	s.text, s.obsolete, s.hasStr = s.Scanner.Bytes(), false, false
+	if bytes.HasPrefix(s.text, []byte(obsoletePrefix)) {
+		s.text, s.obsolete = s.text[len(obsoletePrefix):], true
+	} else if bytes.HasPrefix(s.text, []byte(obsoletePrevPrefix)) {
+		// "#~| msgid ..." holds a previous field of an obsolete message.
+		s.text, s.obsolete = append([]byte("#"), s.text[len(obsoletePrevPrefix)-1:]...), true
+	}
+	if !s.obsolete && s.prefix("#") {
+		s.raw = append(s.raw, s.text...)
+		s.rawEnds = append(s.rawEnds, len(s.raw))
+	} else if !s.obsolete && len(bytes.TrimSpace(s.text)) == 0 {
+		s.rawEnds = append(s.rawEnds, len(s.raw))
+	}
+	if s.validateUTF8 && s.err == nil && !utf8.Valid(s.Bytes()) {
+		s.error("invalid UTF-8", nil)
+	}
+	return true
+}
+
+// obsoletePrefix marks the lines of obsolete messages, and obsoletePrevPrefix
+// their previous fields.
+const (
+	obsoletePrefix     = "#~ "
+	obsoletePrevPrefix = "#~|"
+)
+
+// rawLines returns the first n raw lines. They are only converted to strings
+// when kept, e.g. for free-standing comments.
+func (s *scanner) rawLines(n int) []string {
+	var r = make([]string, n)
+	var start = 0
+	for i, end := range s.rawEnds[:n] {
+		r[i] = string(s.raw[start:end])
+		start = end
+	}
+	return r
+}
+
+// resetRaw discards the raw lines read.
+func (s *scanner) resetRaw() {
+	s.raw, s.rawEnds = s.raw[:0], s.rawEnds[:0]
+}
+
+// Bytes returns the current line, without the obsolete marker.
+func (s *scanner) Bytes() []byte {
+	return s.text
+}
+
+// Text returns the current line, without the obsolete marker.
+func (s *scanner) Text() string {
+	if !s.hasStr {
+		s.str, s.hasStr = string(s.text), true
+	}
+	return s.str
+}
+
+// isObsolete returns true if the current line is marked obsolete.
+func (s *scanner) isObsolete() bool {
+	return s.obsolete
+}
+
+// message reads the fields of the message at the current line into msg.
+// A line that begins no field and is not a comment is a syntax error.
+func (s *scanner) message(msg *Message) {
+	var start = s.line
+	defer func() {
+		if s.line == start && s.text != nil && !s.prefix("#") {
+			s.errorAt(0, fmt.Sprintf("unexpected %q", s.text), nil)
+		}
+	}()
+	// NOTE: the source code order of these fields is important.
+	var c = Comment{
+		TranslatorComments: s.mul("# "),
+		ExtractedComments:  s.mul("#."),
+		Extensions:         s.ext("#%"),
+		References:         s.refs("#:"),
+		Flags:              s.csv("#,"),
+		PrevCtxt:           s.one("#| msgctxt"),
+		PrevId:             s.one("#| msgid"),
+		PrevIdPlural:       s.one("#| msgid_plural"),
+	}
+	s.nraw = len(s.rawEnds)
+	if s.text != nil && (s.prefix("#") || len(bytes.TrimSpace(s.text)) == 0) {
+		s.nraw-- // the current line is not a comment of the message
+	}
+	*msg = Message{
+		Comment:    c,
+		Ctxt:       s.ctxt(),
+		Obsolete:   s.isObsolete(),
+		Id:         s.quo("msgid"),
+		IdPlural:   s.quo("msgid_plural"),
+		Str:        s.msgstr(),
+		StrIndices: s.indices(),
+	}
+}
+
+// skipmsg discards the rest of the current message, up to the next blank
+// line, and returns the error found in it, clearing it.
+func (s *scanner) skipmsg() *ParseError {
+	for s.text != nil && len(bytes.TrimSpace(s.text)) > 0 && s.Scan() {
+	}
+	var err = s.err.(*ParseError)
+	s.err = nil
+	return err
 }
 
 // nextmsg goes to the next message, skipping blank lines in between.
@@ -38,25 +175,117 @@ func (s *scanner) nextmsg() bool {
 }
 
 func (s *scanner) mul(prefix string) []string {
-	var r []string
+	var r = s.tmp[:0]
 	for s.prefix(prefix) {
 		r = append(r, s.txt(prefix))
 		if !s.Scan() {
 			break
 		}
 	}
+	s.tmp = r
+	return s.strings(r)
+}
+
+// ext reads the extension comments, parsing them with their directives.
+func (s *scanner) ext(prefix string) []Extension {
+	var r []Extension
+	for s.prefix(prefix) {
+		var ext, err = parseExtension(s.txt(prefix))
+		if err != nil {
+			s.warn("extension", err.Error())
+		}
+		r = append(r, ext)
+		if !s.Scan() {
+			break
+		}
+	}
 	return r
 }
 
-func (s *scanner) spc(prefix string) []string {
-	var r []string
+// refs reads the space separated references, e.g. "main.go:12 util.go:3".
+func (s *scanner) refs(prefix string) []Reference {
+	var r = s.tmpRefs[:0]
+	if s.prefix(prefix) {
+		var line = s.text[len(prefix):]
+		for {
+			if line = bytes.TrimLeftFunc(line, unicode.IsSpace); len(line) == 0 {
+				break
+			}
+			var end = bytes.IndexFunc(line, unicode.IsSpace)
+			if end == -1 {
+				end = len(line)
+			}
+			var field = line[:end]
+			if i, n := referenceLine(field); i != -1 {
+				r = append(r, Reference{s.intern(field[:i]), n})
+			} else {
+				r = append(r, Reference{File: s.intern(field)})
+			}
+			line = line[end:]
+		}
+		s.Scan()
+	}
+	s.tmpRefs = r
+	if len(r) == 0 {
+		return nil
+	}
+	if len(s.refSlab) < len(r) {
+		s.refSlab = make([]Reference, slabSize+len(r))
+	}
+	var refs = s.refSlab[:len(r):len(r)]
+	s.refSlab = s.refSlab[len(r):]
+	copy(refs, r)
+	return refs
+}
+
+// csv reads a comma separated list of values, e.g. the flags.
+func (s *scanner) csv(prefix string) []string {
+	var r = s.tmp[:0]
 	if s.prefix(prefix) {
-		r = append(r, strings.Fields(s.txt(prefix))...)
+		var line = s.text[len(prefix):]
+		for len(line) > 0 {
+			var val = line
+			if i := bytes.IndexByte(line, ','); i != -1 {
+				val, line = line[:i], line[i+1:]
+			} else {
+				line = nil
+			}
+			if val = bytes.TrimSpace(val); len(val) > 0 {
+				r = append(r, s.intern(val))
+			}
+		}
 		s.Scan()
 	}
+	s.tmp = r
+	return s.strings(r)
+}
+
+// strings returns a copy of the list, allocated from a slab shared by the
+// messages, and capped so that appending to it reallocates.
+func (s *scanner) strings(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	if len(s.strSlab) < len(list) {
+		s.strSlab = make([]string, slabSize+len(list))
+	}
+	var r = s.strSlab[:len(list):len(list)]
+	s.strSlab = s.strSlab[len(list):]
+	copy(r, list)
 	return r
 }
 
+// intern returns b as a string shared by all its occurrences, for the values
+// repeated across messages.
+func (s *scanner) intern(b []byte) string {
+	if str, found := s.interned[string(b)]; found {
+		return str
+	}
+	var str = string(b)
+	s.interned[str] = str
+	return str
+}
+
 func (s *scanner) one(prefix string) string {
 	var r string
 	if s.prefix(prefix) {
@@ -69,48 +298,128 @@ func (s *scanner) one(prefix string) string {
 // quo reads a quoted string after the given prefix.
 // multiline strings are handled.
 func (s *scanner) quo(prefix string) string {
-	var r string
-	if s.prefix(prefix) {
-		r = s.unquote(s.txt(prefix))
-		for {
-			if !s.Scan() {
-				return r
-			}
-			if len(s.Bytes()) > 0 && s.Bytes()[0] == '"' {
-				r += s.unquote(s.Text())
-				continue
-			}
-			break
-		}
+	if !s.prefix(prefix) {
+		return ""
+	}
+	s.quoted(len(prefix))
+	return string(s.buf)
+}
+
+// ctxt reads the msgctxt, interned as contexts are shared by messages.
+func (s *scanner) ctxt() string {
+	if !s.prefix("msgctxt") {
+		return ""
+	}
+	s.quoted(len("msgctxt"))
+	return s.intern(s.buf)
+}
+
+// quoted unquotes the string at byte offset i of the current line, and the
+// lines continuing it, into buf.
+func (s *scanner) quoted(i int) {
+	s.buf = s.unquote(s.buf[:0], bytes.TrimSpace(s.text[i:]))
+	for s.Scan() && len(s.text) > 0 && s.text[0] == '"' {
+		s.buf = s.unquote(s.buf, s.text)
 	}
-	return r
 }
 
 // msgstr parses the msgstr section of a message record.
 // it handles multiline messages as well as indexed plural forms.
+// plural forms may appear out of order, or with gaps, in which case their
+// indices are recorded for indices to return.
 func (s *scanner) msgstr() []string {
+	s.irregular = nil
 	if s.prefix("msgstr ") {
-		return []string{s.quo("msgstr ")}
+		s.tmp = append(s.tmp[:0], s.quo("msgstr "))
+		return s.strings(s.tmp)
 	}
 
-	var r []string
-	for {
-		var prefix = "msgstr[" + strconv.Itoa(len(r)) + "] "
-		if !s.prefix(prefix) {
-			return r
+	var r = s.tmp[:0]
+	var order = s.order[:0]
+	for s.prefix("msgstr[") {
+		var end = bytes.IndexByte(s.Bytes(), ']')
+		if end == -1 {
+			s.errorAt(len("msgstr"), "malformed msgstr index", nil)
+			return s.strings(r)
+		}
+		var n, err = strconv.Atoi(string(s.Bytes()[len("msgstr["):end]))
+		if err != nil || n < 0 || n > maxPlurals {
+			s.errorAt(len("msgstr["), fmt.Sprintf("invalid msgstr index %q", s.Bytes()[:end+1]), err)
+			return s.strings(r)
+		}
+		for _, seen := range order {
+			if seen == n {
+				s.errorAt(len("msgstr["), fmt.Sprintf("duplicate msgstr[%d]", n), nil)
+				return s.strings(r)
+			}
+		}
+		order = append(order, n)
+		for len(r) <= n {
+			r = append(r, "")
 		}
-		r = append(r, s.quo(prefix))
+		s.quoted(end + 1)
+		r[n] = string(s.buf)
 	}
+	s.tmp, s.order = r, order
+	for i, n := range order {
+		if i != n || len(order) != len(r) {
+			s.irregular = append([]int(nil), order...)
+			break
+		}
+	}
+	return s.strings(r)
+}
+
+// maxPlurals bounds the msgstr indices accepted.
+const maxPlurals = 100
+
+// indices returns the msgstr indices of the last message, in the order they
+// appeared, if out of order or sparse.
+func (s *scanner) indices() []int {
+	return s.irregular
 }
 
-func (s *scanner) unquote(str string) string {
-	var r, err = strconv.Unquote(str)
+// unquote appends the content of the quoted string str, of the current line,
+// to b, warning of the escape sequences other than those quote writes.
+func (s *scanner) unquote(b, str []byte) []byte {
+	for i := 0; i < len(str)-1; i++ {
+		if str[i] == '\\' {
+			if i++; !written(str[i]) {
+				s.warn("escape", "suspicious escape sequence \\"+string(str[i]))
+			}
+		}
+	}
+	var r, err = appendUnquoted(b, str)
 	if err != nil {
-		s.err = err
+		s.errorAt(bytes.Index(s.text, str), "invalid quoted string "+string(str), err)
 	}
 	return r
 }
 
+// warn records a non-fatal problem on the current line.
+func (s *scanner) warn(rule, text string) {
+	s.warnings = append(s.warnings, Problem{Rule: rule, Severity: Warning, Line: s.line, Text: text})
+}
+
+// error records a syntax error on the current line, unless one was already.
+func (s *scanner) error(msg string, err error) {
+	s.errorAt(-1, msg, err)
+}
+
+// errorAt is like error, for an error at byte offset i of the current line,
+// without the obsolete marker, or -1 if the offset is unknown.
+func (s *scanner) errorAt(i int, msg string, err error) {
+	if s.err != nil {
+		return
+	}
+	var raw = s.Scanner.Bytes()
+	var e = &ParseError{Line: s.line, Msg: msg, Context: string(raw), Err: err}
+	if i >= 0 {
+		e.Column = len(raw) - len(s.text) + i + 1
+	}
+	s.err = e
+}
+
 // Err returns the last error encountered, if any.
 func (s *scanner) Err() error {
 	if s.err != nil {
@@ -0,0 +1,110 @@
+package po
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// selectRe matches the start of a select expression, with its argument.
+var selectRe = regexp.MustCompile(`^\{\s*([A-Za-z_][A-Za-z0-9_.]*)\s*,\s*select\s*,`)
+
+// Select evaluates the ICU-like select expressions of str, e.g.
+//
+//	{gender, select, male{He} female{She} other{They}} replied.
+//
+// by the value of their argument in data, formatted with fmt.Sprint. The
+// "other" case is used for values without a case of their own, or missing
+// from data. Cases may contain select expressions. Malformed expressions are
+// left as they are.
+func Select(str string, data map[string]interface{}) string {
+	if !strings.Contains(str, "select") {
+		return str
+	}
+	var b strings.Builder
+	for i := 0; i < len(str); {
+		if str[i] == '{' {
+			if n, text, ok := selectAt(str[i:], data); ok {
+				b.WriteString(text)
+				i += n
+				continue
+			}
+		}
+		b.WriteByte(str[i])
+		i++
+	}
+	return b.String()
+}
+
+// selectAt evaluates the select expression at the start of s, returning its
+// length and the evaluated case.
+func selectAt(s string, data map[string]interface{}) (n int, text string, ok bool) {
+	var m = selectRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, "", false
+	}
+	var cases = make(map[string]string)
+	var i = len(m[0])
+	for {
+		i += len(s[i:]) - len(strings.TrimLeft(s[i:], " \t\n"))
+		if i == len(s) {
+			return 0, "", false
+		}
+		if s[i] == '}' {
+			break
+		}
+		var k = strings.IndexAny(s[i:], "{} \t\n")
+		if k <= 0 {
+			return 0, "", false
+		}
+		var name = s[i : i+k]
+		i += k
+		i += len(s[i:]) - len(strings.TrimLeft(s[i:], " \t\n"))
+		var end = matchingBrace(s[i:])
+		if end < 0 {
+			return 0, "", false
+		}
+		cases[name] = s[i+1 : i+end]
+		i += end + 1
+	}
+	var c, found = "", false
+	if v, has := data[m[1]]; has {
+		c, found = cases[fmt.Sprint(v)]
+	}
+	if !found {
+		c = cases["other"]
+	}
+	return i + 1, Select(c, data), true
+}
+
+// matchingBrace returns the index of the brace closing the one s starts
+// with, or -1.
+func matchingBrace(s string) int {
+	if !strings.HasPrefix(s, "{") {
+		return -1
+	}
+	var depth = 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// SelectText is like GetTextData, with the select expressions of the
+// translation evaluated by Select before the named placeholders are
+// replaced, so that grammatical gender can be handled in a single message.
+func (f *File) SelectText(id string, data map[string]interface{}) string {
+	return f.PSelectText("", id, data)
+}
+
+// PSelectText is like SelectText, for the message in the given context.
+func (f *File) PSelectText(ctxt, id string, data map[string]interface{}) string {
+	return Interpolate(Select(f.getByIds(ctxt, id, 0).text(0, id), data), data)
+}
@@ -0,0 +1,54 @@
+package po
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	var tests = []struct {
+		str      string
+		data     map[string]interface{}
+		expected string
+	}{
+		{"{gender, select, male{He} female{She} other{They}} replied.", map[string]interface{}{"gender": "female"}, "She replied."},
+		{"{gender, select, male{He} female{She} other{They}} replied.", map[string]interface{}{"gender": "x"}, "They replied."},
+		{"{gender, select, male{He} female{She} other{They}} replied.", nil, "They replied."},
+		{"{gender,select,male{He}}", nil, ""},
+		{"{a, select, x{A{b, select, y{B} other{C}}} other{D}}!", map[string]interface{}{"a": "x", "b": "y"}, "AB!"},
+		{"{on, select, true{yes} other{no}}", map[string]interface{}{"on": true}, "yes"},
+		{"{gender, select, male{He} female{She}", nil, "{gender, select, male{He} female{She}"},
+		{"{name} and {gender, select, male{his} other{their}} {thing}", map[string]interface{}{"gender": "male"}, "{name} and his {thing}"},
+	}
+	for _, test := range tests {
+		if actual := Select(test.str, test.data); actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.str, test.expected, actual)
+		}
+	}
+}
+
+func TestSelectText(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid "{name} updated the file"
+msgstr "{gender, select, male{{name} aktualizoval} female{{name} aktualizovala} other{{name} aktualizoval(a)}} súbor"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		gender, expected string
+	}{
+		{"male", "Peter aktualizoval súbor"},
+		{"female", "Peter aktualizovala súbor"},
+		{"", "Peter aktualizoval(a) súbor"},
+	}
+	for _, test := range tests {
+		var data = map[string]interface{}{"name": "Peter", "gender": test.gender}
+		if actual := f.SelectText("{name} updated the file", data); actual != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.gender, test.expected, actual)
+		}
+	}
+	if actual, expected := f.SelectText("{name} left", map[string]interface{}{"name": "Eva"}), "Eva left"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
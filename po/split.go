@@ -0,0 +1,136 @@
+package po
+
+import (
+	"net/textproto"
+	"path"
+	"sort"
+	"strings"
+)
+
+// SplitBy partitions the messages of the file into catalogs named by fn,
+// e.g. one per package. The catalogs share the messages of the file, and a
+// copy of its header.
+func (f *File) SplitBy(fn func(*Message) string) map[string]*File {
+	var r = make(map[string]*File)
+	for _, msg := range f.Messages {
+		var name = fn(msg)
+		var sub = r[name]
+		if sub == nil {
+			sub = &File{
+				Header:          cloneHeader(f.Header),
+				Pluralize:       f.Pluralize,
+				ContextFallback: f.ContextFallback,
+				headerOrder:     f.headerOrder,
+			}
+			r[name] = sub
+		}
+		sub.Messages = append(sub.Messages, msg)
+	}
+	for _, sub := range r {
+		sub.reindex()
+	}
+	return r
+}
+
+// ByReferenceDir returns a splitter naming the catalog of each message after
+// the first depth directories of its first reference, e.g. "internal/billing"
+// for "internal/billing/invoice.go:42" with a depth of 2. Messages without
+// references are named "".
+func ByReferenceDir(depth int) func(*Message) string {
+	return func(m *Message) string {
+		if len(m.References) == 0 {
+			return ""
+		}
+		var file = m.References[0].File
+		var dirs = strings.Split(path.Dir(path.Clean(file)), "/")
+		if dirs[0] == "." {
+			return ""
+		}
+		if len(dirs) > depth {
+			dirs = dirs[:depth]
+		}
+		return strings.Join(dirs, "/")
+	}
+}
+
+func cloneHeader(h textproto.MIMEHeader) textproto.MIMEHeader {
+	if h == nil {
+		return nil
+	}
+	var r = make(textproto.MIMEHeader, len(h))
+	for k, v := range h {
+		r[k] = append([]string(nil), v...)
+	}
+	return r
+}
+
+// OriginPrefix starts the extracted comment recording the catalog a joined
+// message came from, e.g. "#. origin: billing".
+const OriginPrefix = "origin:"
+
+// Origin returns the catalog the message was joined from, if recorded.
+func (c Comment) Origin() string {
+	for _, line := range c.ExtractedComments {
+		if strings.HasPrefix(line, OriginPrefix) {
+			return strings.TrimSpace(line[len(OriginPrefix):])
+		}
+	}
+	return ""
+}
+
+// Join merges the catalogs, e.g. those returned by SplitBy, into one, in the
+// order of their names. Each message is copied with an OriginPrefix comment
+// naming its catalog, so that splitting by ByOrigin restores the catalogs.
+// The header is that of the first catalog. Joining catalogs with the same
+// message fails with a *DuplicateError.
+func Join(catalogs map[string]*File) (*File, error) {
+	var names []string
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var r = &File{}
+	var seen = make(map[key]bool)
+	for i, name := range names {
+		var sub = catalogs[name]
+		if i == 0 {
+			r.Header = cloneHeader(sub.Header)
+			r.Pluralize, r.ContextFallback = sub.Pluralize, sub.ContextFallback
+			r.headerOrder = sub.headerOrder
+		}
+		for _, msg := range sub.Messages {
+			var k = key{msg.Ctxt, msg.Id}
+			if seen[k] && !msg.Obsolete {
+				return nil, &DuplicateError{msg.Ctxt, msg.Id}
+			}
+			seen[k] = seen[k] || !msg.Obsolete
+			var joined = *msg
+			joined.ExtractedComments = withOrigin(msg.ExtractedComments, name)
+			r.Messages = append(r.Messages, &joined)
+		}
+	}
+	r.reindex()
+	return r, nil
+}
+
+// withOrigin returns the comments with the origin set to name.
+func withOrigin(comments []string, name string) []string {
+	var r = make([]string, 0, len(comments)+1)
+	for _, line := range comments {
+		if !strings.HasPrefix(line, OriginPrefix) {
+			r = append(r, line)
+		}
+	}
+	return append(r, OriginPrefix+" "+name)
+}
+
+// ByOrigin returns a splitter naming the catalog of each message after its
+// origin, as recorded by Join, or by fallback for the messages without one.
+func ByOrigin(fallback func(*Message) string) func(*Message) string {
+	return func(m *Message) string {
+		if origin := m.Origin(); origin != "" {
+			return origin
+		}
+		return fallback(m)
+	}
+}
@@ -0,0 +1,90 @@
+package po
+
+import (
+	"errors"
+	"net/textproto"
+	"reflect"
+	"testing"
+)
+
+func TestByReferenceDir(t *testing.T) {
+	var tests = []struct {
+		refs     []string
+		depth    int
+		expected string
+	}{
+		{[]string{"internal/billing/invoice.go:42"}, 2, "internal/billing"},
+		{[]string{"internal/billing/invoice.go:42"}, 1, "internal"},
+		{[]string{"internal/billing/tax/vat.go"}, 2, "internal/billing"},
+		{[]string{"main.go:3", "cmd/app/main.go:1"}, 1, ""},
+		{[]string{"./web/app.js:10"}, 1, "web"},
+		{nil, 1, ""},
+	}
+	for _, test := range tests {
+		var msg = &Message{Comment: Comment{References: parseReferences(test.refs)}}
+		if actual := ByReferenceDir(test.depth)(msg); actual != test.expected {
+			t.Errorf("%v, %d: expected %q, got %q", test.refs, test.depth, test.expected, actual)
+		}
+	}
+}
+
+func TestSplitBy(t *testing.T) {
+	var f = &File{
+		Header: textproto.MIMEHeader{"Language": {"sk"}},
+		Messages: []*Message{
+			{Comment: Comment{References: []Reference{{"billing/invoice.go", 1}}}, Id: "Invoice", Str: []string{"Faktúra"}},
+			{Comment: Comment{References: []Reference{{"auth/login.go", 1}}}, Id: "Log in", Str: []string{"Prihlásiť"}},
+			{Comment: Comment{References: []Reference{{"billing/tax.go", 1}}}, Id: "Tax", Str: []string{"Daň"}},
+		},
+	}
+	var split = f.SplitBy(ByReferenceDir(1))
+	var ids = make(map[string][]string)
+	for name, sub := range split {
+		for _, msg := range sub.Messages {
+			ids[name] = append(ids[name], msg.Id)
+		}
+	}
+	if expected := map[string][]string{"billing": {"Invoice", "Tax"}, "auth": {"Log in"}}; !reflect.DeepEqual(expected, ids) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+	split["auth"].Header.Set("Language", "cs")
+	if f.Header.Get("Language") != "sk" || split["billing"].GetText("Tax") != "Daň" {
+		t.Error("expected independent headers and indexed catalogs")
+	}
+}
+
+func TestJoin(t *testing.T) {
+	var catalogs = map[string]*File{
+		"billing": {Header: textproto.MIMEHeader{"Language": {"sk"}}, Messages: []*Message{
+			{Comment: Comment{References: []Reference{{"billing/invoice.go", 1}}}, Id: "Invoice", Str: []string{"Faktúra"}},
+		}},
+		"auth": {Header: textproto.MIMEHeader{"Language": {"sk"}}, Messages: []*Message{
+			{Comment: Comment{References: []Reference{{"shared/login.go", 1}}}, Id: "Log in", Str: []string{"Prihlásiť"}},
+		}},
+	}
+	f, err := Join(catalogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Messages) != 2 || f.Messages[0].Origin() != "auth" || f.GetText("Invoice") != "Faktúra" {
+		t.Errorf("unexpected joined messages %v", f.Messages)
+	}
+	if catalogs["auth"].Messages[0].Origin() != "" {
+		t.Error("expected the catalogs to be left unchanged")
+	}
+	var split = f.SplitBy(ByOrigin(ByReferenceDir(1)))
+	if len(split) != 2 || len(split["auth"].Messages) != 1 || len(split["billing"].Messages) != 1 {
+		t.Errorf("expected the catalogs to be restored, got %v", split)
+	}
+
+	catalogs["auth"].Messages = append(catalogs["auth"].Messages, &Message{Id: "Invoice"})
+	if _, err := Join(catalogs); err == nil {
+		t.Error("expected an error for a message in several catalogs")
+	}
+	catalogs["billing"].Messages[0].Obsolete = true
+	catalogs["crm"] = &File{Messages: []*Message{{Id: "Invoice", Str: []string{"Faktúra"}}}}
+	var dup *DuplicateError
+	if _, err := Join(catalogs); !errors.As(err, &dup) || dup.Id != "Invoice" {
+		t.Errorf("expected a duplicate error despite the obsolete message in between, got %v", err)
+	}
+}
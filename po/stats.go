@@ -0,0 +1,163 @@
+package po
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// WordCount returns the number of words in the message's source strings,
+// msgid and msgid_plural, excluding placeholders. Han, Hiragana and Katakana
+// characters count as one word each.
+func (m *Message) WordCount() int {
+	return countWords(m.Id) + countWords(m.IdPlural)
+}
+
+// CharCount returns the number of characters in the message's source
+// strings, excluding placeholders and whitespace.
+func (m *Message) CharCount() int {
+	var n int
+	for _, r := range StripPlaceholders(m.Id + m.IdPlural) {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
+
+func countWords(s string) int {
+	var n int
+	for _, field := range strings.Fields(StripPlaceholders(s)) {
+		var inWord bool
+		for _, r := range field {
+			switch {
+			case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+				n++
+				inWord = false
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				if !inWord {
+					n++
+				}
+				inWord = true
+			}
+		}
+	}
+	return n
+}
+
+// Count holds the size of a set of messages.
+type Count struct {
+	Messages int
+	Words    int
+	Chars    int
+}
+
+func (c *Count) add(m *Message) {
+	c.Messages++
+	c.Words += m.WordCount()
+	c.Chars += m.CharCount()
+}
+
+// WordStats breaks down the source text of a file by translation state.
+type WordStats struct {
+	Total        Count
+	Translated   Count // fully translated, not fuzzy
+	Fuzzy        Count
+	Untranslated Count // not fully translated, not fuzzy
+}
+
+// WordStats returns the size of the file's source text, by translation state.
+func (f *File) WordStats() WordStats {
+	var s WordStats
+	for _, msg := range f.Messages {
+		s.Total.add(msg)
+		switch {
+		case msg.HasFlag("fuzzy"):
+			s.Fuzzy.add(msg)
+		case msg.IsTranslated():
+			s.Translated.add(msg)
+		default:
+			s.Untranslated.add(msg)
+		}
+	}
+	return s
+}
+
+// Stats counts the messages of a file by translation state, like msgfmt
+// --statistics. Obsolete messages are counted apart from the others.
+type Stats struct {
+	Translated   int // fully translated, not fuzzy
+	Fuzzy        int
+	Untranslated int // not fully translated, not fuzzy
+	Obsolete     int
+
+	// Plurals is the number of plural messages, not obsolete, and Forms the
+	// number of those not fuzzy with each plural form translated, up to the
+	// nplurals of the file.
+	Plurals int
+	Forms   []int
+}
+
+// Stats returns the number of messages of the file by translation state.
+func (f *File) Stats() Stats {
+	var s = Stats{Forms: make([]int, f.nplurals())}
+	for _, msg := range f.Messages {
+		switch {
+		case msg.Obsolete:
+			s.Obsolete++
+			continue
+		case msg.HasFlag("fuzzy"):
+			s.Fuzzy++
+		case msg.IsTranslated():
+			s.Translated++
+		default:
+			s.Untranslated++
+		}
+		if msg.IdPlural == "" {
+			continue
+		}
+		s.Plurals++
+		if msg.HasFlag("fuzzy") {
+			continue
+		}
+		for i, str := range msg.Str {
+			if i < len(s.Forms) && str != "" {
+				s.Forms[i]++
+			}
+		}
+	}
+	return s
+}
+
+// Total returns the number of messages, not obsolete.
+func (s Stats) Total() int {
+	return s.Translated + s.Fuzzy + s.Untranslated
+}
+
+// Coverage returns the fraction of the messages translated, from 0 to 1, or
+// 1 if there are none.
+func (s Stats) Coverage() float64 {
+	if s.Total() == 0 {
+		return 1
+	}
+	return float64(s.Translated) / float64(s.Total())
+}
+
+// String formats the counts as msgfmt --statistics, e.g. "3 translated
+// messages, 1 fuzzy translation, 2 untranslated messages.".
+func (s Stats) String() string {
+	var plural = func(n int, one, other string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, one)
+		}
+		return fmt.Sprintf("%d %s", n, other)
+	}
+	var parts = []string{plural(s.Translated, "translated message", "translated messages")}
+	if s.Fuzzy > 0 {
+		parts = append(parts, plural(s.Fuzzy, "fuzzy translation", "fuzzy translations"))
+	}
+	if s.Untranslated > 0 {
+		parts = append(parts, plural(s.Untranslated, "untranslated message", "untranslated messages"))
+	}
+	return strings.Join(parts, ", ") + "."
+}
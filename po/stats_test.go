@@ -0,0 +1,96 @@
+package po
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWordCount(t *testing.T) {
+	var tests = []struct {
+		msg   Message
+		words int
+		chars int
+	}{
+		{Message{Id: "Open file"}, 2, 8},
+		{Message{Id: "Hello, %s!"}, 1, 7},
+		{Message{Id: "one egg", IdPlural: "{$EGGS_2} eggs"}, 3, 10},
+		{Message{Id: "don't stop-gap"}, 2, 13},
+		{Message{Id: "ファイルを開く"}, 7, 7},
+	}
+	for _, test := range tests {
+		if n := test.msg.WordCount(); n != test.words {
+			t.Errorf("%q: expected %d words, got %d", test.msg.Id, test.words, n)
+		}
+		if n := test.msg.CharCount(); n != test.chars {
+			t.Errorf("%q: expected %d chars, got %d", test.msg.Id, test.chars, n)
+		}
+	}
+}
+
+func TestWordStats(t *testing.T) {
+	var f = &File{Messages: []*Message{
+		{Id: "Open file", Str: []string{"Datei öffnen"}},
+		{Id: "Save all files", Str: []string{"Alle speichern"}, Comment: Comment{Flags: []string{"fuzzy"}}},
+		{Id: "Quit", Str: []string{""}},
+	}}
+	var expected = WordStats{
+		Total:        Count{3, 6, 24},
+		Translated:   Count{1, 2, 8},
+		Fuzzy:        Count{1, 3, 12},
+		Untranslated: Count{1, 1, 4},
+	}
+	if actual := f.WordStats(); actual != expected {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestStats(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Language: sk\n"
+
+msgid "Open"
+msgstr "Otvoriť"
+
+#, fuzzy
+msgid "Save"
+msgstr "Uložiť"
+
+msgid "Quit"
+msgstr ""
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d súbor"
+msgstr[1] "%d súbory"
+msgstr[2] ""
+
+msgid "%d day"
+msgid_plural "%d days"
+msgstr[0] "%d deň"
+msgstr[1] "%d dni"
+msgstr[2] "%d dní"
+
+#~ msgid "Close"
+#~ msgstr "Zavrieť"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expected = Stats{Translated: 2, Fuzzy: 1, Untranslated: 2, Obsolete: 1, Plurals: 2, Forms: []int{2, 2, 1}}
+	var actual = f.Stats()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+	if s := actual.String(); s != "2 translated messages, 1 fuzzy translation, 2 untranslated messages." {
+		t.Errorf("unexpected statistics %q", s)
+	}
+	if c := actual.Coverage(); c != 0.4 {
+		t.Errorf("expected coverage 0.4, got %v", c)
+	}
+	if s := (Stats{Translated: 1}).String(); s != "1 translated message." {
+		t.Errorf("unexpected statistics %q", s)
+	}
+}
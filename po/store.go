@@ -0,0 +1,232 @@
+package po
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StoreOptions configure the caching of the catalogs of a Store.
+type StoreOptions struct {
+	// MaxCatalogs is the number of catalogs kept, the least recently used
+	// being evicted first, or 0 for no limit.
+	MaxCatalogs int
+	// MaxBytes is the estimated memory used by the messages of the catalogs
+	// kept, as reported by Bundle.Debug, or 0 for no limit.
+	MaxBytes int
+	// TTL is the time a catalog is kept after being loaded, and reloaded on
+	// its next use, or 0 for no expiry.
+	TTL time.Duration
+
+	// OnLoad, if set, is called after each load of a catalog, with its
+	// duration and error, e.g. to record the latency of loads.
+	OnLoad func(locale string, d time.Duration, err error)
+	// OnHit, if set, is called for each lookup of a catalog found loaded.
+	OnHit func(locale string)
+	// OnEvict, if set, is called for each catalog evicted.
+	OnEvict func(locale string)
+	// Logger, if set, receives a record of each load of a catalog, with its
+	// duration and number of messages, or its error, and of each eviction.
+	Logger *slog.Logger
+}
+
+// storeEntry is a catalog of a store, loaded or being loaded.
+type storeEntry struct {
+	file   *File
+	err    error
+	ready  chan struct{} // closed once loaded
+	loaded time.Time
+	used   time.Time
+	bytes  int
+}
+
+// Store loads the catalogs of the locales of an application on their first
+// use, and keeps them for the next ones, within the limits of its options,
+// for servers supporting many locales of which few are used at a time. It is
+// safe for concurrent use; the catalog of a locale is loaded once for
+// concurrent lookups.
+type Store struct {
+	load func(locale string) (*File, error)
+	opts StoreOptions
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*storeEntry // by locale
+	bytes   int
+}
+
+// NewStore returns a store of the catalogs loaded by the given function, e.g.
+// DomainLoader(root, domain).
+func NewStore(load func(locale string) (*File, error), opts StoreOptions) *Store {
+	return &Store{load: load, opts: opts, now: time.Now, entries: make(map[string]*storeEntry)}
+}
+
+// DomainLoader returns a loader of the catalogs of a domain from a directory
+// in the layout of LoadDomain: <root>/<locale>/LC_MESSAGES/<domain>.mo or
+// .po, or else <root>/<locale>.mo or .po, for a directory of a single
+// domain.
+func DomainLoader(root, domain string) func(locale string) (*File, error) {
+	return DomainLoaderFS(os.DirFS(root), domain)
+}
+
+// DomainLoaderFS is like DomainLoader, for the root directory of fsys.
+func DomainLoaderFS(fsys fs.FS, domain string) func(locale string) (*File, error) {
+	return func(locale string) (*File, error) {
+		if !fs.ValidPath(locale) || strings.Contains(locale, "/") {
+			return nil, &fs.PathError{Op: "open", Path: locale, Err: fs.ErrInvalid}
+		}
+		var names = []string{
+			path.Join(locale, "LC_MESSAGES", domain+".mo"),
+			path.Join(locale, "LC_MESSAGES", domain+".po"),
+			locale + ".mo",
+			locale + ".po",
+		}
+		for _, name := range names {
+			if f, err := LoadFileFS(fsys, name); !os.IsNotExist(err) {
+				return f, err
+			}
+		}
+		return nil, &fs.PathError{Op: "open", Path: names[1], Err: fs.ErrNotExist}
+	}
+}
+
+// File returns the catalog of the locale, loading it unless it is loaded and
+// not expired. Failed loads are not kept, and retried on the next lookup.
+func (s *Store) File(locale string) (*File, error) {
+	var evicted []string
+	defer func() { s.evicted(evicted) }()
+	s.mu.Lock()
+	var e = s.entries[locale]
+	if e != nil && s.opts.TTL > 0 && e.file != nil && s.now().Sub(e.loaded) >= s.opts.TTL {
+		s.remove(locale)
+		evicted, e = append(evicted, locale), nil
+	}
+	if e != nil {
+		s.mu.Unlock()
+		<-e.ready
+		if e.err != nil {
+			return nil, e.err
+		}
+		s.mu.Lock()
+		e.used = s.now()
+		s.mu.Unlock()
+		if s.opts.OnHit != nil {
+			s.opts.OnHit(locale)
+		}
+		return e.file, nil
+	}
+	e = &storeEntry{ready: make(chan struct{})}
+	s.entries[locale] = e
+	s.mu.Unlock()
+
+	var start = time.Now()
+	var f, err = s.load(locale)
+	if s.opts.OnLoad != nil {
+		s.opts.OnLoad(locale, time.Since(start), err)
+	}
+	if err != nil {
+		s.log(slog.LevelError, "po: catalog load failed", slog.String("locale", locale),
+			slog.Duration("duration", time.Since(start)), slog.Any("error", err))
+	} else {
+		s.log(slog.LevelInfo, "po: catalog loaded", slog.String("locale", locale),
+			slog.Duration("duration", time.Since(start)), slog.Int("messages", len(f.Messages)))
+	}
+
+	s.mu.Lock()
+	e.file, e.err = f, err
+	if err != nil {
+		if s.entries[locale] == e {
+			delete(s.entries, locale)
+		}
+	} else if s.entries[locale] == e {
+		e.loaded, e.used, e.bytes = s.now(), s.now(), f.size()
+		s.bytes += e.bytes
+		evicted = append(evicted, s.evict(locale)...)
+	}
+	close(e.ready)
+	s.mu.Unlock()
+	return f, err
+}
+
+// evict removes the least recently used catalogs, other than that of keep,
+// until the store is within its limits, and returns their locales.
+func (s *Store) evict(keep string) []string {
+	var r []string
+	for (s.opts.MaxCatalogs > 0 && len(s.entries) > s.opts.MaxCatalogs) || (s.opts.MaxBytes > 0 && s.bytes > s.opts.MaxBytes) {
+		var oldest string
+		var found = false
+		for locale, e := range s.entries {
+			if locale == keep || e.file == nil {
+				continue
+			}
+			if !found || e.used.Before(s.entries[oldest].used) {
+				oldest, found = locale, true
+			}
+		}
+		if !found {
+			break
+		}
+		s.remove(oldest)
+		r = append(r, oldest)
+	}
+	return r
+}
+
+// remove removes the loaded catalog of the locale.
+func (s *Store) remove(locale string) {
+	s.bytes -= s.entries[locale].bytes
+	delete(s.entries, locale)
+}
+
+// evicted reports the catalogs evicted to OnEvict and Logger, once the store
+// is unlocked.
+func (s *Store) evicted(locales []string) {
+	for _, locale := range locales {
+		if s.opts.OnEvict != nil {
+			s.opts.OnEvict(locale)
+		}
+		s.log(slog.LevelInfo, "po: catalog evicted", slog.String("locale", locale))
+	}
+}
+
+// log records an event, if the store has a logger.
+func (s *Store) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if s.opts.Logger != nil {
+		s.opts.Logger.LogAttrs(context.Background(), level, msg, attrs...)
+	}
+}
+
+// Evict removes the catalog of the locale, if loaded, so that the next
+// lookup reloads it.
+func (s *Store) Evict(locale string) {
+	s.mu.Lock()
+	var e = s.entries[locale]
+	var loaded = e != nil && e.file != nil
+	if loaded {
+		s.remove(locale)
+	}
+	s.mu.Unlock()
+	if loaded {
+		s.evicted([]string{locale})
+	}
+}
+
+// Locales returns the locales of the catalogs loaded, sorted.
+func (s *Store) Locales() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var r = make([]string, 0, len(s.entries))
+	for locale, e := range s.entries {
+		if e.file != nil {
+			r = append(r, locale)
+		}
+	}
+	sort.Strings(r)
+	return r
+}
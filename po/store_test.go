@@ -0,0 +1,154 @@
+package po
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	var loads, hits, evictions []string
+	var mu sync.Mutex
+	var load = func(locale string) (*File, error) {
+		if locale == "xx" {
+			return nil, errors.New("no catalog")
+		}
+		return Parse(strings.NewReader("msgid \"Open\"\nmsgstr \"Open (" + locale + ")\"\n"))
+	}
+	var s = NewStore(load, StoreOptions{
+		MaxCatalogs: 2,
+		TTL:         time.Hour,
+		OnLoad: func(locale string, d time.Duration, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			loads = append(loads, locale)
+		},
+		OnHit: func(locale string) {
+			mu.Lock()
+			defer mu.Unlock()
+			hits = append(hits, locale)
+		},
+		OnEvict: func(locale string) { evictions = append(evictions, locale) },
+	})
+	var now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	var get = func(locale string) {
+		t.Helper()
+		f, err := s.File(locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := f.GetText("Open"); actual != "Open ("+locale+")" {
+			t.Errorf("unexpected translation %q", actual)
+		}
+		now = now.Add(time.Minute)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.File("de")
+		}()
+	}
+	wg.Wait()
+	if !reflect.DeepEqual([]string{"de"}, loads) {
+		t.Errorf("expected a single load, got %v", loads)
+	}
+	hits = nil
+
+	get("sk")
+	get("de")
+	get("fr") // evicts sk, the least recently used
+	if !reflect.DeepEqual([]string{"de", "fr"}, s.Locales()) {
+		t.Errorf("unexpected locales %v", s.Locales())
+	}
+	get("sk")
+	if !reflect.DeepEqual([]string{"de", "sk", "fr", "sk"}, loads) || !reflect.DeepEqual([]string{"de"}, hits) || !reflect.DeepEqual([]string{"sk", "de"}, evictions) {
+		t.Errorf("unexpected loads %v, hits %v, evictions %v", loads, hits, evictions)
+	}
+
+	now = now.Add(time.Hour)
+	get("sk")
+	if loads[len(loads)-1] != "sk" || len(loads) != 5 || evictions[len(evictions)-1] != "sk" {
+		t.Errorf("expected sk to expire, got loads %v, evictions %v", loads, evictions)
+	}
+
+	s.Evict("fr")
+	if !reflect.DeepEqual([]string{"sk"}, s.Locales()) {
+		t.Errorf("unexpected locales %v", s.Locales())
+	}
+	if _, err := s.File("xx"); err == nil {
+		t.Error("expected an error")
+	}
+	if !reflect.DeepEqual([]string{"sk"}, s.Locales()) {
+		t.Errorf("unexpected locales after a failed load %v", s.Locales())
+	}
+}
+
+func TestStoreMaxBytes(t *testing.T) {
+	var s = NewStore(func(locale string) (*File, error) {
+		return Parse(strings.NewReader("msgid \"Open\"\nmsgstr \"" + locale + "\"\n"))
+	}, StoreOptions{MaxBytes: 300})
+	s.File("de")
+	s.File("sk")
+	if !reflect.DeepEqual([]string{"sk"}, s.Locales()) {
+		t.Errorf("unexpected locales %v", s.Locales())
+	}
+}
+
+func TestStoreLogger(t *testing.T) {
+	var buf bytes.Buffer
+	var s = NewStore(func(locale string) (*File, error) {
+		if locale == "fr" {
+			return nil, errors.New("missing")
+		}
+		return Parse(strings.NewReader("msgid \"Open\"\nmsgstr \"" + locale + "\"\n"))
+	}, StoreOptions{MaxCatalogs: 1, Logger: slog.New(slog.NewTextHandler(&buf, nil))})
+	s.File("de")
+	s.File("sk")
+	s.File("fr")
+	for _, expected := range []string{
+		`level=INFO msg="po: catalog loaded" locale=de duration=`,
+		`level=INFO msg="po: catalog evicted" locale=de`,
+		`level=ERROR msg="po: catalog load failed" locale=fr duration=`,
+		"messages=1",
+		"error=missing",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %s in:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestDomainLoader(t *testing.T) {
+	var root = t.TempDir()
+	for name, str := range map[string]string{"sk/LC_MESSAGES/app.po": "Otvoriť", "de.po": "Öffnen"} {
+		name = filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(name), 0777)
+		if err := os.WriteFile(name, []byte("msgid \"Open\"\nmsgstr \""+str+"\"\n"), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var load = DomainLoader(root, "app")
+	for locale, expected := range map[string]string{"sk": "Otvoriť", "de": "Öffnen"} {
+		f, err := load(locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := f.GetText("Open"); actual != expected {
+			t.Errorf("%s: expected %q, got %q", locale, expected, actual)
+		}
+	}
+	if _, err := load("fr"); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package po
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TM is a translation memory: a corpus of existing translations searched for
+// matches of new msgids.
+type TM struct {
+	Scorer Scorer // scores the similarity of msgids
+
+	entries []*Message
+	exact   map[string][]*Message
+}
+
+// TMMatch is a translation found in a TM.
+type TMMatch struct {
+	*Message         // message holding the translation
+	Score    float64 // similarity of the msgids, 1 for exact matches
+}
+
+// NewTM returns a translation memory of the translated, non-fuzzy messages of
+// the given files.
+func NewTM(files ...*File) *TM {
+	var tm = &TM{Scorer: DefaultScorer, exact: make(map[string][]*Message)}
+	for _, f := range files {
+		for _, msg := range f.Messages {
+			tm.Add(msg)
+		}
+	}
+	return tm
+}
+
+// Add adds the message to the translation memory, unless it is fuzzy or not
+// fully translated.
+func (tm *TM) Add(msg *Message) {
+	if msg.HasFlag("fuzzy") || !msg.IsTranslated() {
+		return
+	}
+	if tm.exact == nil {
+		tm.exact = make(map[string][]*Message)
+	}
+	tm.entries = append(tm.entries, msg)
+	tm.exact[msg.Id] = append(tm.exact[msg.Id], msg)
+}
+
+// Lookup returns the translations of msgids similar to id, scoring at least
+// minScore, best first.
+func (tm *TM) Lookup(id string, minScore float64) []TMMatch {
+	var matches []TMMatch
+	for _, msg := range tm.exact[id] {
+		matches = append(matches, TMMatch{msg, 1})
+	}
+	if minScore >= 1 {
+		return matches
+	}
+	for _, msg := range tm.entries {
+		if msg.Id == id {
+			continue
+		}
+		if s := tm.Scorer.Score(id, msg.Id); s >= minScore {
+			matches = append(matches, TMMatch{msg, s})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches
+}
+
+// PreTranslateTM fills the untranslated messages of the file from tm. Exact
+// matches in the same context are used as they are, while those in other
+// contexts, and matches scoring at least minScore, are flagged fuzzy. The
+// origin of each translation is recorded in an extracted comment. The number
+// of messages translated is returned.
+func (f *File) PreTranslateTM(tm *TM, minScore float64) int {
+	var n int
+	for _, msg := range f.Messages {
+		if !msg.isUntranslated() {
+			continue
+		}
+		var matches = tm.Lookup(msg.Id, minScore)
+		var exact = func(match TMMatch) bool { return match.Score == 1 && match.Ctxt == msg.Ctxt }
+		sort.SliceStable(matches, func(i, j int) bool { return exact(matches[i]) && !exact(matches[j]) })
+		for _, match := range matches {
+			if (match.IdPlural == "") != (msg.IdPlural == "") {
+				continue
+			}
+			msg.Str = append([]string(nil), match.Str...)
+			if exact(match) {
+				msg.ExtractedComments = append(msg.ExtractedComments, "tm: exact match")
+			} else if match.Score == 1 {
+				msg.markFuzzy()
+				msg.ExtractedComments = append(msg.ExtractedComments, fmt.Sprintf("tm: exact match in context %q", match.Ctxt))
+			} else {
+				msg.markFuzzy()
+				msg.ExtractedComments = append(msg.ExtractedComments,
+					fmt.Sprintf("tm: %.0f%% match of %q", match.Score*100, match.Id))
+			}
+			n++
+			break
+		}
+	}
+	return n
+}
+
+// Tokenization selects the units compared by a Scorer.
+type Tokenization int
+
+const (
+	WordTokens Tokenization = iota // words, with each punctuation mark a separate token
+	CharTokens                     // characters
+)
+
+// Scorer computes the similarity of strings, as used by TM lookups.
+type Scorer struct {
+	Tokens       Tokenization
+	IgnoreCase   bool // compare tokens case-insensitively
+	Placeholders bool // consider all placeholders equal, e.g. "%s" and "{name}"
+}
+
+// DefaultScorer is the Scorer used by Score and by new TMs.
+var DefaultScorer = Scorer{Tokens: WordTokens, Placeholders: true}
+
+// Score returns the similarity of a and b according to DefaultScorer.
+func Score(a, b string) float64 {
+	return DefaultScorer.Score(a, b)
+}
+
+// Score returns the similarity of a and b in [0, 1], based on the edit
+// distance between their tokens. Identical strings score 1.
+func (s Scorer) Score(a, b string) float64 {
+	var ta, tb = s.tokenize(a), s.tokenize(b)
+	var longest = len(ta)
+	if len(tb) > longest {
+		longest = len(tb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ta, tb))/float64(longest)
+}
+
+// placeholderToken replaces placeholders in normalized strings.
+const placeholderToken = "\uFFFC"
+
+func (s Scorer) tokenize(str string) []string {
+	if s.IgnoreCase {
+		str = strings.ToLower(str)
+	}
+	if s.Placeholders {
+		str = placeholderRe.ReplaceAllString(str, placeholderToken)
+	}
+	var tokens []string
+	var word = -1 // start of the current word, if any
+	for i, r := range str {
+		var isWord = s.Tokens == WordTokens && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r))
+		if isWord {
+			if word < 0 {
+				word = i
+			}
+			continue
+		}
+		if word >= 0 {
+			tokens = append(tokens, str[word:i])
+			word = -1
+		}
+		if !unicode.IsSpace(r) || s.Tokens == CharTokens {
+			tokens = append(tokens, string(r))
+		}
+	}
+	if word >= 0 {
+		tokens = append(tokens, str[word:])
+	}
+	return tokens
+}
+
+// editDistance returns the Levenshtein distance between the token sequences.
+func editDistance(a, b []string) int {
+	var prev, cur = make([]int, len(b)+1), make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			var cost = 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Leverage fills the untranslated messages of target with the translations of
+// messages with the same msgctxt, msgid and msgid_plural in sources, e.g. the
+// catalogs of other domains in the same language. Fuzzy translations are not
+// copied, and the first source with a translation wins. The number of
+// messages translated is returned.
+func Leverage(target *File, sources ...*File) int {
+	type key struct{ ctxt, id, idPlural string }
+	var translated = make(map[key]*Message)
+	for i := len(sources) - 1; i >= 0; i-- {
+		for _, msg := range sources[i].Messages {
+			if msg.IsTranslated() && !msg.HasFlag("fuzzy") {
+				translated[key{msg.Ctxt, msg.Id, msg.IdPlural}] = msg
+			}
+		}
+	}
+	var n int
+	for _, msg := range target.Messages {
+		if !msg.isUntranslated() {
+			continue
+		}
+		if src, found := translated[key{msg.Ctxt, msg.Id, msg.IdPlural}]; found {
+			msg.Str = append([]string(nil), src.Str...)
+			n++
+		}
+	}
+	return n
+}
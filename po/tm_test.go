@@ -0,0 +1,110 @@
+package po
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPreTranslateTM(t *testing.T) {
+	var tm = NewTM(&File{Messages: []*Message{
+		{Id: "Delete the selected file", Str: []string{"Ausgewählte Datei löschen"}},
+		{Id: "Cancel", Str: []string{"Abbrechen"}},
+		{Id: "Quit", Str: []string{"Beenden"}, Comment: Comment{Flags: []string{"fuzzy"}}},
+	}})
+	var f = &File{Messages: []*Message{
+		{Id: "Cancel", Str: []string{""}},
+		{Id: "Delete the selected files", Str: []string{""}},
+		{Id: "Quit", Str: []string{""}},
+	}}
+	if n := f.PreTranslateTM(tm, 0.7); n != 2 {
+		t.Errorf("expected 2 messages translated, got %d", n)
+	}
+	var expected = []*Message{
+		{
+			Comment: Comment{ExtractedComments: []string{"tm: exact match"}},
+			Id:      "Cancel",
+			Str:     []string{"Abbrechen"},
+		},
+		{
+			Comment: Comment{
+				ExtractedComments: []string{`tm: 75% match of "Delete the selected file"`},
+				Flags:             []string{"fuzzy"},
+			},
+			Id:  "Delete the selected files",
+			Str: []string{"Ausgewählte Datei löschen"},
+		},
+		{Id: "Quit", Str: []string{""}},
+	}
+	if !reflect.DeepEqual(expected, f.Messages) {
+		t.Errorf("expected msgs:\n%v\ngot msgs:\n%v", expected, f.Messages)
+	}
+}
+
+func TestPreTranslateTMContext(t *testing.T) {
+	var tm TM
+	tm.Add(&Message{Ctxt: "verb", Id: "Open", Str: []string{"Otvoriť"}})
+	tm.Add(&Message{Ctxt: "adjective", Id: "Open", Str: []string{"Otvorený"}})
+	var f = &File{Messages: []*Message{
+		{Ctxt: "adjective", Id: "Open", Str: []string{""}},
+		{Ctxt: "menu", Id: "Open", Str: []string{""}},
+	}}
+	if n := f.PreTranslateTM(&tm, 1); n != 2 {
+		t.Errorf("expected 2 messages translated, got %d", n)
+	}
+	if msg := f.Messages[0]; msg.Str[0] != "Otvorený" || msg.HasFlag("fuzzy") {
+		t.Errorf("expected the exact match of the context, got %v", msg)
+	}
+	if msg := f.Messages[1]; msg.Str[0] != "Otvoriť" || !msg.HasFlag("fuzzy") ||
+		!reflect.DeepEqual(msg.ExtractedComments, []string{`tm: exact match in context "verb"`}) {
+		t.Errorf("expected a fuzzy match of another context, got %v", msg)
+	}
+}
+
+func TestScore(t *testing.T) {
+	var tests = []struct {
+		scorer   Scorer
+		a, b     string
+		expected float64
+	}{
+		{DefaultScorer, "Open file", "Open file", 1},
+		{DefaultScorer, "Open file", "Open files", 0.5},
+		{DefaultScorer, "Open the file.", "Open the file", 0.75},
+		{DefaultScorer, "Hello, %s!", "Hello, {name}!", 1},
+		{Scorer{Tokens: WordTokens}, "Hello, %s!", "Hello, {name}!", 0.5},
+		{Scorer{Tokens: WordTokens}, "Open File", "open file", 0},
+		{Scorer{Tokens: WordTokens, IgnoreCase: true}, "Open File", "open file", 1},
+		{Scorer{Tokens: CharTokens}, "file", "files", 0.8},
+		{DefaultScorer, "", "", 1},
+	}
+	for _, test := range tests {
+		if actual := test.scorer.Score(test.a, test.b); actual != test.expected {
+			t.Errorf("%+v %q %q: expected %v, got %v", test.scorer, test.a, test.b, test.expected, actual)
+		}
+	}
+}
+
+func TestLeverage(t *testing.T) {
+	var billing = &File{Messages: []*Message{
+		{Id: "Cancel", Str: []string{"Abbrechen"}},
+		{Ctxt: "verb", Id: "Save", Str: []string{"Speichern"}},
+	}}
+	var accounts = &File{Messages: []*Message{
+		{Id: "Cancel", Str: []string{"Stornieren"}},
+		{Id: "Save", Str: []string{"Sichern"}},
+	}}
+	var target = &File{Messages: []*Message{
+		{Id: "Cancel", Str: []string{""}},
+		{Ctxt: "verb", Id: "Save", Str: []string{""}},
+		{Id: "Delete", Str: []string{""}},
+	}}
+	if n := Leverage(target, billing, accounts); n != 2 {
+		t.Errorf("expected 2 messages translated, got %d", n)
+	}
+	var actual []string
+	for _, msg := range target.Messages {
+		actual = append(actual, msg.Str[0])
+	}
+	if expected := []string{"Abbrechen", "Speichern", ""}; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
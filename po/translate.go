@@ -0,0 +1,91 @@
+package po
+
+import (
+	"context"
+	"fmt"
+)
+
+// MTProvider is implemented by machine translation services, e.g. DeepL or
+// Google Translate.
+type MTProvider interface {
+	// Name identifies the provider in the comments of the messages it translated.
+	Name() string
+	// Translate translates texts from the src to the dst language, returning
+	// the translations in the same order.
+	Translate(ctx context.Context, src, dst string, texts []string) ([]string, error)
+}
+
+// PreTranslate fills the untranslated messages of the file with translations
+// from p, in a single batch. The messages are flagged fuzzy, so that they are
+// reviewed by a translator, and the provider is recorded in a translator
+// comment. src is the language of the msgids. The number of messages
+// translated is returned.
+func (f *File) PreTranslate(ctx context.Context, p MTProvider, src string) (int, error) {
+	var msgs []*Message
+	var texts []string
+	var nplurals = f.nplurals()
+	for _, msg := range f.Messages {
+		if !msg.isUntranslated() {
+			continue
+		}
+		msgs = append(msgs, msg)
+		texts = append(texts, msg.Id)
+		if msg.IdPlural != "" {
+			texts = append(texts, msg.IdPlural)
+		}
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+
+	var translations, err = p.Translate(ctx, src, f.Header.Get("Language"), texts)
+	if err != nil {
+		return 0, err
+	}
+	if len(translations) != len(texts) {
+		return 0, fmt.Errorf("po: %s returned %d translations for %d texts", p.Name(), len(translations), len(texts))
+	}
+	for _, msg := range msgs {
+		if msg.IdPlural == "" {
+			msg.Str = []string{translations[0]}
+			translations = translations[1:]
+		} else {
+			msg.Str = make([]string, nplurals)
+			msg.Str[0] = translations[0]
+			for i := 1; i < nplurals; i++ {
+				msg.Str[i] = translations[1]
+			}
+			translations = translations[2:]
+		}
+		msg.markFuzzy()
+		msg.TranslatorComments = append(msg.TranslatorComments, "machine-translated: "+p.Name())
+	}
+	return len(msgs), nil
+}
+
+// IsTranslated returns true if all of the message's msgstrs are filled in.
+func (m *Message) IsTranslated() bool {
+	for _, str := range m.Str {
+		if str == "" {
+			return false
+		}
+	}
+	return len(m.Str) > 0
+}
+
+// isUntranslated returns true if none of the message's msgstrs are filled in.
+func (m *Message) isUntranslated() bool {
+	for _, str := range m.Str {
+		if str != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// markFuzzy adds the fuzzy flag to the message, if missing.
+func (m *Message) markFuzzy() {
+	if !m.HasFlag("fuzzy") {
+		m.Flags = append([]string{"fuzzy"}, m.Flags...)
+	}
+}
@@ -0,0 +1,62 @@
+package po
+
+import (
+	"context"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type upperProvider struct{}
+
+func (upperProvider) Name() string { return "upper" }
+
+func (upperProvider) Translate(ctx context.Context, src, dst string, texts []string) ([]string, error) {
+	var r []string
+	for _, text := range texts {
+		r = append(r, strings.ToUpper(text))
+	}
+	return r, nil
+}
+
+func TestPreTranslate(t *testing.T) {
+	var f = &File{
+		Header: textproto.MIMEHeader{"Plural-Forms": {"nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;"}},
+		Messages: []*Message{
+			{Id: "Open", Str: []string{"Otvoriť"}},
+			{Id: "Save", Str: []string{""}},
+			{Id: "one egg", IdPlural: "%d eggs", Str: []string{"", ""}},
+		},
+	}
+	n, err := f.PreTranslate(context.Background(), upperProvider{}, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 messages translated, got %d", n)
+	}
+	var expected = []*Message{
+		{Id: "Open", Str: []string{"Otvoriť"}},
+		{
+			Comment: Comment{
+				TranslatorComments: []string{"machine-translated: upper"},
+				Flags:              []string{"fuzzy"},
+			},
+			Id:  "Save",
+			Str: []string{"SAVE"},
+		},
+		{
+			Comment: Comment{
+				TranslatorComments: []string{"machine-translated: upper"},
+				Flags:              []string{"fuzzy"},
+			},
+			Id:       "one egg",
+			IdPlural: "%d eggs",
+			Str:      []string{"ONE EGG", "%D EGGS", "%D EGGS"},
+		},
+	}
+	if !reflect.DeepEqual(expected, f.Messages) {
+		t.Errorf("expected msgs:\n%v\ngot msgs:\n%v", expected, f.Messages)
+	}
+}
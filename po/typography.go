@@ -0,0 +1,190 @@
+package po
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Typography configures the typographic checks, whose conventions differ
+// between locales.
+type Typography struct {
+	DoubleSpace   bool // report runs of spaces not present in the source
+	TrailingSpace bool // report trailing whitespace not present in the source
+	Punctuation   bool // report mismatched terminal punctuation
+	Quotes        bool // report mixed straight and curly quotes
+	RepeatedWords bool // report words repeated back to back ("the the")
+
+	// Terminal lists, for each punctuation mark that may end a source string,
+	// the marks accepted at the end of the translation.
+	Terminal map[rune]string
+}
+
+// defaultTerminal is the terminal punctuation of languages using Latin script.
+var defaultTerminal = map[rune]string{
+	'.': ".…",
+	'!': "!",
+	'?': "?",
+	':': ":",
+	';': ";",
+	'…': "…",
+}
+
+// terminalByLang holds the terminal punctuation of languages departing from the default.
+var terminalByLang = map[string]map[rune]string{
+	"ja": {'.': "。.", '!': "！!", '?': "？?", ':': "：:", ';': "；;", '…': "…"},
+	"zh": {'.': "。.", '!': "！!", '?': "？?", ':': "：:", ';': "；;", '…': "…"},
+	"el": {'.': ".", '!': "!", '?': "\u037e;", ':': ":", ';': "\u0387·", '…': "…"},
+	"ar": {'.': ".", '!': "!", '?': "؟", ':': ":", ';': "؛", '…': "…"},
+	"fa": {'.': ".", '!': "!", '?': "؟", ':': ":", ';': "؛", '…': "…"},
+	"hy": {'.': "\u0589:", '!': "\u055c!", '?': "\u055e?", ':': ":", ';': ";", '…': "…"},
+}
+
+// TypographyForLanguage returns the typographic conventions for the provided
+// language code, with all checks enabled.
+func TypographyForLanguage(lang string) Typography {
+	var t = Typography{true, true, true, true, true, defaultTerminal}
+	lang = strings.Replace(lang, "-", "_", -1)
+	if len(lang) > 2 && lang[2] == '_' {
+		lang = lang[:2]
+	}
+	if terminal, found := terminalByLang[lang]; found {
+		t.Terminal = terminal
+	}
+	switch lang {
+	case "ja", "zh", "th", "lo", "km", "my":
+		// Words are not separated by spaces.
+		t.RepeatedWords = false
+	}
+	return t
+}
+
+// typographyRules lists the IDs of the typographic checks.
+var typographyRules = []string{"double-space", "trailing-space", "punctuation", "quotes", "repeated-word"}
+
+// CheckTypography reports the typographic problems of the translated strings
+// in the file.
+func (f *File) CheckTypography(t Typography) []Problem {
+	var l Linter
+	for _, id := range typographyRules {
+		if t.enabled(id) {
+			l.Rules = append(l.Rules, t.rule(id))
+		}
+	}
+	return l.Lint(f)
+}
+
+// newTypographyRule returns the registered rule for the given check, which
+// uses the conventions of each file's language.
+func newTypographyRule(id string) Rule {
+	return NewRule(id, Warning, func(c *RuleContext) {
+		if t := TypographyForLanguage(c.Lang); t.enabled(id) {
+			t.rule(id).Check(c)
+		}
+	})
+}
+
+// rule returns the given check using the conventions in t.
+func (t Typography) rule(id string) Rule {
+	return NewRule(id, Warning, func(c *RuleContext) {
+		for i, str := range c.Msg.Str {
+			if str == "" {
+				continue
+			}
+			if text := t.check(id, c.Msg.source(i), str); text != "" {
+				c.Report("%s", text)
+			}
+		}
+	})
+}
+
+func (t Typography) enabled(id string) bool {
+	switch id {
+	case "double-space":
+		return t.DoubleSpace
+	case "trailing-space":
+		return t.TrailingSpace
+	case "punctuation":
+		return t.Punctuation
+	case "quotes":
+		return t.Quotes
+	case "repeated-word":
+		return t.RepeatedWords
+	}
+	return false
+}
+
+// check runs the given check on a translation of src, returning the
+// description of the problem found, if any.
+func (t Typography) check(id, src, str string) string {
+	switch id {
+	case "double-space":
+		if strings.Contains(str, "  ") && !strings.Contains(src, "  ") {
+			return "translation contains double spaces"
+		}
+	case "trailing-space":
+		if hasTrailingSpace(str) && !hasTrailingSpace(src) {
+			return "translation ends with whitespace"
+		}
+	case "punctuation":
+		var srcEnd, strEnd = lastRune(src), lastRune(str)
+		if accepted, found := t.Terminal[srcEnd]; found {
+			if !strings.ContainsRune(accepted, strEnd) {
+				return "translation should end with " + string(srcEnd)
+			}
+		} else if t.isTerminal(strEnd) {
+			return "translation should not end with " + string(strEnd)
+		}
+	case "quotes":
+		if strings.ContainsRune(str, '"') && strings.ContainsAny(str, "“”„«»") {
+			return "translation mixes straight and curly quotes"
+		}
+	case "repeated-word":
+		if word := repeatedWord(str); word != "" && repeatedWord(src) != word {
+			return "translation repeats the word " + word
+		}
+	}
+	return ""
+}
+
+// isTerminal returns true if r is accepted as terminal punctuation.
+func (t Typography) isTerminal(r rune) bool {
+	for _, accepted := range t.Terminal {
+		if strings.ContainsRune(accepted, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// source returns the untranslated string corresponding to msgstr[i].
+func (m *Message) source(i int) string {
+	if i > 0 && m.IdPlural != "" {
+		return m.IdPlural
+	}
+	return m.Id
+}
+
+func hasTrailingSpace(s string) bool {
+	var r, _ = utf8.DecodeLastRuneInString(s)
+	return r == ' ' || r == '\t'
+}
+
+// lastRune returns the last rune of s, ignoring trailing whitespace.
+func lastRune(s string) rune {
+	var r, _ = utf8.DecodeLastRuneInString(strings.TrimRightFunc(s, unicode.IsSpace))
+	return r
+}
+
+// repeatedWord returns the first word of s that is immediately repeated, if any.
+func repeatedWord(s string) string {
+	var prev string
+	for _, word := range strings.Fields(s) {
+		word = strings.ToLower(strings.TrimFunc(word, unicode.IsPunct))
+		if word != "" && word == prev && !strings.ContainsFunc(word, unicode.IsDigit) {
+			return word
+		}
+		prev = word
+	}
+	return ""
+}
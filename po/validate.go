@@ -0,0 +1,145 @@
+package po
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// Validate checks the header, the plural forms, the flags, the format strings
+// and the leading and trailing newlines of the file, and reports duplicated
+// messages, like msgfmt --check. It is meant both for linting and for
+// validating uploaded catalogs.
+func (f *File) Validate() []Problem {
+	var problems = f.validateHeader()
+	var l = Linter{[]Rule{pluralFormsRule, msgstrIndexRule, flagsRule, formatRule, newlinesRule}}
+	problems = append(problems, l.Lint(f)...)
+	return append(problems, f.duplicates()...)
+}
+
+func (f *File) validateHeader() []Problem {
+	var problems []Problem
+	var report = func(severity Severity, text string) {
+		problems = append(problems, Problem{Rule: "header", Severity: severity, Text: text})
+	}
+	if len(f.Header) == 0 {
+		report(Warning, "missing header")
+		return problems
+	}
+	if contentType := f.Header.Get("Content-Type"); contentType == "" {
+		report(Warning, "missing Content-Type header")
+	} else if _, params, err := mime.ParseMediaType(contentType); err != nil {
+		report(Error, "invalid Content-Type header: "+err.Error())
+	} else if params["charset"] == "" {
+		report(Warning, "missing charset in Content-Type header")
+	}
+	if f.Header.Get("Language") == "" {
+		report(Warning, "missing Language header")
+	}
+	if pluralForms := f.Header.Get("Plural-Forms"); pluralForms != "" {
+		if lookupPluralSelector(pluralForms) == nil {
+			report(Error, "unrecognized Plural-Forms header: "+pluralForms)
+		}
+	} else if f.Pluralize == nil && f.hasPlurals() {
+		report(Error, "missing Plural-Forms header")
+	}
+	return problems
+}
+
+func (f *File) hasPlurals() bool {
+	for _, msg := range f.Messages {
+		if msg.IdPlural != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicates reports the messages sharing their context and msgid with a
+// previous message.
+func (f *File) duplicates() []Problem {
+	var seen = make(map[key]*Message)
+	var problems []Problem
+	for _, msg := range f.Messages {
+		var k = key{msg.Ctxt, msg.Id}
+		if first, dup := seen[k]; dup {
+			var text = "duplicate of a previous message"
+			if line := f.Line(first); line > 0 {
+				text = fmt.Sprintf("duplicate of the message on line %d", line)
+			}
+			problems = append(problems, Problem{"duplicate", Error, msg, f.Line(msg), text})
+			continue
+		}
+		seen[k] = msg
+	}
+	return problems
+}
+
+var pluralFormsRule = NewRule("plural-forms", Error, func(c *RuleContext) {
+	if c.Msg.IdPlural == "" {
+		if len(c.Msg.Str) > 1 {
+			c.Report("singular message has %d msgstrs", len(c.Msg.Str))
+		}
+		return
+	}
+	if n := c.File.nplurals(); len(c.Msg.Str) != n && !c.Msg.isUntranslated() {
+		c.Report("plural message has %d msgstrs, the language has %d plural forms", len(c.Msg.Str), n)
+	}
+})
+
+// newlinesRule checks that the translations begin and end with a newline
+// when their msgid does, and only then.
+var newlinesRule = NewRule("newlines", Error, func(c *RuleContext) {
+	if c.Msg.HasFlag("fuzzy") {
+		return
+	}
+	var check = func(src, str, name string) {
+		if strings.HasPrefix(src, "\n") != strings.HasPrefix(str, "\n") {
+			c.Report("the msgid and %s do not both begin with a newline", name)
+		}
+		if strings.HasSuffix(src, "\n") != strings.HasSuffix(str, "\n") {
+			c.Report("the msgid and %s do not both end with a newline", name)
+		}
+	}
+	if c.Msg.IdPlural != "" {
+		check(c.Msg.Id, c.Msg.IdPlural, "msgid_plural")
+	}
+	for i, str := range c.Msg.Str {
+		if str != "" {
+			check(c.Msg.source(i), str, strName(c.Msg, i))
+		}
+	}
+})
+
+// formatLanguages lists the languages of the GNU gettext format flags, e.g.
+// "c-format" and "no-c-format".
+var formatLanguages = []string{
+	"c", "objc", "c++", "python", "python-brace", "java", "java-printf",
+	"csharp", "javascript", "scheme", "lisp", "elisp", "librep", "rust", "go",
+	"ruby", "sh", "awk", "lua", "object-pascal", "modula2", "d", "smalltalk",
+	"qt", "qt-plural", "kde", "kde-kuit", "boost", "tcl", "perl",
+	"perl-brace", "php", "gcc-internal", "gfc-internal", "ycp",
+}
+
+var flagsRule = NewRule("flags", Warning, func(c *RuleContext) {
+	for _, flag := range c.Msg.Flags {
+		switch {
+		case flag == "fuzzy", flag == "no-wrap", flag == "wrap", flag == FlagNoTranslate,
+			strings.HasPrefix(flag, "range:"),
+			strings.HasPrefix(flag, IgnoreCheck),
+			strings.HasPrefix(flag, MaxLengthPrefix):
+		case strings.HasSuffix(flag, "-format"):
+			var lang = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSuffix(flag, "-format"), "no-"), "possible-")
+			if !contains(formatLanguages, lang) {
+				c.Report("unknown format flag %s", flag)
+			} else if strings.HasPrefix(flag, "no-") && c.Msg.HasFlag(lang+"-format") {
+				c.Report("contradictory flags %s and %s-format", flag, lang)
+			}
+		default:
+			c.Report("unknown flag %s", flag)
+		}
+	}
+	if c.Msg.HasFlag("wrap") && c.Msg.HasFlag("no-wrap") {
+		c.Report("contradictory flags wrap and no-wrap")
+	}
+})
@@ -0,0 +1,147 @@
+package po
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Content-Type: text/plain\n"
+"Language: sk\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+#, fuzzy, no-c-format, c-format
+msgid "Open"
+msgstr "Otvoriť"
+
+#, sparkly
+msgid "one egg"
+msgid_plural "%d eggs"
+msgstr[0] "jedno vajce"
+msgstr[1] "%d vajcia"
+
+msgid "Open"
+msgstr "Otvor"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, p := range f.Validate() {
+		actual = append(actual, p.String())
+	}
+	var expected = []string{
+		"header: warning: header: missing charset in Content-Type header",
+		`line 7: "Open": warning: flags: contradictory flags no-c-format and c-format`,
+		`line 11: "one egg": error: plural-forms: plural message has 2 msgstrs, the language has 3 plural forms`,
+		`line 11: "one egg": warning: flags: unknown flag sparkly`,
+		`line 17: "Open": error: duplicate: duplicate of the message on line 7`,
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected:\n%v\ngot:\n%v", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Language: sk\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+#, c-format
+msgid "%s has %d files"
+msgstr "%2$d súborov má %1$s"
+
+#, c-format
+msgctxt "wrong"
+msgid "%s has %d files"
+msgstr "%d súborov má %s"
+
+#, c-format
+msgid "%.*f%%"
+msgstr "%s"
+
+#, c-format
+msgid "one file"
+msgid_plural "%lu files"
+msgstr[0] "jeden súbor"
+msgstr[1] "%lu súbory"
+msgstr[2] "%u súborov"
+
+msgid "Line\n"
+msgstr "\nRiadok"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, p := range f.Validate() {
+		actual = append(actual, p.String())
+	}
+	var expected = []string{
+		`line 11: "%s has %d files": error: format: format specifications in the msgid and msgstr for argument 1 are not the same: %s and %d`,
+		`line 11: "%s has %d files": error: format: format specifications in the msgid and msgstr for argument 2 are not the same: %d and %s`,
+		`line 16: "%.*f%%": error: format: format specifications in the msgid and msgstr for argument 1 are not the same: %.*f and %s`,
+		`line 16: "%.*f%%": error: format: a format specification for argument 2, %.*f, doesn't exist in msgstr`,
+		`line 20: "one file": error: format: format specifications in the msgid and msgstr[2] for argument 1 are not the same: %lu and %u`,
+		`line 27: "Line\n": error: newlines: the msgid and msgstr do not both begin with a newline`,
+		`line 27: "Line\n": error: newlines: the msgid and msgstr do not both end with a newline`,
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected:\n%v\ngot:\n%v", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestValidateGoFormat(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`
+msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Language: sk\n"
+
+#, go-format
+msgid "%s has %d files"
+msgstr "%[2]d súborov má %[1]s"
+
+#, go-format
+msgctxt "order"
+msgid "%s has %d files"
+msgstr "%d súborov má %s"
+
+#, go-format
+msgctxt "count"
+msgid "%s has %d files"
+msgstr "%v má %d súborov v %s"
+
+#, go-format
+msgid "%*d: %x"
+msgstr "%[1]*[2]v: %[3]s"
+
+#, go-format
+msgid "%[2]q after %q"
+msgstr "%[2]q po %[1]q"
+`[1:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, p := range f.Validate() {
+		actual = append(actual, p.String())
+	}
+	var expected = []string{
+		`line 10: "%s has %d files": error: format: format specifications in the msgid and msgstr for argument 1 are not the same: %s and %d`,
+		`line 10: "%s has %d files": error: format: format specifications in the msgid and msgstr for argument 2 are not the same: %d and %s`,
+		`line 15: "%s has %d files": error: format: a format specification for argument 3, %s, in msgstr doesn't exist in the msgid`,
+		`line 24: "%[2]q after %q": error: format: a format specification for argument 1, %[1]q, in msgstr doesn't exist in the msgid`,
+		`line 24: "%[2]q after %q": error: format: a format specification for argument 3, %q, doesn't exist in msgstr`,
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected:\n%v\ngot:\n%v", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
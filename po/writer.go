@@ -5,17 +5,30 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 // writer formats message fields into a buffer and writes to a destination.
 // it is a mirror of the scanner.
 type writer struct {
-	buf *bytes.Buffer
-	n   int64
+	buf   *bytes.Buffer
+	n     int64
+	lead  string // written at the start of each quoted line, e.g. obsoletePrefix
+	width int    // maximum length of the quoted lines, or 0 for no wrapping
 }
 
+// defaultWidth is the line width of the quoted strings written by default.
+const defaultWidth = 79
+
+// bufPool holds the buffers of writers, reused across writes.
+var bufPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// maxPooledBuf is the capacity above which buffers are not returned to the pool.
+const maxPooledBuf = 4 << 20
+
 func newWriter() writer {
-	return writer{new(bytes.Buffer), 0}
+	return writer{bufPool.Get().(*bytes.Buffer), 0, "", defaultWidth}
 }
 
 // mul writes the given values on multiple lines, one per line.
@@ -25,6 +38,24 @@ func (wr *writer) mul(prefix string, vals []string) {
 	}
 }
 
+// raw writes the given comment lines as is, adding the translator comment
+// prefix to the lines without "#".
+func (wr *writer) raw(lines []string) {
+	for _, line := range lines {
+		if line != "" && line[0] != '#' {
+			line = "# " + line
+		}
+		wr.buf.WriteString(line + "\n")
+	}
+}
+
+// ext writes the given extension comments, one per line.
+func (wr *writer) ext(prefix string, exts []Extension) {
+	for _, ext := range exts {
+		wr.buf.WriteString(prefix + ext.String() + "\n")
+	}
+}
+
 // spc writes the given values on a single line, separated by spaces.
 func (wr *writer) spc(prefix string, vals []string) {
 	if len(vals) == 0 {
@@ -40,6 +71,13 @@ func (wr *writer) spc(prefix string, vals []string) {
 	wr.buf.WriteString("\n")
 }
 
+// csv writes the given values on a single line, separated by commas.
+func (wr *writer) csv(prefix string, vals []string) {
+	if len(vals) > 0 {
+		wr.buf.WriteString(prefix + strings.Join(vals, ", ") + "\n")
+	}
+}
+
 // one writes the given value with the given prefix.
 func (wr *writer) one(prefix, val string) {
 	if val != "" {
@@ -55,26 +93,60 @@ func (wr *writer) opt(prefix, val string) {
 }
 
 // quo always writes the given value (quoted), even if empty.
-// Additionally, it breaks multiline strings across lines.
+// Additionally, like GNU gettext, it breaks strings across lines after their
+// newlines, and after spaces to fit the width, starting with an empty string
+// if broken.
 func (wr *writer) quo(prefix, val string) {
-	if !strings.Contains(val, "\n") {
-		wr.buf.WriteString(prefix + strconv.Quote(val) + "\n")
+	var first = wr.lead + prefix + quote(val)
+	if !strings.Contains(strings.TrimSuffix(val, "\n"), "\n") && (wr.width == 0 || utf8.RuneCountInString(first) <= wr.width) {
+		wr.buf.WriteString(first + "\n")
 		return
 	}
+	wr.lines(prefix, val)
+}
 
-	// multiline
-	wr.buf.WriteString(prefix + `""` + "\n")
-	for {
-		i := strings.Index(val, "\n")
-		if i == -1 {
-			if val != "" {
-				wr.buf.WriteString(strconv.Quote(val) + "\n")
+// lines writes the given value (quoted) broken across lines, starting with
+// an empty string, as always done for the header.
+func (wr *writer) lines(prefix, val string) {
+	wr.buf.WriteString(wr.lead + prefix + `""` + "\n")
+	for val != "" {
+		var i = strings.Index(val, "\n") + 1
+		if i == 0 {
+			i = len(val)
+		}
+		var line = quote(val[:i])
+		line = line[1 : len(line)-1]
+		for _, part := range wrap(line, wr.width-len(wr.lead)-len(`""`)) {
+			wr.buf.WriteString(wr.lead + `"` + part + `"` + "\n")
+		}
+		val = val[i:]
+	}
+}
+
+// wrap breaks the escaped string s after spaces into parts of at most width
+// runes, where possible. A width of 0 or less does not break s.
+func wrap(s string, width int) []string {
+	var parts []string
+	for width > 0 && utf8.RuneCountInString(s) > width {
+		var i = 0 // byte offset of the first rune past the width
+		for n := 0; n < width; n++ {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+		var brk = strings.LastIndexByte(s[:i], ' ') + 1
+		if brk == 0 {
+			if brk = strings.IndexByte(s[i:], ' ') + 1; brk == 0 {
+				break
 			}
-			return
+			brk += i
 		}
-		wr.buf.WriteString(strconv.Quote(val[:i+1]) + "\n")
-		val = val[i+1:]
+		if brk == len(s) {
+			break
+		}
+		parts = append(parts, s[:brk])
+		s = s[brk:]
 	}
+	return append(parts, s)
 }
 
 // msgstr writes a singular msgstr.
@@ -110,6 +182,13 @@ func (wr *writer) from(w io.WriterTo) {
 }
 
 // to writes the contents of the writer to the given output.
+// the writer must not be used afterwards, as its buffer is released.
 func (wr *writer) to(w io.Writer) (n int64, err error) {
-	return io.Copy(w, wr.buf)
+	n, err = io.Copy(w, wr.buf)
+	if wr.buf.Cap() <= maxPooledBuf {
+		wr.buf.Reset()
+		bufPool.Put(wr.buf)
+	}
+	wr.buf = nil
+	return n, err
 }
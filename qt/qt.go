@@ -0,0 +1,189 @@
+// Package qt converts catalogs to and from the .ts files of Qt Linguist, for
+// teams migrating from the Qt tools.
+//
+// The context of a Qt message becomes the msgctxt, followed by its
+// disambiguation comment, if any, after a "|", as lconvert does. Numerus
+// messages become plural messages, whose msgid_plural is their source, as Qt
+// has no plural source. Unfinished translations are fuzzy, and obsolete or
+// vanished ones obsolete. Locations become references, and extra and
+// translator comments the extracted and translator comments. Other flags and
+// header fields than the Language are not converted.
+package qt
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strings"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// Options controls how .ts files are written.
+type Options struct {
+	SourceLanguage string // language of the msgids, e.g. "en_US", omitted if empty
+}
+
+type ts struct {
+	XMLName        xml.Name  `xml:"TS"`
+	Version        string    `xml:"version,attr"`
+	Language       string    `xml:"language,attr,omitempty"`
+	SourceLanguage string    `xml:"sourcelanguage,attr,omitempty"`
+	Contexts       []context `xml:"context"`
+}
+
+type context struct {
+	Name     string    `xml:"name"`
+	Messages []message `xml:"message"`
+}
+
+type message struct {
+	Numerus           string      `xml:"numerus,attr,omitempty"`
+	Locations         []location  `xml:"location"`
+	Source            string      `xml:"source"`
+	Comment           string      `xml:"comment,omitempty"`
+	ExtraComment      string      `xml:"extracomment,omitempty"`
+	TranslatorComment string      `xml:"translatorcomment,omitempty"`
+	Translation       translation `xml:"translation"`
+}
+
+type location struct {
+	Filename string `xml:"filename,attr"`
+	Line     int    `xml:"line,attr,omitempty"`
+}
+
+type translation struct {
+	Type  string   `xml:"type,attr,omitempty"`
+	Text  string   `xml:",chardata"`
+	Forms []string `xml:"numerusform"`
+}
+
+// The translation types.
+const (
+	typeUnfinished = "unfinished"
+	typeObsolete   = "obsolete"
+	typeVanished   = "vanished"
+)
+
+// Write writes the messages of the file as a .ts file, translated to its
+// Language.
+func Write(w io.Writer, f *po.File, opts Options) error {
+	var doc = ts{Version: "2.1", Language: f.Header.Get("Language"), SourceLanguage: opts.SourceLanguage}
+	var index = make(map[string]int) // of the contexts in doc, by name
+	for _, msg := range f.Messages {
+		var name, comment = msg.Ctxt, ""
+		if i := strings.IndexByte(name, '|'); i != -1 {
+			name, comment = name[:i], name[i+1:]
+		}
+		var i, found = index[name]
+		if !found {
+			i = len(doc.Contexts)
+			index[name] = i
+			doc.Contexts = append(doc.Contexts, context{Name: name})
+		}
+		doc.Contexts[i].Messages = append(doc.Contexts[i].Messages, encode(msg, comment))
+	}
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE TS>\n"); err != nil {
+		return err
+	}
+	var enc = xml.NewEncoder(w)
+	enc.Indent("", "    ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// encode returns the Qt message of msg, with the disambiguation comment.
+func encode(msg *po.Message, comment string) message {
+	var m = message{
+		Source:            msg.Id,
+		Comment:           comment,
+		ExtraComment:      strings.Join(msg.ExtractedComments, "\n"),
+		TranslatorComment: strings.Join(msg.TranslatorComments, "\n"),
+	}
+	for _, ref := range msg.References {
+		m.Locations = append(m.Locations, location{ref.File, ref.Line})
+	}
+	var translated = false
+	for _, str := range msg.Str {
+		translated = translated || str != ""
+	}
+	switch {
+	case msg.Obsolete:
+		m.Translation.Type = typeObsolete
+	case !translated || msg.HasFlag("fuzzy"):
+		m.Translation.Type = typeUnfinished
+	}
+	if msg.IdPlural == "" {
+		if len(msg.Str) > 0 {
+			m.Translation.Text = msg.Str[0]
+		}
+		return m
+	}
+	m.Numerus = "yes"
+	m.Translation.Forms = append([]string(nil), msg.Str...)
+	if len(m.Translation.Forms) == 0 {
+		m.Translation.Forms = []string{""}
+	}
+	return m
+}
+
+// Parse reads a .ts file, returning its messages as those of a file, with
+// the language of the translations as Language.
+func Parse(r io.Reader) (*po.File, error) {
+	var doc ts
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("qt: %v", err)
+	}
+	var f = &po.File{Pluralize: po.PluralSelectorForLanguage(doc.Language)}
+	if doc.Language != "" {
+		f.Header = textproto.MIMEHeader{
+			"Language":     {doc.Language},
+			"Content-Type": {"text/plain; charset=UTF-8"},
+		}
+	}
+	for _, c := range doc.Contexts {
+		for _, m := range c.Messages {
+			f.Messages = append(f.Messages, decode(c.Name, m))
+		}
+	}
+	return f, nil
+}
+
+// decode returns the message of the Qt message of the given context.
+func decode(name string, m message) *po.Message {
+	var msg = &po.Message{Ctxt: name, Id: m.Source}
+	if m.Comment != "" {
+		msg.Ctxt += "|" + m.Comment
+	}
+	if m.ExtraComment != "" {
+		msg.ExtractedComments = strings.Split(m.ExtraComment, "\n")
+	}
+	if m.TranslatorComment != "" {
+		msg.TranslatorComments = strings.Split(m.TranslatorComment, "\n")
+	}
+	for _, loc := range m.Locations {
+		msg.References = append(msg.References, po.Reference{File: loc.Filename, Line: loc.Line})
+	}
+	if m.Numerus == "yes" {
+		msg.IdPlural = m.Source
+		msg.Str = append([]string(nil), m.Translation.Forms...)
+	} else {
+		msg.Str = []string{m.Translation.Text}
+	}
+	switch m.Translation.Type {
+	case typeObsolete, typeVanished:
+		msg.Obsolete = true
+	case typeUnfinished:
+		for _, str := range msg.Str {
+			if str != "" {
+				msg.Flags = []string{"fuzzy"}
+				break
+			}
+		}
+	}
+	return msg
+}
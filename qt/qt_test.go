@@ -0,0 +1,127 @@
+package qt
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/olebedev/gettext/po"
+)
+
+var catalog = `
+msgid ""
+msgstr ""
+"Language: sk\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+# Keep it short.
+#. Menu item.
+#: ../mainwindow.cpp:42
+msgctxt "MainWindow"
+msgid "Open"
+msgstr "Otvoriť"
+
+#, fuzzy
+msgctxt "MainWindow|verb"
+msgid "Close <b>all</b>"
+msgstr "Zavrieť <b>všetko</b>"
+
+msgid "Quit"
+msgstr ""
+
+msgctxt "MainWindow"
+msgid "%n file(s)"
+msgid_plural "%n file(s)"
+msgstr[0] "%n súbor"
+msgstr[1] "%n súbory"
+msgstr[2] "%n súborov"
+
+#~ msgctxt "MainWindow"
+#~ msgid "Print"
+#~ msgstr "Tlačiť"
+`
+
+func TestRoundTrip(t *testing.T) {
+	var f, err = po.Parse(strings.NewReader(catalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = Write(&buf, f, Options{SourceLanguage: "en_US"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<TS version="2.1" language="sk" sourcelanguage="en_US">`,
+		`<location filename="../mainwindow.cpp" line="42"></location>`,
+		`<comment>verb</comment>`,
+		`<message numerus="yes">`,
+		`<numerusform>%n súbory</numerusform>`,
+		`<translation type="obsolete">Tlačiť</translation>`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %s in:\n%s", expected, buf.String())
+		}
+	}
+	decoded, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Header.Get("Language") != "sk" || len(decoded.Messages) != len(f.Messages) {
+		t.Fatalf("unexpected file %v %v", decoded.Header, decoded.Messages)
+	}
+	// The messages are grouped by context, in the order of their first
+	// message.
+	var order = []int{0, 1, 3, 4, 2}
+	for i, j := range order {
+		var msg, actual = f.Messages[j], decoded.Messages[i]
+		if actual.Ctxt != msg.Ctxt || actual.Id != msg.Id || actual.IdPlural != msg.IdPlural ||
+			!reflect.DeepEqual(actual.Str, msg.Str) || actual.Obsolete != msg.Obsolete {
+			t.Errorf("expected %v, got %v", msg, actual)
+		}
+		if !reflect.DeepEqual(actual.Comment, msg.Comment) {
+			t.Errorf("%q: expected comments %+v, got %+v", msg.Id, msg.Comment, actual.Comment)
+		}
+	}
+	if actual := decoded.GetText("Quit"); actual != "Quit" {
+		t.Errorf("unexpected translation %q", actual)
+	}
+}
+
+func TestParse(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE TS>
+<TS version="2.1" language="de_DE">
+<context>
+    <name>Dialog</name>
+    <message>
+        <source>Save</source>
+        <translation type="unfinished"></translation>
+    </message>
+    <message>
+        <source>Cancel</source>
+        <translation type="vanished">Abbrechen</translation>
+    </message>
+    <message numerus="yes">
+        <source>%n item(s)</source>
+        <translation>
+            <numerusform>%n Element</numerusform>
+            <numerusform>%n Elemente</numerusform>
+        </translation>
+    </message>
+</context>
+</TS>
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Messages) != 3 || f.Messages[0].HasFlag("fuzzy") || !f.Messages[1].Obsolete {
+		t.Fatalf("unexpected messages %v", f.Messages)
+	}
+	if actual := f.NPGetText("Dialog", "%n item(s)", "%n item(s)", 3); actual != "%n Elemente" {
+		t.Errorf("unexpected plural %q", actual)
+	}
+	if _, err := Parse(strings.NewReader("<TS>")); err == nil {
+		t.Error("expected an error")
+	}
+}
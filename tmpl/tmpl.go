@@ -0,0 +1,52 @@
+// Package tmpl provides the translation functions of templates, for
+// html/template and text/template:
+//
+//	{{gettext "Open"}}
+//	{{ngettext "%d file" "%d files" .Count .Count}}
+//	{{pgettext "menu" "Open"}}
+//	{{npgettext "mail" "%d message" "%d messages" .Count .Count}}
+//
+// The arguments following the msgid, or the quantity of the plural forms,
+// format the translation as for po.File.GetText. The messages of the
+// templates are extracted by extract.Extractor.ParseTemplate.
+package tmpl
+
+import (
+	"context"
+	"html/template"
+
+	"github.com/olebedev/gettext"
+	"github.com/olebedev/gettext/po"
+)
+
+// untranslated is the catalog of the templates executed without one, which
+// returns the source strings.
+var untranslated = &po.File{Pluralize: po.PluralSelectorForLanguage("en")}
+
+// Funcs returns the translation functions of templates bound to the catalog,
+// or returning the source strings if f is nil.
+func Funcs(f *po.File) template.FuncMap {
+	if f == nil {
+		f = untranslated
+	}
+	return template.FuncMap{
+		"gettext":   f.GetText,
+		"ngettext":  f.NGetText,
+		"pgettext":  f.PGetText,
+		"npgettext": f.NPGetText,
+	}
+}
+
+// ContextFuncs returns the translation functions bound to the catalog of the
+// context, e.g. that of a request selected by gettext.Middleware. As the
+// functions of a template are set before it is parsed, the templates of a
+// request are clones of the parsed ones:
+//
+//	t, err := page.Clone()
+//	...
+//	t.Funcs(tmpl.ContextFuncs(r.Context())).Execute(w, data)
+//
+// The templates are parsed with the functions of Funcs(nil).
+func ContextFuncs(ctx context.Context) template.FuncMap {
+	return Funcs(gettext.FromContext(ctx))
+}
@@ -0,0 +1,62 @@
+package tmpl
+
+import (
+	"context"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/olebedev/gettext"
+	"github.com/olebedev/gettext/po"
+)
+
+const catalog = `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+msgid "Open <file>"
+msgstr "Otvoriť <súbor>"
+
+msgctxt "menu"
+msgid "Open <file>"
+msgstr "Otvor <súbor>"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d súbor"
+msgstr[1] "%d súbory"
+msgstr[2] "%d súborov"
+`
+
+const page = `{{gettext "Open <file>"}}|{{pgettext "menu" "Open <file>"}}|{{ngettext "%d file" "%d files" . .}}|{{npgettext "x" "%d day" "%d days" . .}}`
+
+func TestFuncs(t *testing.T) {
+	f, err := po.Parse(strings.NewReader(catalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var base = template.Must(template.New("page").Funcs(Funcs(nil)).Parse(page))
+	var execute = func(funcs template.FuncMap, n int) string {
+		var t = template.Must(base.Clone())
+		var b strings.Builder
+		if err := t.Funcs(funcs).Execute(&b, n); err != nil {
+			panic(err)
+		}
+		return b.String()
+	}
+	for _, c := range []struct {
+		funcs    template.FuncMap
+		n        int
+		expected string
+	}{
+		{Funcs(f), 3, "Otvoriť &lt;súbor&gt;|Otvor &lt;súbor&gt;|3 súbory|3 days"},
+		{Funcs(f), 1, "Otvoriť &lt;súbor&gt;|Otvor &lt;súbor&gt;|1 súbor|1 day"},
+		{Funcs(nil), 5, "Open &lt;file&gt;|Open &lt;file&gt;|5 files|5 days"},
+		{ContextFuncs(gettext.NewContext(context.Background(), "sk", f)), 5, "Otvoriť &lt;súbor&gt;|Otvor &lt;súbor&gt;|5 súborov|5 days"},
+		{ContextFuncs(context.Background()), 1, "Open &lt;file&gt;|Open &lt;file&gt;|1 file|1 day"},
+	} {
+		if actual := execute(c.funcs, c.n); actual != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, actual)
+		}
+	}
+}
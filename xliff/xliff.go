@@ -0,0 +1,611 @@
+// Package xliff converts catalogs to and from XLIFF 1.2 and 2.0 documents,
+// the exchange format of most translation management systems.
+//
+// Each message becomes a translation unit, with its context, translator
+// and extracted comments as notes, and its fuzzy flag as the state of the
+// translation. The forms of plural messages are grouped, the first one with
+// the msgid as source, the others with the msgid_plural. Other comments,
+// flags and header fields are not converted.
+package xliff
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+
+	"github.com/olebedev/gettext/po"
+)
+
+// Version is a version of the XLIFF format.
+type Version int
+
+const (
+	Version12 Version = iota // XLIFF 1.2
+	Version20                // XLIFF 2.0
+)
+
+// Options controls how documents are written.
+type Options struct {
+	Version        Version // version written, 1.2 by default
+	SourceLanguage string  // language of the msgids, "en" by default
+	Original       string  // name of the catalog, e.g. "messages.po"
+}
+
+// pluralType marks the groups holding the forms of plural messages.
+const (
+	pluralType12 = "x-gettext-plurals"
+	pluralType20 = "po:plurals"
+)
+
+// note categories, and the context type of the msgctxt in XLIFF 1.2.
+const (
+	noteTranslator = "translator"
+	noteDeveloper  = "developer"
+	noteContext    = "msgctxt"
+	contextType12  = "x-gettext-msgctxt"
+)
+
+// Write writes the messages of the file as an XLIFF document, translated to
+// its Language.
+func Write(w io.Writer, f *po.File, opts Options) error {
+	if opts.SourceLanguage == "" {
+		opts.SourceLanguage = "en"
+	}
+	if opts.Original == "" {
+		opts.Original = "messages.po"
+	}
+	var doc interface{}
+	switch opts.Version {
+	case Version12:
+		doc = encode12(f, opts)
+	case Version20:
+		doc = encode20(f, opts)
+	default:
+		return fmt.Errorf("xliff: unknown version %d", opts.Version)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	var enc = xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Parse reads an XLIFF 1.2 or 2.0 document, returning its units as the
+// messages of a file, with the target language as Language. The units of
+// all the files of the document are returned.
+func Parse(r io.Reader) (*po.File, error) {
+	var data, err = io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var root struct {
+		Version string `xml:"version,attr"`
+	}
+	if err = xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("xliff: %v", err)
+	}
+	var lang string
+	var msgs []*po.Message
+	switch root.Version {
+	case "1.2":
+		var doc xliff12
+		if err = xml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("xliff: %v", err)
+		}
+		lang, msgs = doc.decode()
+	case "2.0", "2.1":
+		var doc xliff20
+		if err = xml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("xliff: %v", err)
+		}
+		lang, msgs = doc.TargetLanguage, doc.decode()
+	default:
+		return nil, fmt.Errorf("xliff: unsupported version %q", root.Version)
+	}
+	var f = &po.File{Messages: msgs, Pluralize: po.PluralSelectorForLanguage(lang)}
+	if lang != "" {
+		f.Header = textproto.MIMEHeader{
+			"Language":     {lang},
+			"Content-Type": {"text/plain; charset=UTF-8"},
+		}
+	}
+	return f, nil
+}
+
+// state is the translation state of a message.
+type state int
+
+const (
+	untranslated state = iota
+	fuzzy
+	translated
+)
+
+func stateOf(msg *po.Message) state {
+	switch {
+	case msg.HasFlag("fuzzy"):
+		return fuzzy
+	case len(msg.Str) == 0 || msg.Str[0] == "":
+		return untranslated
+	}
+	return translated
+}
+
+// newMessage returns a message with the given translations, flagged fuzzy
+// if so.
+func newMessage(ctxt, id, idPlural string, strs []string, s state) *po.Message {
+	var msg = &po.Message{Ctxt: ctxt, Id: id, IdPlural: idPlural, Str: strs}
+	if s == fuzzy {
+		msg.Flags = []string{"fuzzy"}
+	}
+	return msg
+}
+
+// str returns the i-th translation of the message, or "".
+func str(msg *po.Message, i int) string {
+	if i < len(msg.Str) {
+		return msg.Str[i]
+	}
+	return ""
+}
+
+// forms returns the number of units of the forms of a plural message.
+func forms(msg *po.Message) int {
+	if len(msg.Str) < 2 {
+		return 2
+	}
+	return len(msg.Str)
+}
+
+// XLIFF 1.2
+
+type xliff12 struct {
+	XMLName xml.Name `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string   `xml:"version,attr"`
+	Files   []file12 `xml:"file"`
+}
+
+type file12 struct {
+	Original       string `xml:"original,attr"`
+	SourceLanguage string `xml:"source-language,attr"`
+	TargetLanguage string `xml:"target-language,attr,omitempty"`
+	Datatype       string `xml:"datatype,attr"`
+	Body           struct {
+		Items items12 `xml:",any"`
+	} `xml:"body"`
+}
+
+// items12 holds the units and groups of a body or group, in order.
+type items12 []interface{}
+
+func (it *items12) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v interface{}
+	switch start.Name.Local {
+	case "trans-unit":
+		v = new(unit12)
+	case "group":
+		v = new(group12)
+	default:
+		return d.Skip()
+	}
+	if err := d.DecodeElement(v, &start); err != nil {
+		return err
+	}
+	*it = append(*it, v)
+	return nil
+}
+
+type group12 struct {
+	XMLName  xml.Name         `xml:"group"`
+	Id       string           `xml:"id,attr"`
+	Restype  string           `xml:"restype,attr,omitempty"`
+	Contexts []contextGroup12 `xml:"context-group"`
+	Notes    []note12         `xml:"note"`
+	Items    items12          `xml:",any"`
+}
+
+type unit12 struct {
+	XMLName  xml.Name         `xml:"trans-unit"`
+	Id       string           `xml:"id,attr"`
+	Source   string           `xml:"source"`
+	Target   *target12        `xml:"target"`
+	Contexts []contextGroup12 `xml:"context-group"`
+	Notes    []note12         `xml:"note"`
+}
+
+type target12 struct {
+	State string `xml:"state,attr,omitempty"`
+	Text  string `xml:",chardata"`
+}
+
+type contextGroup12 struct {
+	Purpose  string      `xml:"purpose,attr,omitempty"`
+	Contexts []context12 `xml:"context"`
+}
+
+type context12 struct {
+	Type string `xml:"context-type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type note12 struct {
+	From string `xml:"from,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+// states12 are the target states of XLIFF 1.2.
+var states12 = map[state]string{
+	untranslated: "needs-translation",
+	fuzzy:        "needs-review-translation",
+	translated:   "translated",
+}
+
+func encode12(f *po.File, opts Options) *xliff12 {
+	var file = file12{
+		Original:       opts.Original,
+		SourceLanguage: opts.SourceLanguage,
+		TargetLanguage: f.Header.Get("Language"),
+		Datatype:       "po",
+	}
+	for i, msg := range f.Messages {
+		if msg.Obsolete {
+			continue
+		}
+		var id = strconv.Itoa(i + 1)
+		var s = states12[stateOf(msg)]
+		var contexts, notes = comments12(msg)
+		if msg.IdPlural == "" {
+			file.Body.Items = append(file.Body.Items, &unit12{
+				Id:       id,
+				Source:   msg.Id,
+				Target:   &target12{s, str(msg, 0)},
+				Contexts: contexts,
+				Notes:    notes,
+			})
+			continue
+		}
+		var group = &group12{Id: id, Restype: pluralType12, Contexts: contexts, Notes: notes}
+		for n := 0; n < forms(msg); n++ {
+			var source = msg.IdPlural
+			if n == 0 {
+				source = msg.Id
+			}
+			group.Items = append(group.Items, &unit12{
+				Id:     id + "-" + strconv.Itoa(n),
+				Source: source,
+				Target: &target12{s, str(msg, n)},
+			})
+		}
+		file.Body.Items = append(file.Body.Items, group)
+	}
+	return &xliff12{Version: "1.2", Files: []file12{file}}
+}
+
+// comments12 returns the context and notes of the message.
+func comments12(msg *po.Message) ([]contextGroup12, []note12) {
+	var contexts []contextGroup12
+	if msg.Ctxt != "" {
+		contexts = []contextGroup12{{"information", []context12{{contextType12, msg.Ctxt}}}}
+	}
+	var notes []note12
+	for _, c := range msg.TranslatorComments {
+		notes = append(notes, note12{noteTranslator, c})
+	}
+	for _, c := range msg.ExtractedComments {
+		notes = append(notes, note12{noteDeveloper, c})
+	}
+	return contexts, notes
+}
+
+func (doc *xliff12) decode() (string, []*po.Message) {
+	var lang string
+	var msgs []*po.Message
+	for _, file := range doc.Files {
+		if lang == "" {
+			lang = file.TargetLanguage
+		}
+		msgs = decodeItems12(msgs, file.Body.Items)
+	}
+	return lang, msgs
+}
+
+func decodeItems12(msgs []*po.Message, items []interface{}) []*po.Message {
+	for _, item := range items {
+		switch item := item.(type) {
+		case *unit12:
+			var text, s = target12State(item.Target)
+			var msg = newMessage(context12Of(item.Contexts), item.Source, "", []string{text}, s)
+			setNotes12(msg, item.Notes)
+			msgs = append(msgs, msg)
+		case *group12:
+			if item.Restype != pluralType12 {
+				msgs = decodeItems12(msgs, item.Items)
+				continue
+			}
+			var id, idPlural string
+			var strs []string
+			var s = translated
+			for n, form := range item.Items {
+				var unit, ok = form.(*unit12)
+				if !ok {
+					continue
+				}
+				var text, fs = target12State(unit.Target)
+				switch n {
+				case 0:
+					id = unit.Source
+				case 1:
+					idPlural = unit.Source
+				}
+				strs = append(strs, text)
+				if fs < s {
+					s = fs
+				}
+			}
+			var msg = newMessage(context12Of(item.Contexts), id, idPlural, strs, s)
+			setNotes12(msg, item.Notes)
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+// target12State returns the text and state of a target, which may be nil.
+func target12State(t *target12) (string, state) {
+	switch {
+	case t == nil || t.State == "new" || t.State == "needs-translation":
+		return "", untranslated
+	case len(t.State) >= len("needs-review") && t.State[:len("needs-review")] == "needs-review":
+		return t.Text, fuzzy
+	}
+	return t.Text, translated
+}
+
+func context12Of(groups []contextGroup12) string {
+	for _, g := range groups {
+		for _, c := range g.Contexts {
+			if c.Type == contextType12 {
+				return c.Text
+			}
+		}
+	}
+	return ""
+}
+
+func setNotes12(msg *po.Message, notes []note12) {
+	for _, n := range notes {
+		if n.From == noteDeveloper {
+			msg.ExtractedComments = append(msg.ExtractedComments, n.Text)
+		} else {
+			msg.TranslatorComments = append(msg.TranslatorComments, n.Text)
+		}
+	}
+}
+
+// XLIFF 2.0
+
+type xliff20 struct {
+	XMLName        xml.Name `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version        string   `xml:"version,attr"`
+	SourceLanguage string   `xml:"srcLang,attr"`
+	TargetLanguage string   `xml:"trgLang,attr,omitempty"`
+	Files          []file20 `xml:"file"`
+}
+
+type file20 struct {
+	Id       string  `xml:"id,attr"`
+	Original string  `xml:"original,attr,omitempty"`
+	Items    items20 `xml:",any"`
+}
+
+// items20 holds the units and groups of a file or group, in order.
+type items20 []interface{}
+
+func (it *items20) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v interface{}
+	switch start.Name.Local {
+	case "unit":
+		v = new(unit20)
+	case "group":
+		v = new(group20)
+	default:
+		return d.Skip()
+	}
+	if err := d.DecodeElement(v, &start); err != nil {
+		return err
+	}
+	*it = append(*it, v)
+	return nil
+}
+
+type group20 struct {
+	XMLName xml.Name `xml:"group"`
+	Id      string   `xml:"id,attr"`
+	Type    string   `xml:"type,attr,omitempty"`
+	Notes   *notes20 `xml:"notes"`
+	Items   items20  `xml:",any"`
+}
+
+type unit20 struct {
+	XMLName  xml.Name    `xml:"unit"`
+	Id       string      `xml:"id,attr"`
+	Notes    *notes20    `xml:"notes"`
+	Segments []segment20 `xml:"segment"`
+}
+
+type notes20 struct {
+	Notes []note20 `xml:"note"`
+}
+
+type note20 struct {
+	Category string `xml:"category,attr,omitempty"`
+	Text     string `xml:",chardata"`
+}
+
+type segment20 struct {
+	State    string  `xml:"state,attr,omitempty"`
+	SubState string  `xml:"subState,attr,omitempty"`
+	Source   string  `xml:"source"`
+	Target   *string `xml:"target"`
+}
+
+// subStateFuzzy marks the translated segments of fuzzy messages, which need
+// review.
+const subStateFuzzy = "po:needs-review"
+
+func encode20(f *po.File, opts Options) *xliff20 {
+	var file = file20{Id: "f1", Original: opts.Original}
+	for i, msg := range f.Messages {
+		if msg.Obsolete {
+			continue
+		}
+		var id = strconv.Itoa(i + 1)
+		var notes = notes20Of(msg)
+		if msg.IdPlural == "" {
+			file.Items = append(file.Items, &unit20{Id: id, Notes: notes, Segments: []segment20{segment20Of(msg, msg.Id, 0)}})
+			continue
+		}
+		var group = &group20{Id: id, Type: pluralType20, Notes: notes}
+		for n := 0; n < forms(msg); n++ {
+			var source = msg.IdPlural
+			if n == 0 {
+				source = msg.Id
+			}
+			group.Items = append(group.Items, &unit20{Id: id + "-" + strconv.Itoa(n), Segments: []segment20{segment20Of(msg, source, n)}})
+		}
+		file.Items = append(file.Items, group)
+	}
+	return &xliff20{
+		Version:        "2.0",
+		SourceLanguage: opts.SourceLanguage,
+		TargetLanguage: f.Header.Get("Language"),
+		Files:          []file20{file},
+	}
+}
+
+// segment20Of returns the segment of the n-th translation of the message.
+func segment20Of(msg *po.Message, source string, n int) segment20 {
+	var seg = segment20{State: "initial", Source: source}
+	switch stateOf(msg) {
+	case fuzzy:
+		seg.State, seg.SubState = "translated", subStateFuzzy
+	case translated:
+		seg.State = "translated"
+	}
+	if seg.State != "initial" {
+		var text = str(msg, n)
+		seg.Target = &text
+	}
+	return seg
+}
+
+func notes20Of(msg *po.Message) *notes20 {
+	var r notes20
+	if msg.Ctxt != "" {
+		r.Notes = append(r.Notes, note20{noteContext, msg.Ctxt})
+	}
+	for _, c := range msg.TranslatorComments {
+		r.Notes = append(r.Notes, note20{noteTranslator, c})
+	}
+	for _, c := range msg.ExtractedComments {
+		r.Notes = append(r.Notes, note20{noteDeveloper, c})
+	}
+	if len(r.Notes) == 0 {
+		return nil
+	}
+	return &r
+}
+
+func (doc *xliff20) decode() []*po.Message {
+	var msgs []*po.Message
+	for _, file := range doc.Files {
+		msgs = decodeItems20(msgs, file.Items)
+	}
+	return msgs
+}
+
+func decodeItems20(msgs []*po.Message, items []interface{}) []*po.Message {
+	for _, item := range items {
+		switch item := item.(type) {
+		case *unit20:
+			var source, text string
+			var s = translated
+			for _, seg := range item.Segments {
+				var segText, segState = segment20State(seg)
+				source, text = source+seg.Source, text+segText
+				if segState < s {
+					s = segState
+				}
+			}
+			if s == untranslated {
+				text = ""
+			}
+			msgs = append(msgs, setNotes20(newMessage("", source, "", []string{text}, s), item.Notes))
+		case *group20:
+			if item.Type != pluralType20 {
+				msgs = decodeItems20(msgs, item.Items)
+				continue
+			}
+			var id, idPlural string
+			var strs []string
+			var s = translated
+			for n, form := range item.Items {
+				var unit, ok = form.(*unit20)
+				if !ok || len(unit.Segments) == 0 {
+					continue
+				}
+				var text, fs = segment20State(unit.Segments[0])
+				switch n {
+				case 0:
+					id = unit.Segments[0].Source
+				case 1:
+					idPlural = unit.Segments[0].Source
+				}
+				strs = append(strs, text)
+				if fs < s {
+					s = fs
+				}
+			}
+			msgs = append(msgs, setNotes20(newMessage("", id, idPlural, strs, s), item.Notes))
+		}
+	}
+	return msgs
+}
+
+// segment20State returns the target text and state of a segment.
+func segment20State(seg segment20) (string, state) {
+	switch {
+	case seg.Target == nil || seg.State == "initial":
+		return "", untranslated
+	case seg.SubState == subStateFuzzy:
+		return *seg.Target, fuzzy
+	}
+	return *seg.Target, translated
+}
+
+// setNotes20 sets the context and comments of the message from the notes,
+// and returns it.
+func setNotes20(msg *po.Message, notes *notes20) *po.Message {
+	if notes == nil {
+		return msg
+	}
+	for _, n := range notes.Notes {
+		switch n.Category {
+		case noteContext:
+			msg.Ctxt = n.Text
+		case noteDeveloper:
+			msg.ExtractedComments = append(msg.ExtractedComments, n.Text)
+		default:
+			msg.TranslatorComments = append(msg.TranslatorComments, n.Text)
+		}
+	}
+	return msg
+}
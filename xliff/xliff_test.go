@@ -0,0 +1,115 @@
+package xliff
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/olebedev/gettext/po"
+)
+
+var catalog = `
+msgid ""
+msgstr ""
+"Language: sk\n"
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+# Keep it short.
+#. Menu item.
+msgctxt "menu"
+msgid "Open"
+msgstr "Otvoriť"
+
+#, fuzzy
+msgid "Close <b>all</b>"
+msgstr "Zavrieť <b>všetko</b>"
+
+msgid "Quit"
+msgstr ""
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d súbor"
+msgstr[1] "%d súbory"
+msgstr[2] "%d súborov"
+`
+
+func TestRoundTrip(t *testing.T) {
+	var f, err = po.Parse(strings.NewReader(catalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []Version{Version12, Version20} {
+		var buf bytes.Buffer
+		if err = Write(&buf, f, Options{Version: version}); err != nil {
+			t.Fatal(err)
+		}
+		var decoded, err = Parse(&buf)
+		if err != nil {
+			t.Fatalf("version %d: %v", version, err)
+		}
+		if decoded.Header.Get("Language") != "sk" || len(decoded.Messages) != len(f.Messages) {
+			t.Fatalf("version %d: unexpected file %v %v", version, decoded.Header, decoded.Messages)
+		}
+		for i, msg := range f.Messages {
+			var actual = decoded.Messages[i]
+			if actual.Ctxt != msg.Ctxt || actual.Id != msg.Id || actual.IdPlural != msg.IdPlural || !reflect.DeepEqual(actual.Str, msg.Str) {
+				t.Errorf("version %d: expected %v, got %v", version, msg, actual)
+			}
+			if !reflect.DeepEqual(actual.Comment, msg.Comment) {
+				t.Errorf("version %d: expected the comments %v, got %v", version, msg.Comment, actual.Comment)
+			}
+		}
+		if actual := decoded.NGetText("%d file", "%d files", 3, 3); actual != "3 súbory" {
+			t.Errorf("version %d: unexpected plural %q", version, actual)
+		}
+	}
+}
+
+func TestWrite12(t *testing.T) {
+	var f, _ = po.Parse(strings.NewReader(catalog))
+	var buf bytes.Buffer
+	if err := Write(&buf, f, Options{Original: "sk.po"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">`,
+		`<file original="sk.po" source-language="en" target-language="sk" datatype="po">`,
+		`<context context-type="x-gettext-msgctxt">menu</context>`,
+		`<note from="developer">Menu item.</note>`,
+		`<target state="needs-review-translation">Zavrieť &lt;b&gt;všetko&lt;/b&gt;</target>`,
+		`<group id="4" restype="x-gettext-plurals">`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %s in:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestParse20(t *testing.T) {
+	var f, err = Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="cs">
+  <file id="f1">
+    <group id="g1">
+      <unit id="u1">
+        <notes><note category="msgctxt">menu</note></notes>
+        <segment state="final"><source>Open</source><target>Otevřít</target></segment>
+      </unit>
+    </group>
+    <unit id="u2">
+      <segment state="translated"><source>Save </source><target>Uložit </target></segment>
+      <segment state="initial"><source>all</source></segment>
+    </unit>
+  </file>
+</xliff>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Messages) != 2 || f.PGetText("menu", "Open") != "Otevřít" {
+		t.Fatalf("unexpected messages %v", f.Messages)
+	}
+	if msg := f.Messages[1]; msg.Id != "Save all" || msg.Str[0] != "" {
+		t.Errorf("expected an untranslated segment to make the unit untranslated, got %v", msg)
+	}
+}